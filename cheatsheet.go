@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+var cheatsheetFlags = []flagSpec{
+	{"format", "format", "text", "output `format`: text, markdown or html", completeChoices},
+	{"o", "file", "", "write to this `file` instead of stdout", completeFiles},
+}
+
+func runCheatsheet(args []string) error {
+	fs := newFlagSet("cheatsheet")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, output := flagString(fs, "format"), flagString(fs, "o")
+
+	tree, err := config.LoadConfigTree(configPath)
+	if err != nil {
+		return err
+	}
+	sheet := config.NewCheatsheet(tree.Config)
+	if len(sheet.Sections) == 0 {
+		return fmt.Errorf("no keybindings in %s", configPath)
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case "text":
+		return sheet.WriteText(w)
+	case "markdown", "md":
+		return sheet.WriteMarkdown(w)
+	case "html":
+		return sheet.WriteHTML(w)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
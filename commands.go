@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+// command is a non-interactive subcommand, e.g. "hyprmax drift"
+type command struct {
+	name     string
+	args     string // positional arguments, e.g. "OPTION..."
+	summary  string
+	complete argCompletion
+	flags    []flagSpec
+	run      func(args []string) error
+}
+
+// flagSpec describes a flag. Commands define their flag sets from these
+// tables, and completion and the man page read them without running the
+// command.
+type flagSpec struct {
+	name     string
+	arg      string // name of the value, empty for boolean flags
+	def      string // default value
+	usage    string
+	complete argCompletion // what the value completes to
+}
+
+// argCompletion is what the positional arguments of a command or the value
+// of a flag complete to
+type argCompletion int
+
+const (
+	completeNone        argCompletion = iota
+	completeOptions                   // option paths
+	completeOptionValue               // an option path, then a value for it
+	completeFiles
+	completeShells
+	completeQuery    // a collection name, then field values in the filter
+	completeChoices  // the values listed in the usage after ": "
+	completeSections // option categories
+	completeProfiles // saved profile names
+)
+
+var commands []command
+
+// The list is filled in init because completion and man read it
+func init() {
+	commands = []command{
+		{"get", "OPTION...", "print the value of options", completeOptions, nil, runGet},
+		{"set", "OPTION VALUE", "validate and write an option, keeping comments and layout", completeOptionValue, dryRunFlags, runSet},
+		{"unset", "OPTION...", "remove options from the config file", completeOptions, dryRunFlags, runUnset},
+		{"list", "", "list options with their values and defaults", completeNone, listFlags, runList},
+		{"export", "", "print the config, including sourced files, as JSON or Nix", completeNone, exportFlags, runExport},
+		{"import", "FILE|-", "apply a JSON export or Nix settings to the config files", completeFiles, importFlags, runImport},
+		{"fmt", "[FILE...]", "rewrite the config files in the canonical layout", completeFiles, fmtFlags, runFmt},
+		{"diff", "OLD [NEW]", "compare two configs option by option, ignoring formatting", completeFiles, diffFlags, runDiff},
+		{"query", "QUERY", "list config entries matching a filter, e.g. 'binds[dispatcher=exec]'", completeQuery, queryFlags, runQuery},
+		{"cheatsheet", "", "print the keybindings grouped by submap and dispatcher", completeNone, cheatsheetFlags, runCheatsheet},
+		{"drift", "", "compare the config file with the running compositor", completeNone, driftFlags, runDrift},
+		{"lint", "[FILE...]", "check the config for errors, conflicts and inconsistencies", completeFiles, lintFlags, runLint},
+		{"migrate", "", "rewrite deprecated options for newer Hyprland versions", completeNone, migrateFlags, runMigrate},
+		{"doctor", "", "check the config files, their sources and the running compositor", completeNone, doctorFlags, runDoctor},
+		{"docs", "", "print the option reference as Markdown", completeNone, nil, runDocs},
+		{"completion", "bash|zsh|fish", "print a shell completion script", completeShells, nil, runCompletion},
+		{"man", "", "print the man page, or write one per command with --dir", completeNone, manFlags, runMan},
+	}
+}
+
+// runCommand runs the subcommand named by args[0] and returns the exit code
+func runCommand(args []string) int {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return 0
+	}
+
+	if name == completeCommand {
+		runComplete(args[1:])
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "hyprmax %s: %v\n", name, err)
+				return 1
+			}
+			return 0
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "hyprmax: unknown command %q\n\n", name)
+	printUsage()
+	return 2
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: hyprmax [--config PATH] [--test | --sandbox] [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command the interactive settings manager is started.")
+	fmt.Fprintln(os.Stderr, "\nGlobal flags:")
+	fmt.Fprintln(os.Stderr, "  --config PATH  config file to use (default: $HYPRMAX_CONFIG, then")
+	fmt.Fprintln(os.Stderr, "                 $XDG_CONFIG_HOME/hypr/hyprland.conf, then ~/.config/hypr/hyprland.conf)")
+	fmt.Fprintln(os.Stderr, "  --test         work on a temporary copy of the bundled sample config")
+	fmt.Fprintln(os.Stderr, "  --sandbox      work on a temporary copy of the config and the files it sources")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// configPath is the config file commands and the settings manager work on,
+// chosen by selectConfig
+var configPath string
+
+// sandboxed is set when configPath is a temporary copy. Changes to it are
+// not applied to the running compositor, which still uses the real file.
+var sandboxed bool
+
+// configGiven is set when configPath came from the command line rather
+// than from the environment or the default location
+var configGiven bool
+
+// selectConfig parses the global flags in front of the command, sets
+// configPath and returns the remaining arguments. The returned cleanup
+// function removes a --test sandbox; a --sandbox copy is kept for
+// inspection.
+func selectConfig(args []string) ([]string, func(), error) {
+	fs := flag.NewFlagSet("hyprmax", flag.ContinueOnError)
+	defineFlags(fs, globalFlags)
+	fs.Usage = printUsage
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	path := flagString(fs, "config")
+	test, sandbox := flagBool(fs, "test"), flagBool(fs, "sandbox")
+	if test && sandbox {
+		return nil, nil, fmt.Errorf("--test and --sandbox are mutually exclusive")
+	}
+
+	cleanup := func() {}
+	switch {
+	case test:
+		sb, err := config.NewTestSandbox()
+		if err != nil {
+			return nil, nil, err
+		}
+		configPath, sandboxed = sb.Path, true
+		cleanup = func() { sb.Remove() }
+	case sandbox:
+		sb, err := config.NewSandbox(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		configPath, sandboxed = sb.Path, true
+		fmt.Fprintf(os.Stderr, "hyprmax: working in sandbox %s, which is kept after exit\n", sb.Dir)
+	default:
+		expanded, err := config.ExpandPath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		configPath = expanded
+	}
+	configGiven = path != "" || test || sandbox
+	return fs.Args(), cleanup, nil
+}
+
+// loadConfig loads the config the commands operate on with every file it
+// sources
+func loadConfig() (*config.ConfigTree, error) {
+	return config.LoadConfigTree(configPath)
+}
+
+func runDocs(args []string) error {
+	fs := newFlagSet("docs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return config.WriteOptionsReference(os.Stdout)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	if cmd := lookupCommand(name); cmd != nil {
+		defineFlags(fs, cmd.flags)
+	}
+	return fs
+}
+
+// defineFlags adds flags to fs. Flags with an argument hold strings, the
+// others are booleans.
+func defineFlags(fs *flag.FlagSet, flags []flagSpec) {
+	for _, f := range flags {
+		if f.arg == "" {
+			fs.Bool(f.name, f.def == "true", f.usage)
+		} else {
+			fs.String(f.name, f.def, f.usage)
+		}
+	}
+}
+
+// flagString returns the value of a flag defined by defineFlags
+func flagString(fs *flag.FlagSet, name string) string {
+	return fs.Lookup(name).Value.String()
+}
+
+func flagBool(fs *flag.FlagSet, name string) bool {
+	return flagString(fs, name) == "true"
+}
+
+// lookupFlag returns the flag of a command, or the global flag when cmd is
+// nil
+func lookupFlag(cmd *command, name string) (flagSpec, bool) {
+	flags := globalFlags
+	if cmd != nil {
+		flags = cmd.flags
+	}
+	for _, f := range flags {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return flagSpec{}, false
+}
+
+// dryRunFlags are the flags of commands that edit a single option
+var dryRunFlags = []flagSpec{
+	{"dry-run", "", "", "print the resulting diff instead of writing the file", completeNone},
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/max-geller/hyprmax/config"
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+// completeCommand is the hidden command the completion scripts call
+const completeCommand = "__complete"
+
+// globalFlags are read by selectConfig in front of the command
+var globalFlags = []flagSpec{
+	{"config", "PATH", "", "config file to use (default: $HYPRMAX_CONFIG, then $XDG_CONFIG_HOME/hypr/hyprland.conf, then ~/.config/hypr/hyprland.conf)", completeFiles},
+	{"test", "", "", "work on a temporary copy of the bundled sample config", completeNone},
+	{"sandbox", "", "", "work on a temporary copy of the config and the files it sources", completeNone},
+}
+
+var shells = []string{"bash", "zsh", "fish"}
+
+func runCompletion(args []string) error {
+	fs := newFlagSet("completion")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax completion bash|zsh|fish")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("need a shell")
+	}
+
+	var script string
+	switch fs.Arg(0) {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return fmt.Errorf("unknown shell %q, want bash, zsh or fish", fs.Arg(0))
+	}
+	_, err := io.WriteString(os.Stdout, script)
+	return err
+}
+
+// runComplete prints the candidates for the last of args, which are the
+// words of a command line after "hyprmax". A single ":files" line asks the
+// shell to complete file names instead.
+func runComplete(args []string) {
+	candidates, files := completeWords(args)
+	if files {
+		fmt.Println(":files")
+		return
+	}
+	for _, c := range candidates {
+		fmt.Println(c)
+	}
+}
+
+// completeWords returns the candidates for the last word, and whether it
+// is a file name
+func completeWords(words []string) ([]string, bool) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := words[len(words)-1]
+
+	var cmd *command
+	var positional []string
+	var pending *flagSpec // flag waiting for its value
+	for _, w := range words[:len(words)-1] {
+		switch {
+		case pending != nil:
+			pending = nil
+		case strings.HasPrefix(w, "-") && w != "-":
+			name, _, inline := strings.Cut(strings.TrimLeft(w, "-"), "=")
+			if f, ok := lookupFlag(cmd, name); ok && !inline && f.arg != "" {
+				pending = &f
+			}
+		case cmd == nil:
+			cmd = lookupCommand(w)
+			if cmd == nil {
+				return nil, false
+			}
+		default:
+			positional = append(positional, w)
+		}
+	}
+
+	switch {
+	case pending != nil:
+		return completeArg(pending.complete, pending.usage, cur)
+	case strings.HasPrefix(cur, "-"):
+		flags := globalFlags
+		if cmd != nil {
+			flags = cmd.flags
+		}
+		var names []string
+		for _, f := range flags {
+			names = append(names, flagLabel(f.name))
+		}
+		return withPrefix(names, cur), false
+	case cmd == nil:
+		var names []string
+		for _, c := range commands {
+			names = append(names, c.name)
+		}
+		return withPrefix(names, cur), false
+	}
+
+	switch cmd.complete {
+	case completeOptionValue:
+		switch len(positional) {
+		case 0:
+			return config.CompleteOption(cur), false
+		case 1:
+			return config.CompleteValue(positional[0], cur), false
+		}
+	case completeQuery:
+		if len(positional) == 0 {
+			return completeQueryWord(cur), false
+		}
+	default:
+		return completeArg(cmd.complete, "", cur)
+	}
+	return nil, false
+}
+
+// completeArg returns the candidates for a positional argument or a flag
+// value. usage is the usage text of the flag.
+func completeArg(kind argCompletion, usage, cur string) ([]string, bool) {
+	switch kind {
+	case completeOptions:
+		return config.CompleteOption(cur), false
+	case completeFiles:
+		return nil, true
+	case completeShells:
+		return withPrefix(shells, cur), false
+	case completeChoices:
+		return withPrefix(flagChoices(usage), cur), false
+	case completeSections:
+		return config.CompleteSection(cur), false
+	case completeProfiles:
+		names, _ := config.Profiles()
+		return withPrefix(names, cur), false
+	}
+	return nil, false
+}
+
+// completeQueryWord completes a query: the collection name, then the value
+// of the condition being typed for fields holding dispatchers or monitors
+func completeQueryWord(cur string) []string {
+	collection, filter, ok := strings.Cut(cur, "[")
+	if !ok {
+		return withPrefix(config.QueryCollections(), cur)
+	}
+	cond := filter[strings.LastIndexAny(filter, "&|(")+1:]
+	cond = strings.TrimLeft(cond, " !")
+	op := strings.IndexAny(cond, "=!~<>")
+	if op < 0 || !strings.HasPrefix(cond[op:], "=") && !strings.HasPrefix(cond[op:], "!=") {
+		return nil
+	}
+	field := strings.TrimSpace(cond[:op])
+	value := strings.TrimPrefix(strings.TrimPrefix(cond[op:], "!"), "=")
+	prefix := cur[:len(cur)-len(value)]
+
+	var values []string
+	switch {
+	case field == "dispatcher" && collection == "binds":
+		values = config.CompleteDispatcher(value)
+	case field == "name" && collection == "monitors", field == "monitor" && collection == "workspaces":
+		values = withPrefix(monitorNames(), value)
+	}
+	for i, v := range values {
+		values[i] = prefix + v
+	}
+	return values
+}
+
+// monitorNames returns the monitors named in the config and those of the
+// running compositor. It is a variable so tests can replace it.
+var monitorNames = func() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if tree, err := loadConfig(); err == nil {
+		for _, m := range tree.Config.Monitors {
+			add(m.Name)
+		}
+	}
+	if client, err := ipc.NewClient(); err == nil {
+		client.Timeout = 200 * time.Millisecond
+		monitors, _ := client.Monitors()
+		for _, m := range monitors {
+			add(m.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// flagChoices reads the values listed in a usage text such as
+// "output format: text, json or sarif"
+func flagChoices(usage string) []string {
+	_, list, ok := strings.Cut(usage, ": ")
+	if !ok {
+		return nil
+	}
+	list, _, _ = strings.Cut(list, " (")
+	return strings.Split(strings.ReplaceAll(list, " or ", ", "), ", ")
+}
+
+// flagLabel returns how a flag is written: -o for single letters, --name
+// otherwise
+func flagLabel(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+func withPrefix(words []string, prefix string) []string {
+	var matches []string
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
+
+const bashCompletion = `# bash completion for hyprmax, generated by "hyprmax completion bash".
+# Load it with
+#   source <(hyprmax completion bash)
+
+_hyprmax() {
+    local line=${COMP_LINE:0:COMP_POINT}
+    local -a words
+    read -ra words <<< "$line"
+    [[ $line == *[[:space:]] ]] && words+=("")
+    local cur=${words[-1]}
+
+    local out
+    out=$("${words[0]}" __complete "${words[@]:1}" 2>/dev/null) || return
+    if [[ $out == ":files" ]]; then
+        compopt -o filenames 2>/dev/null
+        mapfile -t COMPREPLY < <(compgen -f -- "$cur")
+        return
+    fi
+    [[ -z $out ]] && return
+    mapfile -t COMPREPLY <<< "$out"
+
+    # Bash splits words at colons and equals signs, so only the part after
+    # the last one is replaced
+    local breaks=${COMP_WORDBREAKS//[^:=]/}
+    if [[ -n $breaks && $cur == *["$breaks"]* ]]; then
+        local prefix=${cur%"${cur##*["$breaks"]}"}
+        COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+    fi
+}
+
+complete -F _hyprmax hyprmax
+`
+
+const zshCompletion = `#compdef hyprmax
+# zsh completion for hyprmax, generated by "hyprmax completion zsh". Save it
+# as _hyprmax in a directory of $fpath, or load it with
+#   source <(hyprmax completion zsh)
+
+_hyprmax() {
+    local -a candidates
+    candidates=("${(@f)$(${words[1]} __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ ${candidates[1]} == ":files" ]]; then
+        _files
+        return
+    fi
+    candidates=(${candidates:#})
+    (( ${#candidates} )) && compadd -- "${candidates[@]}"
+}
+
+if [[ $funcstack[1] == _hyprmax ]]; then
+    _hyprmax "$@"
+else
+    compdef _hyprmax hyprmax
+fi
+`
+
+const fishCompletion = `# fish completion for hyprmax, generated by "hyprmax completion fish". Save
+# it as ~/.config/fish/completions/hyprmax.fish, or load it with
+#   hyprmax completion fish | source
+
+function __hyprmax_complete
+    set -l tokens (commandline -opc)
+    set -l candidates (hyprmax __complete $tokens[2..-1] (commandline -ct) 2>/dev/null)
+    if test "$candidates" = ":files"
+        __fish_complete_path (commandline -ct)
+        return
+    end
+    printf '%s\n' $candidates
+end
+
+complete -c hyprmax -f -a '(__hyprmax_complete)'
+`
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompleteWords(t *testing.T) {
+	profiles := filepath.Join(t.TempDir(), "config")
+	t.Setenv("XDG_CONFIG_HOME", profiles)
+	if err := os.MkdirAll(filepath.Join(profiles, "hyprmax/profiles"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"gaming.json", "work.json"} {
+		if err := os.WriteFile(filepath.Join(profiles, "hyprmax/profiles", name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(f func() []string) { monitorNames = f }(monitorNames)
+	monitorNames = func() []string { return []string{"DP-1", "DP-2", "eDP-1"} }
+
+	tests := []struct {
+		words []string
+		want  []string
+		files bool
+	}{
+		{[]string{"dri"}, []string{"drift"}, false},
+		{[]string{"--sa"}, []string{"--sandbox"}, false},
+		{[]string{"--config"}, []string{"--config"}, false},
+		{[]string{"--config", ""}, nil, true},
+		{[]string{"--test", "ge"}, []string{"get"}, false},
+		{[]string{"nosuch", ""}, nil, false},
+
+		// Flags come from the table of the command
+		{[]string{"fmt", "--"}, []string{"--check", "--dry-run", "--sort-binds"}, false},
+		{[]string{"export", "-"}, []string{"--format", "-o", "--profile"}, false},
+		{[]string{"get", "--"}, nil, false},
+		{[]string{"lint", "--format", ""}, []string{"text", "json", "sarif"}, false},
+		{[]string{"lint", "--format", "s"}, []string{"sarif"}, false},
+		{[]string{"import", "--format", "nix"}, []string{"nix", "nix-json"}, false},
+		{[]string{"list", "--section", "decoration:b"}, []string{"decoration:blur"}, false},
+		{[]string{"cheatsheet", "-o", ""}, nil, true},
+		{[]string{"man", "--dir", ""}, nil, true},
+		{[]string{"lint", "--check-exec", ""}, nil, true},
+
+		// Positional arguments
+		{[]string{"get", "general:gaps_i"}, []string{"general:gaps_in"}, false},
+		{[]string{"set", "--dry-run", "general:allow_t"}, []string{"general:allow_tearing"}, false},
+		{[]string{"set", "general:allow_tearing", "t"}, []string{"true"}, false},
+		{[]string{"set", "general:allow_tearing", "true", ""}, nil, false},
+		{[]string{"import", ""}, nil, true},
+		{[]string{"completion", "z"}, []string{"zsh"}, false},
+		{[]string{"drift", ""}, nil, false},
+
+		// Profiles
+		{[]string{"import", "--profile", ""}, []string{"gaming", "work"}, false},
+		{[]string{"export", "--profile", "w"}, []string{"work"}, false},
+
+		// Queries: collections, dispatchers and monitors
+		{[]string{"query", "bi"}, []string{"binds"}, false},
+		{[]string{"query", "binds[dispatcher=movef"}, []string{"binds[dispatcher=movefocus"}, false},
+		{[]string{"query", "binds[mods~SUPER&&!dispatcher!=killa"}, []string{"binds[mods~SUPER&&!dispatcher!=killactive"}, false},
+		{[]string{"query", "binds[key=movef"}, nil, false},
+		{[]string{"query", "monitors[name=DP"}, []string{"monitors[name=DP-1", "monitors[name=DP-2"}, false},
+		{[]string{"query", "workspaces[monitor=e"}, []string{"workspaces[monitor=eDP-1"}, false},
+		{[]string{"query", "binds[dispatcher~ex"}, nil, false},
+		{[]string{"query", "binds", ""}, nil, false},
+	}
+	for _, tt := range tests {
+		got, files := completeWords(tt.words)
+		if !reflect.DeepEqual(got, tt.want) || files != tt.files {
+			t.Errorf("completeWords(%q) = %q, %v, want %q, %v", tt.words, got, files, tt.want, tt.files)
+		}
+	}
+}
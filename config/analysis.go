@@ -0,0 +1,273 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity ranks a finding
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "info"
+}
+
+// Finding is a problem reported by the semantic analysis
+type Finding struct {
+	Rule     string // stable identifier, e.g. "undefined-bezier"
+	Severity Severity
+	File     string // file of Line, "" when not loaded from a file
+	Line     int    // 0 when the problem has no single location
+	Message  string
+}
+
+// Source returns the location of the finding as file:line, or as much of
+// it as is known
+func (f Finding) Source() string {
+	switch {
+	case f.Line == 0:
+		return f.File
+	case f.File == "":
+		return strconv.Itoa(f.Line)
+	}
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// semanticRule checks one property of the whole config
+type semanticRule struct {
+	id          string
+	severity    Severity
+	description string
+	check       func(cfg *HyprlandConfig) []Finding
+}
+
+var semanticRules = []semanticRule{
+	{"undeclared-monitor", SeverityWarning, "Workspace bound to a monitor no monitor line declares", checkWorkspaceMonitors},
+	{"undefined-bezier", SeverityError, "Animation uses a bezier curve that is not defined", checkAnimationBeziers},
+	{"undefined-variable", SeverityError, "Bind uses a $variable that is not defined", checkBindVariables},
+	{"opacity-order", SeverityWarning, "Unfocused windows are more opaque than the focused one", checkOpacityOrder},
+	{"shadow-disabled", SeverityInfo, "Shadow color set while shadows are disabled", checkShadowColor},
+	{"monitor-overlap", SeverityWarning, "Monitor positions overlap", checkMonitorOverlap},
+	{"plugin-dispatcher", SeverityInfo, "Bind uses a plugin dispatcher, which is not checked", checkPluginDispatchers},
+}
+
+// Analyze runs the cross-field checks over cfg. Findings are ordered by
+// file and line, findings without a line come last.
+func Analyze(cfg *HyprlandConfig) []Finding {
+	var findings []Finding
+	for _, rule := range semanticRules {
+		for _, f := range rule.check(cfg) {
+			f.Rule, f.Severity = rule.id, rule.severity
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		a, b := findings[i].Line, findings[j].Line
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	return findings
+}
+
+func checkWorkspaceMonitors(cfg *HyprlandConfig) []Finding {
+	declared := make(map[string]bool)
+	for _, m := range cfg.Monitors {
+		declared[m.Name] = true
+	}
+
+	var findings []Finding
+	for _, ws := range cfg.Workspaces {
+		if ws.Monitor != "" && !declared[ws.Monitor] {
+			findings = append(findings, Finding{File: ws.File, Line: ws.Line, Message: fmt.Sprintf(
+				"workspace %s is bound to monitor %q, which no monitor line declares", ws.Name, ws.Monitor)})
+		}
+	}
+	return findings
+}
+
+// builtinBeziers are the curves Hyprland defines itself
+var builtinBeziers = []string{"default", "linear"}
+
+func checkAnimationBeziers(cfg *HyprlandConfig) []Finding {
+	defined := make(map[string]bool)
+	for _, name := range builtinBeziers {
+		defined[name] = true
+	}
+	for _, b := range cfg.Animations.Beziers {
+		defined[b.Name] = true
+	}
+
+	var findings []Finding
+	for _, a := range cfg.Animations.Animations {
+		if a.Enabled && a.Bezier != "" && !defined[a.Bezier] {
+			findings = append(findings, Finding{File: a.File, Line: a.Line, Message: fmt.Sprintf(
+				"animation %s uses bezier %q, which is not defined", a.Target, a.Bezier)})
+		}
+	}
+	return findings
+}
+
+var variableRef = regexp.MustCompile(`\$[A-Za-z0-9_]+`)
+
+func checkBindVariables(cfg *HyprlandConfig) []Finding {
+	var findings []Finding
+	for _, b := range cfg.Binds {
+		fields := b.Mods + " " + b.Key
+		// exec arguments are handed to the shell, which has its own $VARS
+		if d, ok := LookupDispatcher(b.Dispatcher); !ok || d.Arg != ArgCommand {
+			fields += " " + b.Params
+		}
+
+		reported := make(map[string]bool)
+		for _, ref := range variableRef.FindAllString(fields, -1) {
+			if _, ok := cfg.Variables[ref[1:]]; ok || reported[ref] {
+				continue
+			}
+			reported[ref] = true
+			findings = append(findings, Finding{File: b.File, Line: b.Line, Message: fmt.Sprintf(
+				"bind %s uses undefined variable %s", b.Combo(cfg.Variables), ref)})
+		}
+	}
+	return findings
+}
+
+func checkPluginDispatchers(cfg *HyprlandConfig) []Finding {
+	var findings []Finding
+	for _, b := range cfg.Binds {
+		if IsPluginDispatcher(b.Dispatcher) {
+			findings = append(findings, Finding{File: b.File, Line: b.Line, Message: fmt.Sprintf(
+				"bind %s uses plugin dispatcher %s, which is not checked", b.Combo(cfg.Variables), b.Dispatcher)})
+		}
+	}
+	return findings
+}
+
+func checkOpacityOrder(cfg *HyprlandConfig) []Finding {
+	active, err1 := strconv.ParseFloat(cfg.optionValue("decoration:active_opacity"), 64)
+	inactive, err2 := strconv.ParseFloat(cfg.optionValue("decoration:inactive_opacity"), 64)
+	if err1 != nil || err2 != nil || inactive <= active {
+		return nil
+	}
+	at := "decoration:inactive_opacity"
+	return []Finding{{File: cfg.OptionFile(at), Line: cfg.OptionLine(at), Message: fmt.Sprintf(
+		"inactive_opacity %g is higher than active_opacity %g, unfocused windows will look more opaque", inactive, active)}}
+}
+
+// shadowOptions pairs the switch and color of the old and new shadow syntax
+var shadowOptions = [][2]string{
+	{"decoration:drop_shadow", "decoration:col.shadow"},
+	{"decoration:shadow:enabled", "decoration:shadow:color"},
+}
+
+func checkShadowColor(cfg *HyprlandConfig) []Finding {
+	var findings []Finding
+	for _, pair := range shadowOptions {
+		enabled, color := pair[0], pair[1]
+		if !cfg.IsSet(color) || !cfg.IsSet(enabled) {
+			continue
+		}
+		if on, ok := parseHyprBool(cfg.optionValue(enabled)); ok && !on {
+			findings = append(findings, Finding{File: cfg.OptionFile(color), Line: cfg.OptionLine(color), Message: fmt.Sprintf(
+				"%s is set but shadows are disabled by %s", color, enabled)})
+		}
+	}
+	return findings
+}
+
+// monitorRect is the area a monitor covers in the layout, in logical pixels
+type monitorRect struct {
+	monitor    Monitor
+	x, y, w, h float64
+}
+
+// arg returns the value of a "key, value" pair among the further
+// arguments, such as "1" for transform in "transform, 1"
+func (m Monitor) arg(key string) (string, bool) {
+	args := strings.Split(m.Args, ",")
+	for i := 0; i+1 < len(args); i += 2 {
+		if strings.TrimSpace(args[i]) == key {
+			return strings.TrimSpace(args[i+1]), true
+		}
+	}
+	return "", false
+}
+
+// rect returns the logical area of a monitor, or false when it depends on
+// runtime information (auto position, preferred mode), is disabled or
+// mirrors another monitor
+func (m Monitor) rect() (monitorRect, bool) {
+	if _, ok := m.arg("mirror"); ok {
+		return monitorRect{}, false
+	}
+	mode, _, _ := strings.Cut(m.Resolution, "@")
+	w, h, ok := parsePair(mode)
+	if !ok {
+		return monitorRect{}, false
+	}
+	x, y, ok := parsePair(m.Position)
+	if !ok {
+		return monitorRect{}, false
+	}
+	if scale, err := strconv.ParseFloat(m.Scale, 64); err == nil && scale > 0 {
+		w, h = w/scale, h/scale
+	}
+	// Odd transforms rotate by 90 or 270 degrees
+	if t, _ := m.arg("transform"); t == "1" || t == "3" || t == "5" || t == "7" {
+		w, h = h, w
+	}
+	return monitorRect{m, x, y, w, h}, true
+}
+
+// parsePair parses "AxB" as used by monitor modes and positions. Sscanf
+// would read "0x0" as a hexadecimal number.
+func parsePair(s string) (a, b float64, ok bool) {
+	first, second, found := strings.Cut(s, "x")
+	if !found {
+		return 0, 0, false
+	}
+	a, err1 := strconv.ParseFloat(first, 64)
+	b, err2 := strconv.ParseFloat(second, 64)
+	return a, b, err1 == nil && err2 == nil
+}
+
+func checkMonitorOverlap(cfg *HyprlandConfig) []Finding {
+	var rects []monitorRect
+	for _, m := range cfg.Monitors {
+		if r, ok := m.rect(); ok {
+			rects = append(rects, r)
+		}
+	}
+
+	var findings []Finding
+	for i, a := range rects {
+		for _, b := range rects[:i] {
+			if a.x < b.x+b.w && b.x < a.x+a.w && a.y < b.y+b.h && b.y < a.y+a.h {
+				findings = append(findings, Finding{File: a.monitor.File, Line: a.monitor.Line, Message: fmt.Sprintf(
+					"monitor %s at %s overlaps monitor %s at %s",
+					a.monitor.Name, a.monitor.Position, b.monitor.Name, b.monitor.Position)})
+			}
+		}
+	}
+	return findings
+}
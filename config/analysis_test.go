@@ -0,0 +1,131 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string // rule IDs in order
+	}{
+		{
+			name: "clean",
+			input: `monitor = DP-1, 2560x1440@144, 0x0, 1
+monitor = HDMI-A-1, 1920x1080, 2560x0, 1
+workspace = 1, monitor:DP-1
+$mod = SUPER
+bind = $mod, Q, killactive
+bind = $mod, Return, exec, $TERMINAL
+animations {
+    bezier = ease, 0.05, 0.9, 0.1, 1.05
+    animation = windows, 1, 7, ease
+    animation = fade, 1, 7, default
+}
+decoration {
+    active_opacity = 1.0
+    inactive_opacity = 0.9
+}`,
+		},
+		{
+			name:  "undeclared monitor",
+			input: "monitor = DP-1, preferred, auto, 1\nworkspace = 1, monitor:DP-2",
+			want:  []string{"undeclared-monitor"},
+		},
+		{
+			name:  "undefined bezier",
+			input: "animation = windows, 1, 7, overshot, slide\nanimation = fade, 0, 7, missing",
+			want:  []string{"undefined-bezier"},
+		},
+		{
+			name:  "undefined variable",
+			input: "$mod = SUPER\nbind = $mainMod, Q, killactive\nbind = $mod, 1, workspace, $ws",
+			want:  []string{"undefined-variable", "undefined-variable"},
+		},
+		{
+			name:  "inactive more opaque",
+			input: "decoration {\n    inactive_opacity = 0.9\n    active_opacity = 0.8\n}",
+			want:  []string{"opacity-order"},
+		},
+		{
+			name:  "inactive default above active",
+			input: "decoration {\n    active_opacity = 0.8\n}",
+			want:  []string{"opacity-order"},
+		},
+		{
+			name:  "shadow color while disabled",
+			input: "decoration {\n    drop_shadow = false\n    col.shadow = 0xee1a1a1a\n    shadow {\n        enabled = true\n        color = rgba(1a1a1aee)\n    }\n}",
+			want:  []string{"shadow-disabled"},
+		},
+		{
+			name:  "overlapping monitors",
+			input: "monitor = DP-1, 2560x1440, 0x0, 1\nmonitor = DP-2, 1920x1080, 2560x0, 1\nmonitor = DP-3, 3840x2160, 4000x0, 2",
+			want:  []string{"monitor-overlap"},
+		},
+		{
+			name:  "rotated monitors side by side",
+			input: "monitor = DP-1, 2560x1440, 0x0, 1, transform, 1\nmonitor = DP-2, 2560x1440, 1440x0, 1",
+		},
+		{
+			name:  "rotated monitor overlaps",
+			input: "monitor = DP-1, 2560x1440, 0x0, 1, transform, 3\nmonitor = DP-2, 2560x1440, 0x1440, 1",
+			want:  []string{"monitor-overlap"},
+		},
+		{
+			name:  "mirrored monitor",
+			input: "monitor = DP-1, 2560x1440, 0x0, 1\nmonitor = HDMI-A-1, 1920x1080, 0x0, 1, mirror, DP-1",
+		},
+		{
+			name:  "plugin dispatcher",
+			input: "bind = SUPER, grave, hyprexpo:expo, toggle",
+			want:  []string{"plugin-dispatcher"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &HyprlandConfig{}
+			if err := parseLine(tt.input, cfg); err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			var got []string
+			for _, f := range Analyze(cfg) {
+				got = append(got, f.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeFindingDetails(t *testing.T) {
+	cfg := &HyprlandConfig{}
+	input := "monitor = DP-1, 1920x1080, 0x0, 1\n\nworkspace = 2, monitor:HDMI-A-1"
+	if err := parseLine(input, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	findings := Analyze(cfg)
+	if len(findings) != 1 {
+		t.Fatalf("Analyze() = %+v, want one finding", findings)
+	}
+	f := findings[0]
+	if f.Line != 3 || f.Severity != SeverityWarning {
+		t.Errorf("finding = %+v, want line 3 warning", f)
+	}
+	if f.Source() != "3" {
+		t.Errorf("Source() = %q without a file, want 3", f.Source())
+	}
+
+	tree := loadTestTree(t, map[string]string{
+		"hyprland.conf": "source = monitors.conf\nworkspace = 2, monitor:HDMI-A-1\n",
+		"monitors.conf": "\nmonitor = DP-1, 1920x1080, 0x0, 1\n",
+	})
+	findings = Analyze(tree.Config)
+	if len(findings) != 1 || findings[0].Source() != tree.Documents[0].Path+":2" {
+		t.Errorf("Analyze() = %+v, want a finding at hyprland.conf:2", findings)
+	}
+}
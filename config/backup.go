@@ -2,27 +2,22 @@ package config
 
 import (
 	"os"
-	"path/filepath"
-	"strings"
 	"time"
 )
 
+// BackupConfig copies the config file at path to a timestamped backup next
+// to it
 func BackupConfig(path string) error {
-	if path == "" {
-		path = DefaultConfigPath
-	}
-
-	// Expand home directory
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return err
-		}
-		path = filepath.Join(home, path[2:])
+	path, err := ExpandPath(path)
+	if err != nil {
+		return err
 	}
 
-	// Read original file
+	// Read original file, nothing to back up if it does not exist yet
 	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
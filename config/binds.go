@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// modifierAliases maps every modifier spelling Hyprland accepts to its
+// canonical name
+var modifierAliases = map[string]string{
+	"SUPER": "SUPER", "WIN": "SUPER", "LOGO": "SUPER", "MOD4": "SUPER", "META": "SUPER",
+	"ALT": "ALT", "MOD1": "ALT",
+	"CTRL": "CTRL", "CONTROL": "CTRL",
+	"SHIFT": "SHIFT",
+	"CAPS":  "CAPS",
+	"MOD2":  "MOD2",
+	"MOD3":  "MOD3",
+	"MOD5":  "MOD5",
+}
+
+// modifierOrder is the order modifiers are printed in
+var modifierOrder = []string{"SUPER", "CTRL", "ALT", "SHIFT", "CAPS", "MOD2", "MOD3", "MOD5"}
+
+// NormalizeMods expands $variables and returns the canonical, ordered
+// modifier names. Unknown modifiers are returned as an error.
+func NormalizeMods(mods string, vars map[string]string) ([]string, error) {
+	expanded := (&HyprlandConfig{Variables: vars}).expandVariables(mods)
+
+	seen := make(map[string]bool)
+	fields := strings.FieldsFunc(strings.ToUpper(expanded), func(r rune) bool {
+		return r == ' ' || r == '+' || r == '_'
+	})
+	for _, field := range fields {
+		canonical, ok := modifierAliases[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown modifier %q", field)
+		}
+		seen[canonical] = true
+	}
+
+	var normalized []string
+	for _, mod := range modifierOrder {
+		if seen[mod] {
+			normalized = append(normalized, mod)
+		}
+	}
+	return normalized, nil
+}
+
+// Combo returns the canonical key combination of a bind, e.g. "SUPER+SHIFT+q".
+// Key names are matched case-insensitively like Hyprland does.
+func (b Bind) Combo(vars map[string]string) string {
+	mods, err := NormalizeMods(b.Mods, vars)
+	if err != nil {
+		mods = []string{strings.ToUpper(b.Mods)}
+	}
+	key := (&HyprlandConfig{Variables: vars}).expandVariables(b.Key)
+	return strings.Join(append(mods, strings.ToLower(key)), "+")
+}
+
+// ConflictKind classifies a keybinding conflict
+type ConflictKind int
+
+const (
+	// ConflictDuplicate is the same combination bound to the same action twice
+	ConflictDuplicate ConflictKind = iota
+	// ConflictShadowed is a combination bound again to a different action
+	ConflictShadowed
+	// ConflictSystem is a combination that clashes with a well-known
+	// system or application shortcut
+	ConflictSystem
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictDuplicate:
+		return "duplicate"
+	case ConflictShadowed:
+		return "shadowed"
+	}
+	return "system"
+}
+
+// BindConflict is a problem found in the keybindings
+type BindConflict struct {
+	Kind    ConflictKind
+	Combo   string
+	Bind    Bind  // the offending bind
+	Other   *Bind // the earlier bind it collides with, if any
+	Message string
+}
+
+// systemShortcuts are combinations the system or most applications rely on
+var systemShortcuts = map[string]string{
+	"CTRL+ALT+delete":    "used by the system for shutdown dialogs",
+	"CTRL+ALT+backspace": "kills the X server on many setups",
+	"ALT+tab":            "window switching in most desktop applications",
+	"ALT+f4":             "closes windows in most applications",
+	"CTRL+c":             "copy in nearly every application",
+	"CTRL+v":             "paste in nearly every application",
+	"CTRL+x":             "cut in nearly every application",
+	"CTRL+z":             "undo in nearly every application",
+	"CTRL+a":             "select all in nearly every application",
+	"CTRL+s":             "save in nearly every application",
+	"CTRL+q":             "quits many applications",
+	"CTRL+w":             "closes tabs in most applications",
+	"CTRL+t":             "opens tabs in most applications",
+}
+
+func init() {
+	// CTRL+ALT+Fn switches virtual terminals
+	for i := 1; i <= 12; i++ {
+		systemShortcuts[fmt.Sprintf("CTRL+ALT+f%d", i)] = "switches virtual terminals"
+	}
+}
+
+// bindSlot separates binds that can never collide: different submaps,
+// mouse binds and release binds live independently of press binds
+func bindSlot(b Bind) string {
+	slot := b.Submap
+	if strings.Contains(b.Flags, "m") {
+		slot += "|mouse"
+	}
+	if strings.Contains(b.Flags, "r") {
+		slot += "|release"
+	}
+	return slot
+}
+
+// FindBindConflicts reports duplicate, shadowed and system-clashing binds
+// in the order the binds were loaded
+func FindBindConflicts(cfg *HyprlandConfig) []BindConflict {
+	var conflicts []BindConflict
+	first := make(map[string]int)
+
+	for i, bind := range cfg.Binds {
+		combo := bind.Combo(cfg.Variables)
+
+		if reason, ok := systemShortcuts[combo]; ok && bind.Submap == "" && !strings.Contains(bind.Flags, "n") {
+			conflicts = append(conflicts, BindConflict{
+				Kind:    ConflictSystem,
+				Combo:   combo,
+				Bind:    bind,
+				Message: fmt.Sprintf("%s is %s", combo, reason),
+			})
+		}
+
+		slot := bindSlot(bind) + "|" + combo
+		j, seen := first[slot]
+		if !seen {
+			first[slot] = i
+			continue
+		}
+
+		other := cfg.Binds[j]
+		conflict := BindConflict{Combo: combo, Bind: bind, Other: &other}
+		if other.Dispatcher == bind.Dispatcher && other.Params == bind.Params {
+			conflict.Kind = ConflictDuplicate
+			conflict.Message = fmt.Sprintf("%s is bound twice to %s", combo, describeAction(bind))
+		} else {
+			conflict.Kind = ConflictShadowed
+			conflict.Message = fmt.Sprintf("%s runs %s but is already bound to %s",
+				combo, describeAction(bind), describeAction(other))
+		}
+		if other.Line > 0 {
+			conflict.Message += " (" + other.Source() + ")"
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// Source returns where the bind was read as file:line, "line N" when the
+// file is not known or "" when it was not loaded from a config
+func (b Bind) Source() string {
+	switch {
+	case b.Line == 0:
+		return ""
+	case b.File == "":
+		return fmt.Sprintf("line %d", b.Line)
+	}
+	return fmt.Sprintf("%s:%d", b.File, b.Line)
+}
+
+func describeAction(b Bind) string {
+	return strings.TrimSpace(b.Dispatcher + " " + b.Params)
+}
+
+// ValidateBind checks the key, dispatcher and dispatcher arguments of a
+// bind after expanding $variables
+func ValidateBind(b Bind, vars map[string]string) error {
+	expand := (&HyprlandConfig{Variables: vars}).expandVariables
+
+	if _, err := NormalizeMods(b.Mods, vars); err != nil {
+		return &ValidationError{"mods", b.Mods, err.Error()}
+	}
+	if err := ValidateKey("key", expand(b.Key)); err != nil {
+		return err
+	}
+	if err := ValidateDispatcher("dispatcher", b.Dispatcher); err != nil {
+		return err
+	}
+	return ValidateDispatcherParams(b.Dispatcher, expand(b.Params))
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeMods(t *testing.T) {
+	vars := map[string]string{"mainMod": "SUPER"}
+
+	tests := []struct {
+		mods    string
+		want    []string
+		wantErr bool
+	}{
+		{"SUPER", []string{"SUPER"}, false},
+		{"SHIFT SUPER", []string{"SUPER", "SHIFT"}, false},
+		{"shift+win", []string{"SUPER", "SHIFT"}, false},
+		{"MOD4_CONTROL", []string{"SUPER", "CTRL"}, false},
+		{"$mainMod ALT", []string{"SUPER", "ALT"}, false},
+		{"", nil, false},
+		{"HYPER", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mods, func(t *testing.T) {
+			got, err := NormalizeMods(tt.mods, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeMods(%q) error = %v, wantErr %v", tt.mods, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeMods(%q) = %v, want %v", tt.mods, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindBindConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		binds []Bind
+		want  []ConflictKind
+	}{
+		{
+			name: "no conflicts",
+			binds: []Bind{
+				{Mods: "SUPER", Key: "Q", Dispatcher: "exec", Params: "kitty"},
+				{Mods: "SUPER SHIFT", Key: "Q", Dispatcher: "killactive"},
+			},
+		},
+		{
+			name: "duplicate with reordered modifiers",
+			binds: []Bind{
+				{Mods: "SUPER SHIFT", Key: "Q", Dispatcher: "killactive"},
+				{Mods: "SHIFT+WIN", Key: "q", Dispatcher: "killactive"},
+			},
+			want: []ConflictKind{ConflictDuplicate},
+		},
+		{
+			name: "shadowed through variable",
+			binds: []Bind{
+				{Mods: "SUPER", Key: "Return", Dispatcher: "exec", Params: "kitty"},
+				{Mods: "$mainMod", Key: "RETURN", Dispatcher: "exec", Params: "foot"},
+			},
+			want: []ConflictKind{ConflictShadowed},
+		},
+		{
+			name: "submaps and release binds are separate",
+			binds: []Bind{
+				{Mods: "SUPER", Key: "R", Dispatcher: "submap", Params: "resize"},
+				{Mods: "SUPER", Key: "R", Dispatcher: "exec", Params: "x", Submap: "resize"},
+				{Flags: "r", Mods: "SUPER", Key: "R", Dispatcher: "exec", Params: "y"},
+			},
+		},
+		{
+			name: "system shortcut",
+			binds: []Bind{
+				{Mods: "ALT", Key: "F4", Dispatcher: "killactive"},
+				{Mods: "CTRL ALT", Key: "F2", Dispatcher: "workspace", Params: "2"},
+			},
+			want: []ConflictKind{ConflictSystem, ConflictSystem},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &HyprlandConfig{
+				Variables: map[string]string{"mainMod": "SUPER"},
+				Binds:     tt.binds,
+			}
+			var got []ConflictKind
+			for _, c := range FindBindConflicts(cfg) {
+				got = append(got, c.Kind)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindBindConflicts() kinds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindConflictSource(t *testing.T) {
+	cfg := &HyprlandConfig{Binds: []Bind{
+		{Mods: "SUPER", Key: "Q", Dispatcher: "killactive", File: "/cfg/binds.conf", Line: 4},
+		{Mods: "SUPER", Key: "Q", Dispatcher: "exec", Params: "kitty", File: "/cfg/hyprland.conf", Line: 12},
+		{Mods: "SUPER", Key: "E", Dispatcher: "exec", Params: "nautilus", Line: 2},
+		{Mods: "SUPER", Key: "E", Dispatcher: "exec", Params: "nautilus", Line: 9},
+	}}
+	want := []string{
+		"SUPER+q runs exec kitty but is already bound to killactive (/cfg/binds.conf:4)",
+		"SUPER+e is bound twice to exec nautilus (line 2)",
+	}
+	var got []string
+	for _, c := range FindBindConflicts(cfg) {
+		got = append(got, c.Message)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindBindConflicts() messages = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+// Cheatsheet is the keybindings of a config grouped for printing
+type Cheatsheet struct {
+	Sections []CheatsheetSection
+}
+
+// CheatsheetSection holds the binds of one submap, the global keymap first
+type CheatsheetSection struct {
+	Submap string // empty for the global keymap
+	Groups []CheatsheetGroup
+}
+
+// Title returns the heading of the section
+func (s CheatsheetSection) Title() string {
+	if s.Submap == "" {
+		return "Global"
+	}
+	return "Submap " + s.Submap
+}
+
+// CheatsheetGroup holds the binds of a section that use one dispatcher
+type CheatsheetGroup struct {
+	Dispatcher string
+	Entries    []CheatsheetEntry
+}
+
+// CheatsheetEntry is one bind as it is shown to the reader
+type CheatsheetEntry struct {
+	Keys   string // e.g. "SUPER + SHIFT + Q"
+	Action string // description, or a phrase derived from the dispatcher
+	Bind   Bind
+}
+
+// NewCheatsheet groups the binds of cfg by submap and dispatcher, both in
+// the order they first appear, with variables expanded
+func NewCheatsheet(cfg *HyprlandConfig) *Cheatsheet {
+	sheet := &Cheatsheet{}
+	sections := make(map[string]int)
+	groups := make(map[string]int)
+
+	// The global keymap comes first even when a submap is defined above it
+	sheet.Sections = append(sheet.Sections, CheatsheetSection{})
+	sections[""] = 0
+
+	for _, b := range cfg.Binds {
+		si, ok := sections[b.Submap]
+		if !ok {
+			si = len(sheet.Sections)
+			sections[b.Submap] = si
+			sheet.Sections = append(sheet.Sections, CheatsheetSection{Submap: b.Submap})
+		}
+		section := &sheet.Sections[si]
+
+		dispatcher := strings.ToLower(strings.TrimSpace(b.Dispatcher))
+		gkey := b.Submap + "\x00" + dispatcher
+		gi, ok := groups[gkey]
+		if !ok {
+			gi = len(section.Groups)
+			groups[gkey] = gi
+			section.Groups = append(section.Groups, CheatsheetGroup{Dispatcher: dispatcher})
+		}
+
+		action := strings.TrimSpace(cfg.expandVariables(b.Description))
+		if action == "" {
+			action = BindPhrase(b.Dispatcher, cfg.expandVariables(b.Params))
+		}
+		section.Groups[gi].Entries = append(section.Groups[gi].Entries, CheatsheetEntry{
+			Keys:   bindKeys(b, cfg.Variables),
+			Action: action,
+			Bind:   b,
+		})
+	}
+
+	if len(sheet.Sections[0].Groups) == 0 {
+		sheet.Sections = sheet.Sections[1:]
+	}
+	return sheet
+}
+
+// mouseKeys are the readable names of mouse buttons and wheel directions
+var mouseKeys = map[string]string{
+	"mouse:272":   "Left click",
+	"mouse:273":   "Right click",
+	"mouse:274":   "Middle click",
+	"mouse:275":   "Back button",
+	"mouse:276":   "Forward button",
+	"mouse_down":  "Scroll down",
+	"mouse_up":    "Scroll up",
+	"mouse_left":  "Scroll left",
+	"mouse_right": "Scroll right",
+}
+
+// bindKeys returns the key combination of a bind for reading, e.g.
+// "SUPER + SHIFT + Q"
+func bindKeys(b Bind, vars map[string]string) string {
+	mods, err := NormalizeMods(b.Mods, vars)
+	if err != nil {
+		mods = []string{strings.ToUpper(b.Mods)}
+	}
+	key := strings.TrimSpace((&HyprlandConfig{Variables: vars}).expandVariables(b.Key))
+	if name, ok := mouseKeys[strings.ToLower(key)]; ok {
+		key = name
+	} else if key != "" && key == strings.ToLower(key) {
+		// q reads as Q and escape as Escape; names like XF86AudioMute stay
+		key = strings.ToUpper(key[:1]) + key[1:]
+	}
+	return strings.Join(append(mods, key), " + ")
+}
+
+var directionNames = map[string]string{
+	"l": "left", "r": "right", "u": "up", "t": "up", "d": "down", "b": "down",
+}
+
+// direction returns the readable name of a direction argument
+func direction(arg string) string {
+	if name, ok := directionNames[strings.ToLower(arg)]; ok {
+		return name
+	}
+	return arg
+}
+
+// workspaceStep splits a relative workspace such as e+1 into its parts
+var workspaceStep = regexp.MustCompile(`^([emr]?)([+-])(\d+)$`)
+
+// workspacePhrase returns a workspace specifier in words, e.g. "workspace 3"
+// or "the next workspace"
+func workspacePhrase(arg string) string {
+	switch {
+	case arg == "previous" || arg == "previous_per_monitor":
+		return "the previous workspace"
+	case arg == "empty" || arg == "emptynm":
+		return "an empty workspace"
+	case arg == "special":
+		return "the special workspace"
+	case strings.HasPrefix(arg, "special:"):
+		return "special workspace " + strings.TrimPrefix(arg, "special:")
+	case strings.HasPrefix(arg, "name:"):
+		return "workspace " + strings.TrimPrefix(arg, "name:")
+	}
+	if m := workspaceStep.FindStringSubmatch(arg); m != nil {
+		which := "next"
+		if m[2] == "-" {
+			which = "previous"
+		}
+		phrase := "the " + which + " workspace"
+		if m[3] != "1" {
+			phrase = m[3] + " workspaces " + map[string]string{"next": "ahead", "previous": "back"}[which]
+		}
+		if m[1] == "e" || m[1] == "m" {
+			phrase += " on the monitor"
+		}
+		return phrase
+	}
+	return "workspace " + arg
+}
+
+// commandName returns a shell command without its [rules] prefix
+func commandName(cmd string) string {
+	cmd = strings.TrimSpace(cmd)
+	if strings.HasPrefix(cmd, "[") {
+		if end := strings.Index(cmd, "]"); end >= 0 {
+			cmd = strings.TrimSpace(cmd[end+1:])
+		}
+	}
+	return cmd
+}
+
+// BindPhrase describes a dispatcher call in words, for binds without a
+// description
+func BindPhrase(dispatcher, params string) string {
+	name := strings.ToLower(strings.TrimSpace(dispatcher))
+	params = strings.TrimSpace(params)
+	arg, _, _ := strings.Cut(params, ",")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "exec", "execr":
+		if cmd := commandName(params); cmd != "" {
+			return "Run " + cmd
+		}
+	case "workspace", "focusworkspaceoncurrentmonitor":
+		return "Switch to " + workspacePhrase(arg)
+	case "movetoworkspace":
+		return "Move window to " + workspacePhrase(arg)
+	case "movetoworkspacesilent":
+		return "Move window to " + workspacePhrase(arg) + " silently"
+	case "togglespecialworkspace":
+		if arg == "" {
+			return "Toggle the special workspace"
+		}
+		return "Toggle special workspace " + arg
+	case "movefocus":
+		return "Focus window " + direction(arg)
+	case "movewindow":
+		if arg == "" {
+			return "Move window with the mouse"
+		}
+		if mon, ok := strings.CutPrefix(arg, "mon:"); ok {
+			return "Move window to monitor " + mon
+		}
+		return "Move window " + direction(arg)
+	case "swapwindow":
+		return "Swap window " + direction(arg)
+	case "resizeactive":
+		return "Resize window by " + arg
+	case "moveactive":
+		return "Move window by " + arg
+	case "focusmonitor":
+		return "Focus monitor " + direction(arg)
+	case "submap":
+		if arg == "reset" {
+			return "Leave the submap"
+		}
+		return "Enter submap " + arg
+	case "fullscreen":
+		switch arg {
+		case "1":
+			return "Toggle maximize"
+		case "2":
+			return "Toggle fullscreen without telling the window"
+		}
+		return "Toggle fullscreen"
+	}
+
+	if d, ok := LookupDispatcher(name); ok {
+		phrase := d.Description
+		// Descriptions may explain the argument after a colon or comma
+		if i := strings.IndexAny(phrase, ":,("); i > 0 && params != "" {
+			phrase = strings.TrimSpace(phrase[:i])
+		}
+		if params != "" && d.Arg != ArgNone {
+			phrase += ": " + params
+		}
+		return phrase
+	}
+	return strings.TrimSpace(dispatcher + " " + params)
+}
+
+// WriteMarkdown writes the cheat sheet as Markdown tables
+func (s *Cheatsheet) WriteMarkdown(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "# Keybindings"); err != nil {
+		return err
+	}
+	cell := strings.NewReplacer("|", `\|`, "`", "\\`", "\n", " ")
+	for _, section := range s.Sections {
+		fmt.Fprintf(w, "\n## %s\n", section.Title())
+		for _, g := range section.Groups {
+			fmt.Fprintf(w, "\n### %s\n\n", g.Dispatcher)
+			fmt.Fprintln(w, "| Keys | Action |")
+			fmt.Fprintln(w, "|------|--------|")
+			for _, e := range g.Entries {
+				keys := strings.ReplaceAll(e.Keys, "|", `\|`)
+				if _, err := fmt.Fprintf(w, "| `%s` | %s |\n", keys, cell.Replace(e.Action)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// WriteHTML writes the cheat sheet as a standalone HTML page
+func (s *Cheatsheet) WriteHTML(w io.Writer) error {
+	fmt.Fprint(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Keybindings</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td { padding: 0.2em 1em 0.2em 0; vertical-align: top; }
+kbd { border: 1px solid #888; border-radius: 3px; padding: 0 0.3em; font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Keybindings</h1>
+`)
+	for _, section := range s.Sections {
+		fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(section.Title()))
+		for _, g := range section.Groups {
+			fmt.Fprintf(w, "<h3>%s</h3>\n<table>\n", html.EscapeString(g.Dispatcher))
+			for _, e := range g.Entries {
+				var keys []string
+				for _, k := range strings.Split(e.Keys, " + ") {
+					keys = append(keys, "<kbd>"+html.EscapeString(k)+"</kbd>")
+				}
+				fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td></tr>\n",
+					strings.Join(keys, " + "), html.EscapeString(e.Action))
+			}
+			fmt.Fprintln(w, "</table>")
+		}
+	}
+	_, err := fmt.Fprintln(w, "</body>\n</html>")
+	return err
+}
+
+// WriteText writes the cheat sheet as plain tables for the terminal
+func (s *Cheatsheet) WriteText(w io.Writer) error {
+	for i, section := range s.Sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		title := section.Title()
+		fmt.Fprintf(w, "%s\n%s\n", title, strings.Repeat("=", len(title)))
+
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "DISPATCHER\tKEYS\tACTION")
+		for _, g := range section.Groups {
+			for j, e := range g.Entries {
+				dispatcher := g.Dispatcher
+				if j > 0 {
+					dispatcher = ""
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", dispatcher, e.Keys, e.Action)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
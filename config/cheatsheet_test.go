@@ -0,0 +1,106 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const cheatsheetConfig = `$mod = SUPER
+$term = kitty
+
+submap = resize
+binde = , right, resizeactive, 10 0
+bind = , escape, submap, reset
+submap = reset
+
+# Terminal
+bind = $mod, Return, exec, $term
+bind = $mod, E, exec, thunar
+bindd = $mod, Q, Close window, killactive,
+bind = $mod, 1, workspace, 1
+bind = $mod SHIFT, 1, movetoworkspace, 1
+bind = $mod, R, submap, resize
+bindm = $mod, mouse:272, movewindow
+`
+
+func TestNewCheatsheet(t *testing.T) {
+	cfg := &HyprlandConfig{}
+	if err := parseLine(cheatsheetConfig, cfg); err != nil {
+		t.Fatal(err)
+	}
+	sheet := NewCheatsheet(cfg)
+
+	var got []string
+	for _, s := range sheet.Sections {
+		for _, g := range s.Groups {
+			for _, e := range g.Entries {
+				got = append(got, s.Title()+" | "+g.Dispatcher+" | "+e.Keys+" | "+e.Action)
+			}
+		}
+	}
+	want := []string{
+		"Global | exec | SUPER + Return | Terminal",
+		"Global | exec | SUPER + E | Run thunar",
+		"Global | killactive | SUPER + Q | Close window",
+		"Global | workspace | SUPER + 1 | Switch to workspace 1",
+		"Global | movetoworkspace | SUPER + SHIFT + 1 | Move window to workspace 1",
+		"Global | submap | SUPER + R | Enter submap resize",
+		"Global | movewindow | SUPER + Left click | Move window with the mouse",
+		"Submap resize | resizeactive | Right | Resize window by 10 0",
+		"Submap resize | submap | Escape | Leave the submap",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("cheat sheet:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestBindPhrase(t *testing.T) {
+	tests := []struct {
+		dispatcher, params, want string
+	}{
+		{"exec", "[float] pavucontrol", "Run pavucontrol"},
+		{"workspace", "e+1", "the next workspace on the monitor"},
+		{"workspace", "-2", "2 workspaces back"},
+		{"movetoworkspacesilent", "special:scratch", "Move window to special workspace scratch silently"},
+		{"movefocus", "l", "Focus window left"},
+		{"togglefloating", "", "Toggle floating for a window"},
+		{"signal", "9", "Send a signal to the active window: 9"},
+		{"dpms", "off", "Set monitor power: off"},
+		{"myplugin:go", "fast", "myplugin:go fast"},
+	}
+	for _, tt := range tests {
+		if got := BindPhrase(tt.dispatcher, tt.params); !strings.HasSuffix(got, tt.want) {
+			t.Errorf("BindPhrase(%q, %q) = %q, want %q", tt.dispatcher, tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestCheatsheetWriters(t *testing.T) {
+	cfg := &HyprlandConfig{}
+	if err := parseLine("bind = SUPER, P, exec, a | b <c>", cfg); err != nil {
+		t.Fatal(err)
+	}
+	sheet := NewCheatsheet(cfg)
+
+	var md, page, text strings.Builder
+	if err := sheet.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if err := sheet.WriteHTML(&page); err != nil {
+		t.Fatal(err)
+	}
+	if err := sheet.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "| `SUPER + P` | Run a \\| b <c> |\n"; !strings.Contains(md.String(), want) {
+		t.Errorf("Markdown lacks %q:\n%s", want, md.String())
+	}
+	if want := "<tr><td><kbd>SUPER</kbd> + <kbd>P</kbd></td><td>Run a | b &lt;c&gt;</td></tr>"; !strings.Contains(page.String(), want) {
+		t.Errorf("HTML lacks %q:\n%s", want, page.String())
+	}
+	wantText := "Global\n======\nDISPATCHER  KEYS       ACTION\nexec        SUPER + P  Run a | b <c>\n"
+	if text.String() != wantText {
+		t.Errorf("text:\n%s\nwant:\n%s", text.String(), wantText)
+	}
+}
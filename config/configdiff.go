@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind tells whether an entry was added, removed or changed
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeChanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	}
+	return "changed"
+}
+
+// MarshalText lets change kinds appear as words in JSON
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// ConfigChange is an entry that differs between two configs. Entries are
+// matched by identity: options by path, binds by submap and key
+// combination, monitors, workspaces and curves by name, animations by
+// target and window rules by rule and matcher.
+type ConfigChange struct {
+	Kind    ChangeKind `json:"kind"`
+	Section string     `json:"section"` // keyword, or "option" and "variable"
+	Name    string     `json:"name"`
+	Old     string     `json:"old,omitempty"`
+	New     string     `json:"new,omitempty"`
+	OldAt   *Source    `json:"old_source,omitempty"`
+	NewAt   *Source    `json:"new_source,omitempty"`
+}
+
+func (c ConfigChange) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return strings.TrimSuffix(fmt.Sprintf("+ %s %s: %s", c.Section, c.Name, c.New), ": ")
+	case ChangeRemoved:
+		return strings.TrimSuffix(fmt.Sprintf("- %s %s: %s", c.Section, c.Name, c.Old), ": ")
+	}
+	return fmt.Sprintf("~ %s %s: %s -> %s", c.Section, c.Name, c.Old, c.New)
+}
+
+// diffEntry is an entry of one config as DiffConfigs compares it
+type diffEntry struct {
+	section string
+	id      string // identity within the section
+	name    string
+	value   string
+	at      Source
+}
+
+// DiffConfigs compares two configs entry by entry, ignoring formatting,
+// comments, the order of options and which file an entry was read from.
+// Values are compared with variables expanded.
+func DiffConfigs(a, b *ConfigTree) []ConfigChange {
+	ea, eb := diffEntries(a), diffEntries(b)
+	var changes []ConfigChange
+	for i := range ea {
+		changes = append(changes, diffSection(ea[i], eb[i])...)
+	}
+	return changes
+}
+
+// diffEntries returns the entries of a config by section: variables,
+// options, monitors, workspaces, binds, window rules, beziers, animations
+// and exec
+func diffEntries(tree *ConfigTree) [][]diffEntry {
+	cfg := tree.Config
+	e := ExportConfig(tree)
+	entries := make([][]diffEntry, 9)
+
+	for _, v := range e.Variables {
+		entries[0] = append(entries[0], diffEntry{"variable", v.Name, "$" + v.Name, v.Value, v.Source})
+	}
+	for _, o := range e.Options {
+		entries[1] = append(entries[1], diffEntry{"option", o.Path, o.Path, o.Value, o.Source})
+	}
+	for _, m := range cfg.Monitors {
+		value := strings.Join(m.Fields()[1:], ", ")
+		entries[2] = append(entries[2], diffEntry{"monitor", m.Name, m.Name, value, Source{m.File, m.Line}})
+	}
+	for _, ws := range cfg.Workspaces {
+		entries[3] = append(entries[3], diffEntry{"workspace", ws.Name, ws.Name, ws.Monitor, Source{ws.File, ws.Line}})
+	}
+	for _, b := range cfg.Binds {
+		combo := b.Combo(cfg.Variables)
+		name := combo
+		if b.Submap != "" {
+			name += " (submap " + b.Submap + ")"
+		}
+		value := cfg.expandVariables(b.Dispatcher)
+		if params := cfg.expandVariables(b.Params); params != "" {
+			value += ", " + params
+		}
+		if b.Flags != "" {
+			value += " (bind" + b.Flags + ")"
+		}
+		entries[4] = append(entries[4], diffEntry{"bind", b.Submap + "\x00" + combo, name, value, Source{b.File, b.Line}})
+	}
+	for _, r := range cfg.WindowRules {
+		name := r.Rule + ", " + cfg.expandVariables(r.Target)
+		entries[5] = append(entries[5], diffEntry{r.Keyword(), r.Keyword() + "\x00" + name, name, cfg.expandVariables(r.Value), Source{r.File, r.Line}})
+	}
+	for _, c := range cfg.Animations.Beziers {
+		var points []string
+		for _, p := range c.Points {
+			points = append(points, strconv.FormatFloat(p, 'f', -1, 64))
+		}
+		entries[6] = append(entries[6], diffEntry{"bezier", c.Name, c.Name, strings.Join(points, ", "), Source{c.File, c.Line}})
+	}
+	for _, an := range cfg.Animations.Animations {
+		_, value, _ := strings.Cut(formatAnimation(an), ", ")
+		entries[7] = append(entries[7], diffEntry{"animation", an.Target, an.Target, value, Source{an.File, an.Line}})
+	}
+	for _, x := range cfg.Exec {
+		command := cfg.expandVariables(x.Command)
+		entries[8] = append(entries[8], diffEntry{x.Kind, x.Kind + "\x00" + command, command, "", Source{x.File, x.Line}})
+	}
+	return entries
+}
+
+// diffSection matches the entries of a section by identity. Entries that
+// share an identity, such as a key bound twice, are paired up in order
+// after equal ones are taken out.
+func diffSection(a, b []diffEntry) []ConfigChange {
+	var order []string
+	byA := make(map[string][]diffEntry)
+	byB := make(map[string][]diffEntry)
+	for _, x := range a {
+		if _, ok := byA[x.id]; !ok {
+			order = append(order, x.id)
+		}
+		byA[x.id] = append(byA[x.id], x)
+	}
+	for _, y := range b {
+		if _, ok := byA[y.id]; !ok {
+			if _, ok := byB[y.id]; !ok {
+				order = append(order, y.id)
+			}
+		}
+		byB[y.id] = append(byB[y.id], y)
+	}
+
+	var changes []ConfigChange
+	for _, id := range order {
+		left, right := unmatched(byA[id], byB[id])
+		for i := 0; i < len(left) || i < len(right); i++ {
+			switch {
+			case i >= len(right):
+				x := left[i]
+				changes = append(changes, ConfigChange{Kind: ChangeRemoved, Section: x.section, Name: x.name, Old: x.value, OldAt: sourceRef(x.at)})
+			case i >= len(left):
+				y := right[i]
+				changes = append(changes, ConfigChange{Kind: ChangeAdded, Section: y.section, Name: y.name, New: y.value, NewAt: sourceRef(y.at)})
+			default:
+				x, y := left[i], right[i]
+				changes = append(changes, ConfigChange{Kind: ChangeChanged, Section: y.section, Name: y.name,
+					Old: x.value, New: y.value, OldAt: sourceRef(x.at), NewAt: sourceRef(y.at)})
+			}
+		}
+	}
+	return changes
+}
+
+// sameEntry reports whether two entries of the same identity are equal.
+// Option values are compared by type, so 0.9 equals 0.90.
+func sameEntry(x, y diffEntry) bool {
+	if x.section != y.section {
+		return false
+	}
+	if x.section == "option" {
+		return sameOptionValue(x.id, x.value, y.value)
+	}
+	return x.value == y.value
+}
+
+// unmatched drops the entries of a and b that have an equal counterpart
+func unmatched(a, b []diffEntry) ([]diffEntry, []diffEntry) {
+	used := make([]bool, len(b))
+	var left []diffEntry
+	for _, x := range a {
+		found := false
+		for j, y := range b {
+			if !used[j] && sameEntry(x, y) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			left = append(left, x)
+		}
+	}
+	var right []diffEntry
+	for j, y := range b {
+		if !used[j] {
+			right = append(right, y)
+		}
+	}
+	return left, right
+}
+
+func sourceRef(s Source) *Source {
+	if s == (Source{}) {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	load := func(content string) *ConfigTree {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "hyprland.conf")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tree, err := LoadConfigTree(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+
+	a := load(`$mod = SUPER
+$term = kitty
+monitor = DP-1, 2560x1440@144, 0x0, 1
+monitor = HDMI-A-1, 1920x1080@60, 2560x0, 1
+general {
+    gaps_in = 5
+    border_size = 2
+}
+decoration:active_opacity = 0.9
+bind = $mod, Return, exec, $term
+bind = $mod, Q, killactive,
+bind = $mod, F, fullscreen, 0
+windowrulev2 = float, class:^(pavucontrol)$
+windowrulev2 = size 50% 50%, class:^(mpv)$
+exec-once = waybar
+`)
+	// Reformatted, commented, reordered and with a renamed variable, plus
+	// real changes
+	b := load(`# my config
+$mainMod = SUPER
+$terminal = kitty
+monitor = DP-1,2560x1440@144,0x0,1.25
+general:border_size = 2
+general {
+    gaps_in = 8 # wider
+}
+decoration {
+    active_opacity = 0.90
+}
+bind = $mainMod, Q, killactive,
+bind = SUPER,return,exec,$terminal
+bind = $mainMod, F, fullscreen, 1
+bind = $mainMod, B, exec, firefox
+windowrulev2 = size 60% 60%, class:^(mpv)$
+windowrulev2 = float, class:^(pavucontrol)$
+exec-once = waybar
+exec-once = dunst
+`)
+
+	var got []string
+	for _, c := range DiffConfigs(a, b) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"- variable $mod: SUPER",
+		"- variable $term: kitty",
+		"+ variable $mainMod: SUPER",
+		"+ variable $terminal: kitty",
+		"~ option general:gaps_in: 5 -> 8",
+		"~ monitor DP-1: 2560x1440@144, 0x0, 1 -> 2560x1440@144, 0x0, 1.25",
+		"- monitor HDMI-A-1: 1920x1080@60, 2560x0, 1",
+		"~ bind SUPER+f: fullscreen, 0 -> fullscreen, 1",
+		"+ bind SUPER+b: exec, firefox",
+		"~ windowrulev2 size, class:^(mpv)$: 50% 50% -> 60% 60%",
+		"+ exec-once dunst",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("DiffConfigs():\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes := DiffConfigs(a, a); len(changes) != 0 {
+		t.Errorf("DiffConfigs() of a config with itself = %v", changes)
+	}
+}
@@ -0,0 +1,396 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArgKind is the grammar of a dispatcher argument
+type ArgKind int
+
+const (
+	ArgNone      ArgKind = iota
+	ArgCommand           // shell command, optionally prefixed with [rules]
+	ArgDirection         // l, r, u or d
+	ArgWorkspace         // workspace specifier, e.g. 3, e+1, name:web, special:term
+	ArgWindow            // window selector, e.g. class:^kitty$, address:0x1234
+	ArgResize            // pixel or percent delta, or "exact W H"
+	ArgMonitor           // monitor name, direction or relative index
+	ArgInt
+	ArgEnum   // one of Dispatcher.Values
+	ArgString // free-form text
+)
+
+func (k ArgKind) String() string {
+	switch k {
+	case ArgNone:
+		return "none"
+	case ArgCommand:
+		return "command"
+	case ArgDirection:
+		return "direction"
+	case ArgWorkspace:
+		return "workspace"
+	case ArgWindow:
+		return "window"
+	case ArgResize:
+		return "resize"
+	case ArgMonitor:
+		return "monitor"
+	case ArgInt:
+		return "int"
+	case ArgEnum:
+		return "enum"
+	}
+	return "string"
+}
+
+// Dispatcher describes a Hyprland dispatcher and the argument it takes
+type Dispatcher struct {
+	Name        string
+	Arg         ArgKind
+	Optional    bool     // the argument may be omitted
+	Values      []string // allowed values for ArgEnum
+	Window      bool     // accepts a trailing ",window" selector
+	Description string
+}
+
+var dispatchers = []Dispatcher{
+	// Programs and session
+	{Name: "exec", Arg: ArgCommand, Description: "Run a shell command, optionally with [window rules]"},
+	{Name: "execr", Arg: ArgCommand, Description: "Run a raw shell command without rules"},
+	{Name: "pass", Arg: ArgWindow, Description: "Pass the key to a window"},
+	{Name: "sendshortcut", Arg: ArgString, Description: "Send a key combination (mod, key[, window]) to a window"},
+	{Name: "sendkeystate", Arg: ArgString, Description: "Send a key in a state (mod, key, down|repeat|up, window) to a window"},
+	{Name: "global", Arg: ArgString, Description: "Trigger a global shortcut registered by an application"},
+	{Name: "exit", Arg: ArgNone, Description: "Exit Hyprland"},
+	{Name: "dpms", Arg: ArgString, Description: "Set monitor power: on, off or toggle, optionally followed by a monitor"},
+	{Name: "forcerendererreload", Arg: ArgNone, Description: "Force the renderer to reload all resources"},
+	{Name: "forceidle", Arg: ArgString, Description: "Act as if the user had been idle for the given seconds"},
+	{Name: "event", Arg: ArgString, Description: "Emit a custom event on the event socket"},
+	{Name: "submap", Arg: ArgString, Description: "Switch to a submap, or reset"},
+
+	// Windows
+	{Name: "killactive", Arg: ArgNone, Description: "Close the active window"},
+	{Name: "closewindow", Arg: ArgWindow, Description: "Close a window"},
+	{Name: "forcekillactive", Arg: ArgNone, Description: "Kill the process of the active window"},
+	{Name: "killwindow", Arg: ArgWindow, Description: "Kill a window's process"},
+	{Name: "signal", Arg: ArgInt, Description: "Send a signal to the active window"},
+	{Name: "togglefloating", Arg: ArgWindow, Optional: true, Description: "Toggle floating for a window"},
+	{Name: "setfloating", Arg: ArgWindow, Optional: true, Description: "Make a window floating"},
+	{Name: "settiled", Arg: ArgWindow, Optional: true, Description: "Make a window tiled"},
+	{Name: "fullscreen", Arg: ArgEnum, Optional: true, Values: []string{"0", "1", "2"},
+		Description: "Toggle fullscreen: 0 fullscreen, 1 maximize, 2 fullscreen without the client knowing"},
+	{Name: "fullscreenstate", Arg: ArgString, Description: "Set the internal and client fullscreen state"},
+	{Name: "pseudo", Arg: ArgWindow, Optional: true, Description: "Toggle pseudotiling"},
+	{Name: "pin", Arg: ArgWindow, Optional: true, Description: "Pin a floating window to all workspaces"},
+	{Name: "centerwindow", Arg: ArgEnum, Optional: true, Values: []string{"1"},
+		Description: "Center the active floating window, 1 respects reserved areas"},
+	{Name: "movefocus", Arg: ArgDirection, Description: "Move focus in a direction"},
+	{Name: "movewindow", Arg: ArgString, Optional: true,
+		Description: "Move the active window in a direction or to mon:NAME; without argument drags with the mouse"},
+	{Name: "resizewindow", Arg: ArgNone, Description: "Resize the active window with the mouse"},
+	{Name: "swapwindow", Arg: ArgDirection, Description: "Swap the active window with its neighbour"},
+	{Name: "focuswindow", Arg: ArgWindow, Description: "Focus a window"},
+	{Name: "resizeactive", Arg: ArgResize, Description: "Resize the active window"},
+	{Name: "moveactive", Arg: ArgResize, Description: "Move the active window"},
+	{Name: "resizewindowpixel", Arg: ArgResize, Window: true, Description: "Resize a window"},
+	{Name: "movewindowpixel", Arg: ArgResize, Window: true, Description: "Move a window"},
+	{Name: "cyclenext", Arg: ArgEnum, Optional: true, Values: []string{"prev", "tiled", "floating", "visible", "hist"},
+		Description: "Focus the next window on the workspace"},
+	{Name: "swapnext", Arg: ArgEnum, Optional: true, Values: []string{"prev"}, Description: "Swap with the next window"},
+	{Name: "focusurgentorlast", Arg: ArgNone, Description: "Focus the urgent window or the last one"},
+	{Name: "focuscurrentorlast", Arg: ArgNone, Description: "Switch focus to the previous window"},
+	{Name: "bringactivetotop", Arg: ArgNone, Description: "Bring the active floating window to the top"},
+	{Name: "alterzorder", Arg: ArgEnum, Values: []string{"top", "bottom"}, Window: true,
+		Description: "Move a floating window to the top or bottom of the stack"},
+	{Name: "toggleopaque", Arg: ArgNone, Description: "Toggle the opaque property of the active window"},
+	{Name: "toggleswallow", Arg: ArgNone, Description: "Toggle swallowing of the active window's parent"},
+	{Name: "tagwindow", Arg: ArgString, Description: "Toggle a tag on a window"},
+	{Name: "setprop", Arg: ArgString, Description: "Set a window property"},
+	{Name: "movecursortocorner", Arg: ArgEnum, Values: []string{"0", "1", "2", "3"},
+		Description: "Move the cursor to a corner of the active window"},
+	{Name: "movecursor", Arg: ArgString, Description: "Move the cursor to x y"},
+
+	// Layouts
+	{Name: "togglesplit", Arg: ArgNone, Description: "Toggle the split direction (dwindle)"},
+	{Name: "swapsplit", Arg: ArgNone, Description: "Swap the two halves of a split (dwindle)"},
+	{Name: "splitratio", Arg: ArgString, Description: "Change the split ratio (dwindle)"},
+	{Name: "layoutmsg", Arg: ArgString, Description: "Send a message to the current layout"},
+
+	// Workspaces
+	{Name: "workspace", Arg: ArgWorkspace, Description: "Switch to a workspace"},
+	{Name: "movetoworkspace", Arg: ArgWorkspace, Window: true, Description: "Move a window to a workspace and follow it"},
+	{Name: "movetoworkspacesilent", Arg: ArgWorkspace, Window: true, Description: "Move a window to a workspace"},
+	{Name: "togglespecialworkspace", Arg: ArgString, Optional: true, Description: "Toggle a special workspace"},
+	{Name: "focusworkspaceoncurrentmonitor", Arg: ArgWorkspace, Description: "Focus a workspace on the current monitor"},
+	{Name: "renameworkspace", Arg: ArgString, Description: "Rename a workspace: id name"},
+	{Name: "workspaceopt", Arg: ArgEnum, Values: []string{"allfloat", "allpseudo"},
+		Description: "Toggle an option for the active workspace"},
+
+	// Monitors
+	{Name: "focusmonitor", Arg: ArgMonitor, Description: "Focus a monitor"},
+	{Name: "movecurrentworkspacetomonitor", Arg: ArgMonitor, Description: "Move the active workspace to a monitor"},
+	{Name: "moveworkspacetomonitor", Arg: ArgString, Description: "Move a workspace to a monitor: workspace monitor"},
+	{Name: "swapactiveworkspaces", Arg: ArgString, Description: "Swap the active workspaces of two monitors"},
+
+	// Groups
+	{Name: "togglegroup", Arg: ArgNone, Description: "Toggle the active window in and out of a group"},
+	{Name: "changegroupactive", Arg: ArgString, Optional: true, Description: "Switch to the next (f), previous (b) or n-th window in a group"},
+	{Name: "lockgroups", Arg: ArgEnum, Values: []string{"lock", "unlock", "toggle"}, Description: "Lock all groups"},
+	{Name: "lockactivegroup", Arg: ArgEnum, Values: []string{"lock", "unlock", "toggle"}, Description: "Lock the active group"},
+	{Name: "moveintogroup", Arg: ArgDirection, Description: "Move the active window into a neighbouring group"},
+	{Name: "moveoutofgroup", Arg: ArgWindow, Optional: true, Description: "Move a window out of its group"},
+	{Name: "movewindoworgroup", Arg: ArgDirection, Description: "Move the active window into or out of groups"},
+	{Name: "movegroupwindow", Arg: ArgEnum, Values: []string{"f", "b"}, Description: "Swap the active window with the next or previous one in its group"},
+	{Name: "denywindowfromgroup", Arg: ArgEnum, Values: []string{"on", "off", "toggle"}, Description: "Prohibit the active window from joining groups"},
+	{Name: "setignoregrouplock", Arg: ArgEnum, Values: []string{"on", "off", "toggle"}, Description: "Ignore group locks"},
+}
+
+var dispatcherIndex = make(map[string]Dispatcher)
+
+func init() {
+	for _, d := range dispatchers {
+		dispatcherIndex[d.Name] = d
+	}
+}
+
+// LookupDispatcher returns the catalog entry for a dispatcher name
+func LookupDispatcher(name string) (Dispatcher, bool) {
+	d, ok := dispatcherIndex[strings.ToLower(strings.TrimSpace(name))]
+	return d, ok
+}
+
+// Dispatchers returns all known dispatchers sorted by name
+func Dispatchers() []Dispatcher {
+	list := append([]Dispatcher(nil), dispatchers...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// CompleteDispatcher returns the dispatcher names starting with prefix
+func CompleteDispatcher(prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	var names []string
+	for _, d := range Dispatchers() {
+		if strings.HasPrefix(d.Name, prefix) {
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+// Completions returns example arguments for the bind editor
+func (d Dispatcher) Completions() []string {
+	switch d.Arg {
+	case ArgEnum:
+		return d.Values
+	case ArgDirection:
+		return []string{"l", "r", "u", "d"}
+	case ArgWorkspace:
+		return []string{"1", "e+1", "e-1", "m+1", "m-1", "previous", "empty", "name:", "special:"}
+	case ArgWindow:
+		return windowSelectorPrefixes
+	case ArgResize:
+		return []string{"10 0", "-10 0", "0 10", "0 -10", "exact 50% 50%"}
+	case ArgMonitor:
+		return []string{"l", "r", "u", "d", "+1", "-1", "current"}
+	case ArgCommand:
+		if d.Name == "exec" {
+			return []string{"[float] "}
+		}
+	}
+	return nil
+}
+
+// IsPluginDispatcher reports whether name is a dispatcher of a plugin,
+// written plugin:name such as hyprexpo:expo. hyprmax cannot know them.
+func IsPluginDispatcher(name string) bool {
+	plugin, dispatcher, ok := strings.Cut(name, ":")
+	return ok && plugin != "" && dispatcher != "" && !strings.ContainsAny(name, " \t,")
+}
+
+// ValidateDispatcher checks that value names a known dispatcher. Plugin
+// dispatchers are accepted unchecked.
+func ValidateDispatcher(field, value string) error {
+	if IsPluginDispatcher(value) {
+		return nil
+	}
+	if _, ok := LookupDispatcher(value); !ok {
+		return &ValidationError{field, value, "invalid dispatcher"}
+	}
+	return nil
+}
+
+// ValidateDispatcherParams checks params against the argument grammar of
+// the dispatcher. Unknown dispatchers are reported by ValidateDispatcher.
+func ValidateDispatcherParams(dispatcher, params string) error {
+	d, ok := LookupDispatcher(dispatcher)
+	if !ok {
+		return nil
+	}
+	field := d.Name
+	params = strings.TrimSpace(params)
+
+	if params == "" {
+		if d.Arg == ArgNone || d.Optional {
+			return nil
+		}
+		return &ValidationError{field, params, fmt.Sprintf("needs a %s argument", d.Arg)}
+	}
+
+	arg, window := params, ""
+	if d.Window {
+		if i := strings.Index(params, ","); i >= 0 {
+			arg, window = strings.TrimSpace(params[:i]), strings.TrimSpace(params[i+1:])
+			if msg := checkWindowSelector(window); msg != "" {
+				return &ValidationError{field, window, msg}
+			}
+		}
+	}
+
+	var msg string
+	switch d.Arg {
+	case ArgNone:
+		msg = "takes no argument"
+	case ArgCommand:
+		msg = checkCommand(d.Name, arg)
+	case ArgDirection:
+		msg = checkDirection(arg)
+	case ArgWorkspace:
+		msg = checkWorkspace(arg)
+	case ArgWindow:
+		msg = checkWindowSelector(arg)
+	case ArgResize:
+		msg = checkResize(arg)
+	case ArgInt:
+		if _, err := strconv.Atoi(arg); err != nil {
+			msg = "must be a number"
+		}
+	case ArgEnum:
+		if !containsString(d.Values, arg) {
+			msg = "must be one of: " + strings.Join(d.Values, ", ")
+		}
+	}
+	if msg != "" {
+		return &ValidationError{field, arg, msg}
+	}
+	return nil
+}
+
+var directions = map[string]bool{
+	"l": true, "r": true, "u": true, "d": true, "t": true, "b": true,
+	"left": true, "right": true, "up": true, "down": true, "top": true, "bottom": true,
+}
+
+func checkDirection(arg string) string {
+	if !directions[arg] {
+		return "direction must be l, r, u or d"
+	}
+	return ""
+}
+
+// relativeWorkspace matches +1, -2, e+1, m-1, r~3 and the like
+var relativeWorkspace = regexp.MustCompile(`^([emr]?[+-]|[emr]~)\d+$`)
+
+func checkWorkspace(arg string) string {
+	switch {
+	case arg == "previous", arg == "previous_per_monitor", arg == "special":
+		return ""
+	case strings.HasPrefix(arg, "name:"), strings.HasPrefix(arg, "special:"):
+		if _, name, _ := strings.Cut(arg, ":"); name == "" {
+			return "workspace name is empty"
+		}
+		return ""
+	case strings.HasPrefix(arg, "empty"):
+		// empty takes the flags m (on monitor) and n (next)
+		if strings.Trim(arg[len("empty"):], "mn") != "" {
+			return "empty only takes the flags m and n"
+		}
+		return ""
+	case relativeWorkspace.MatchString(arg):
+		return ""
+	}
+	if n, err := strconv.Atoi(arg); err != nil || n < 1 {
+		return "workspace must be an ID, a relative offset (e+1, m-1), name:NAME, special[:NAME], previous or empty"
+	}
+	return ""
+}
+
+// windowSelectorPrefixes are the ways to select a window in a dispatcher
+var windowSelectorPrefixes = []string{
+	"class:", "initialclass:", "title:", "initialtitle:", "tag:", "pid:", "address:",
+	"activewindow", "floating", "tiled",
+}
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+
+func checkWindowSelector(arg string) string {
+	switch arg {
+	case "activewindow", "floating", "tiled":
+		return ""
+	}
+	kind, value, ok := strings.Cut(arg, ":")
+	if !ok {
+		return "window selector must be class:, title:, initialclass:, initialtitle:, tag:, pid: or address:"
+	}
+	switch kind {
+	case "class", "initialclass", "title", "initialtitle":
+		if _, err := regexp.Compile(value); err != nil {
+			return "invalid regular expression: " + err.Error()
+		}
+	case "tag":
+		if value == "" {
+			return "tag is empty"
+		}
+	case "pid":
+		if _, err := strconv.Atoi(value); err != nil {
+			return "pid must be a number"
+		}
+	case "address":
+		if !addressPattern.MatchString(value) {
+			return "address must be hexadecimal, e.g. address:0x5581f5a0"
+		}
+	default:
+		return fmt.Sprintf("unknown window selector %q", kind)
+	}
+	return ""
+}
+
+var resizeValue = regexp.MustCompile(`^-?\d+(\.\d+)?%?$`)
+
+func checkResize(arg string) string {
+	fields := strings.Fields(arg)
+	if len(fields) > 0 && fields[0] == "exact" {
+		fields = fields[1:]
+	}
+	if len(fields) != 2 {
+		return `must be two values "X Y", optionally prefixed with exact`
+	}
+	for _, f := range fields {
+		if !resizeValue.MatchString(f) {
+			return fmt.Sprintf("%q is not a number of pixels or a percentage", f)
+		}
+	}
+	return ""
+}
+
+func checkCommand(dispatcher, arg string) string {
+	if dispatcher == "exec" && strings.HasPrefix(arg, "[") {
+		end := strings.Index(arg, "]")
+		if end < 0 {
+			return "window rules are missing the closing ]"
+		}
+		if strings.TrimSpace(arg[1:end]) == "" {
+			return "window rules are empty"
+		}
+		arg = strings.TrimSpace(arg[end+1:])
+	}
+	if arg == "" {
+		return "command is empty"
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package config
+
+import "testing"
+
+func TestValidateDispatcherParams(t *testing.T) {
+	tests := []struct {
+		dispatcher string
+		params     string
+		wantErr    bool
+	}{
+		{"exec", "kitty", false},
+		{"exec", "[float; size 800 600] kitty", false},
+		{"exec", "[float kitty", true},
+		{"exec", "", true},
+		{"killactive", "", false},
+		{"killactive", "now", true},
+		{"movefocus", "l", false},
+		{"movefocus", "x", true},
+		{"workspace", "3", false},
+		{"workspace", "e+1", false},
+		{"workspace", "m-1", false},
+		{"workspace", "r~2", false},
+		{"workspace", "name:web", false},
+		{"workspace", "special:term", false},
+		{"workspace", "special", false},
+		{"workspace", "emptynm", false},
+		{"workspace", "previous", false},
+		{"workspace", "0", true},
+		{"workspace", "x+1", true},
+		{"workspace", "name:", true},
+		{"movetoworkspace", "2,class:^kitty$", false},
+		{"movetoworkspace", "2,class:(kitty", true},
+		{"focuswindow", "address:0x5581f5a0", false},
+		{"focuswindow", "address:nothex", true},
+		{"focuswindow", "kitty", true},
+		{"togglefloating", "", false},
+		{"resizeactive", "10 -10", false},
+		{"resizeactive", "exact 50% 50%", false},
+		{"resizeactive", "10", true},
+		{"resizewindowpixel", "exact 800 600,title:^Firefox$", false},
+		{"fullscreen", "1", false},
+		{"fullscreen", "3", true},
+		{"signal", "9", false},
+		{"unknowndispatcher", "anything", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dispatcher+" "+tt.params, func(t *testing.T) {
+			err := ValidateDispatcherParams(tt.dispatcher, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDispatcherParams(%q, %q) error = %v, wantErr %v",
+					tt.dispatcher, tt.params, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBind(t *testing.T) {
+	vars := map[string]string{"mainMod": "SUPER", "term": "kitty"}
+
+	tests := []struct {
+		name    string
+		bind    Bind
+		wantErr bool
+	}{
+		{"valid", Bind{Mods: "$mainMod", Key: "Return", Dispatcher: "exec", Params: "$term"}, false},
+		{"mouse bind", Bind{Flags: "m", Mods: "SUPER", Key: "mouse:272", Dispatcher: "movewindow"}, false},
+		{"bad modifier", Bind{Mods: "HYPER", Key: "Q", Dispatcher: "killactive"}, true},
+		{"bad key", Bind{Mods: "SUPER", Key: "Retrun", Dispatcher: "killactive"}, true},
+		{"bad dispatcher", Bind{Mods: "SUPER", Key: "Q", Dispatcher: "closeactive"}, true},
+		{"plugin dispatcher", Bind{Mods: "SUPER", Key: "grave", Dispatcher: "hyprexpo:expo", Params: "toggle"}, false},
+		{"empty plugin name", Bind{Mods: "SUPER", Key: "grave", Dispatcher: ":expo"}, true},
+		{"toggleswallow", Bind{Mods: "SUPER", Key: "S", Dispatcher: "toggleswallow"}, false},
+		{"forcekillactive", Bind{Mods: "SUPER SHIFT", Key: "Q", Dispatcher: "forcekillactive"}, false},
+		{"sendkeystate", Bind{Mods: "SUPER", Key: "F", Dispatcher: "sendkeystate", Params: ", F, down, class:^kitty$"}, false},
+		{"forceidle", Bind{Mods: "SUPER", Key: "I", Dispatcher: "forceidle", Params: "300"}, false},
+		{"bad params", Bind{Mods: "SUPER", Key: "1", Dispatcher: "workspace", Params: "one"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBind(tt.bind, vars); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompleteDispatcher(t *testing.T) {
+	got := CompleteDispatcher("movetow")
+	if len(got) != 2 || got[0] != "movetoworkspace" || got[1] != "movetoworkspacesilent" {
+		t.Errorf("CompleteDispatcher(\"movetow\") = %v", got)
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteOptionsReference writes a Markdown reference of every schema option,
+// grouped by category
+func WriteOptionsReference(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "# Hyprland Options"); err != nil {
+		return err
+	}
+
+	category := ""
+	for _, opt := range registry {
+		if opt.Category() != category {
+			category = opt.Category()
+			fmt.Fprintf(w, "\n## %s\n\n", category)
+			fmt.Fprintln(w, "| Option | Type | Default | Range | Description |")
+			fmt.Fprintln(w, "|--------|------|---------|-------|-------------|")
+		}
+
+		var notes []string
+		if opt.Restart {
+			notes = append(notes, "requires restart")
+		}
+		if opt.AddedIn != "" {
+			notes = append(notes, "since "+opt.AddedIn)
+		}
+		if opt.DeprecatedIn != "" {
+			notes = append(notes, "deprecated in "+opt.DeprecatedIn)
+		}
+		description := opt.Description
+		if len(notes) > 0 {
+			description += " (" + strings.Join(notes, ", ") + ")"
+		}
+
+		if _, err := fmt.Fprintf(w, "| `%s` | %s | `%s` | %s | %s |\n",
+			opt.Name(), opt.Type, opt.Default, opt.Constraint(), description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
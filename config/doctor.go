@@ -0,0 +1,464 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+// DoctorStatus is the outcome of a doctor check
+type DoctorStatus int
+
+const (
+	DoctorOK DoctorStatus = iota
+	DoctorSkipped
+	DoctorWarning
+	DoctorFailed
+)
+
+func (s DoctorStatus) String() string {
+	switch s {
+	case DoctorOK:
+		return "ok"
+	case DoctorSkipped:
+		return "skip"
+	case DoctorWarning:
+		return "warn"
+	}
+	return "fail"
+}
+
+// MarshalText lets statuses appear as words in JSON
+func (s DoctorStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// DoctorCheck is the result of one diagnostic
+type DoctorCheck struct {
+	Name    string       `json:"name"`
+	Status  DoctorStatus `json:"status"`
+	Message string       `json:"message"`
+	Hint    string       `json:"hint,omitempty"` // what to do about it
+}
+
+// DoctorFS is the part of the filesystem the doctor looks at, so tests can
+// run against a fake one
+type DoctorFS interface {
+	StatFS
+	Lstat(name string) (fs.FileInfo, error)
+	EvalSymlinks(path string) (string, error)
+	Glob(pattern string) ([]string, error)
+	Writable(path string) bool
+}
+
+func (osFS) Lstat(name string) (fs.FileInfo, error)   { return os.Lstat(name) }
+func (osFS) EvalSymlinks(path string) (string, error) { return filepath.EvalSymlinks(path) }
+func (osFS) Glob(pattern string) ([]string, error)    { return filepath.Glob(pattern) }
+
+// Writable asks access(2), which also accounts for read-only mounts
+func (osFS) Writable(path string) bool {
+	const wOK = 2 // W_OK
+	return syscall.Access(path, wOK) == nil
+}
+
+// HyprlandProbe is the part of the running compositor the doctor asks.
+// *ipc.Client implements it.
+type HyprlandProbe interface {
+	Version() (*ipc.Version, error)
+	ConfigErrors() ([]string, error)
+}
+
+// backupWarnSize is the total size of backups the doctor warns about
+const backupWarnSize = 50 << 20
+
+// Doctor diagnoses the environment hyprmax runs in
+type Doctor struct {
+	Path     string // config file in use
+	Explicit bool   // Path was given on the command line rather than resolved
+	Getenv   func(string) string
+	FS       DoctorFS
+	Connect  func() (HyprlandProbe, error) // reaches the running Hyprland
+}
+
+// NewDoctor returns a doctor for the config at path in the current
+// environment
+func NewDoctor(path string, explicit bool) *Doctor {
+	return &Doctor{
+		Path:     path,
+		Explicit: explicit,
+		Getenv:   os.Getenv,
+		FS:       osFS{},
+		Connect: func() (HyprlandProbe, error) {
+			client, err := ipc.NewClient()
+			if err != nil {
+				return nil, err
+			}
+			return client, nil
+		},
+	}
+}
+
+// Run performs every check in order. Checks that need the parsed config or
+// the compositor are skipped when those are unavailable.
+func (d *Doctor) Run() []DoctorCheck {
+	checks := []DoctorCheck{d.checkPath()}
+
+	tree, check := d.checkSources()
+	checks = append(checks, check)
+	files := []string{d.Path}
+	if tree != nil {
+		files = nil
+		for _, doc := range tree.Documents {
+			files = append(files, doc.Path)
+		}
+	}
+	checks = append(checks, d.checkPermissions(files), d.checkSymlinks(files), d.checkBackups(files))
+
+	version, check := d.checkHyprland()
+	checks = append(checks, check, d.checkVersion(tree, version))
+	if tree == nil {
+		skipped := "the config could not be loaded"
+		return append(checks,
+			DoctorCheck{Name: "deprecated options", Status: DoctorSkipped, Message: skipped},
+			DoctorCheck{Name: "lint", Status: DoctorSkipped, Message: skipped})
+	}
+	return append(checks, d.checkDeprecated(tree, version), d.checkLint(tree))
+}
+
+// checkPath reports which config file is used and why
+func (d *Doctor) checkPath() DoctorCheck {
+	c := DoctorCheck{Name: "config path"}
+	origin := "the default location"
+	switch {
+	case d.Explicit:
+		origin = "the command line"
+	case d.Getenv(ConfigPathEnv) != "":
+		origin = "$" + ConfigPathEnv
+	case d.Getenv("XDG_CONFIG_HOME") != "":
+		origin = "$XDG_CONFIG_HOME"
+	}
+
+	info, err := d.FS.Stat(d.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		c.Status = DoctorFailed
+		c.Message = fmt.Sprintf("%s (from %s) does not exist", d.Path, origin)
+		c.Hint = "create it, or point --config or $" + ConfigPathEnv + " at your hyprland.conf"
+		return c
+	case err != nil:
+		c.Status = DoctorFailed
+		c.Message = err.Error()
+		return c
+	case info.IsDir():
+		c.Status = DoctorFailed
+		c.Message = fmt.Sprintf("%s (from %s) is a directory", d.Path, origin)
+		c.Hint = "pass the hyprland.conf inside it"
+		return c
+	}
+	c.Message = fmt.Sprintf("%s (from %s)", d.Path, origin)
+
+	// Hyprland ignores $HYPRMAX_CONFIG and --config
+	hyprland, err := ExpandPath(resolveConfigPath(func(key string) string {
+		if key == ConfigPathEnv {
+			return ""
+		}
+		return d.Getenv(key)
+	}))
+	if err == nil && filepath.Clean(hyprland) != filepath.Clean(d.Path) {
+		c.Status = DoctorWarning
+		c.Message += ", but Hyprland reads " + hyprland
+		c.Hint = "changes only take effect if " + hyprland + " sources this file"
+	}
+	return c
+}
+
+// checkSources loads the config with the files it sources and reports
+// source patterns that match nothing
+func (d *Doctor) checkSources() (*ConfigTree, DoctorCheck) {
+	c := DoctorCheck{Name: "sources"}
+	tree, err := LoadConfigTree(d.Path)
+	if err != nil {
+		c.Status = DoctorFailed
+		c.Message = err.Error()
+		c.Hint = "fix the file or source line; the checks below need the whole config"
+		return nil, c
+	}
+
+	dir := filepath.Dir(tree.Documents[0].Path)
+	var empty []string
+	for _, doc := range tree.Documents {
+		for _, line := range doc.Lines {
+			if line.Kind != LineAssign || line.Section != "" || line.Key != "source" {
+				continue
+			}
+			if files, err := sourceFiles(dir, tree.Config.expandVariables(line.Value)); err == nil && len(files) == 0 {
+				empty = append(empty, fmt.Sprintf("%s:%d: %s", doc.Path, line.orig, line.Value))
+			}
+		}
+	}
+
+	c.Message = fmt.Sprintf("%d file(s) read, %d sourced", len(tree.Documents), len(tree.Documents)-1)
+	if len(empty) > 0 {
+		c.Status = DoctorWarning
+		c.Message = fmt.Sprintf("source patterns match no files: %s", summarize(empty))
+		c.Hint = "check the pattern, or remove the line if the files are gone for good"
+	}
+	return tree, c
+}
+
+// checkPermissions reports files hyprmax cannot write, and directories it
+// cannot write backups to
+func (d *Doctor) checkPermissions(files []string) DoctorCheck {
+	c := DoctorCheck{Name: "permissions", Message: "config files and their directories are writable"}
+	var problems []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if _, err := d.FS.Stat(file); err != nil {
+			continue
+		}
+		if !d.FS.Writable(file) {
+			problems = append(problems, file+" is read-only")
+		}
+		if dir := filepath.Dir(file); !seen[dir] {
+			seen[dir] = true
+			if !d.FS.Writable(dir) {
+				problems = append(problems, dir+" is not writable, so no backups can be made there")
+			}
+		}
+	}
+	if len(problems) > 0 {
+		c.Status = DoctorWarning
+		c.Message = strings.Join(problems, "; ")
+		c.Hint = "set, fmt, import and migrate cannot save there; fix the permissions, or edit the file the config is generated from"
+	}
+	return c
+}
+
+// checkSymlinks reports config files that are symlinks, which is how
+// dotfile managers and home-manager install them
+func (d *Doctor) checkSymlinks(files []string) DoctorCheck {
+	c := DoctorCheck{Name: "symlinks", Message: "no config file is a symlink"}
+	var links []string
+	for _, file := range files {
+		info, err := d.FS.Lstat(file)
+		if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+		target, err := d.FS.EvalSymlinks(file)
+		switch {
+		case err != nil:
+			c.Status = DoctorFailed
+			c.Message = file + " is a broken symlink"
+			c.Hint = "restore the file it points to, or replace the link with a regular file"
+			return c
+		case strings.HasPrefix(target, "/nix/store/"):
+			c.Status = DoctorWarning
+			c.Message = file + " links into the Nix store"
+			c.Hint = "it is generated by home-manager and read-only; change the Nix config instead, see hyprmax export --format nix"
+			return c
+		}
+		links = append(links, file+" -> "+target)
+	}
+	if len(links) > 0 {
+		c.Message = "edits are written through " + strings.Join(links, ", ")
+	}
+	return c
+}
+
+// checkBackups adds up the backups hyprmax left next to the config files
+func (d *Doctor) checkBackups(files []string) DoctorCheck {
+	c := DoctorCheck{Name: "backups"}
+	count, size := 0, int64(0)
+	var patterns []string
+	for _, file := range files {
+		pattern := file + ".*.backup"
+		backups, _ := d.FS.Glob(pattern)
+		if len(backups) > 0 {
+			patterns = append(patterns, pattern)
+		}
+		for _, b := range backups {
+			if info, err := d.FS.Stat(b); err == nil {
+				count++
+				size += info.Size()
+			}
+		}
+	}
+
+	c.Message = fmt.Sprintf("%d backup(s) using %s", count, formatSize(size))
+	if size > backupWarnSize {
+		c.Status = DoctorWarning
+		c.Hint = "every write keeps a backup and none are removed; delete the ones you no longer need: " + strings.Join(patterns, " ")
+	}
+	return c
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KiB"
+	for _, s := range []string{"MiB", "GiB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, s
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// checkHyprland reaches the running compositor and returns its version
+func (d *Doctor) checkHyprland() (string, DoctorCheck) {
+	c := DoctorCheck{Name: "hyprland"}
+	probe, err := d.Connect()
+	switch {
+	case errors.Is(err, ipc.ErrNoInstance):
+		c.Status = DoctorSkipped
+		c.Message = "no running instance, HYPRLAND_INSTANCE_SIGNATURE is not set"
+		c.Hint = "run the doctor from a terminal inside Hyprland to check the compositor"
+		return "", c
+	case err != nil:
+		c.Status = DoctorFailed
+		c.Message = err.Error()
+		c.Hint = "Hyprland may have exited or run as another user; look for its socket in $XDG_RUNTIME_DIR/hypr"
+		return "", c
+	}
+
+	v, err := probe.Version()
+	if err != nil {
+		c.Status = DoctorFailed
+		c.Message = "socket not reachable: " + err.Error()
+		c.Hint = "the instance may have crashed; restart Hyprland or unset a stale HYPRLAND_INSTANCE_SIGNATURE"
+		return "", c
+	}
+	version := v.Semver()
+	c.Message = "Hyprland " + version + " is running"
+
+	if errs, err := probe.ConfigErrors(); err == nil && len(errs) > 0 {
+		c.Status = DoctorWarning
+		c.Message = fmt.Sprintf("Hyprland %s reports %d config error(s), the first: %s", version, len(errs), errs[0])
+		c.Hint = "run hyprmax lint to locate them"
+	}
+	return version, c
+}
+
+// checkVersion compares the running Hyprland with the releases the schema
+// covers and with the options in use
+func (d *Doctor) checkVersion(tree *ConfigTree, running string) DoctorCheck {
+	c := DoctorCheck{Name: "version"}
+	schema := SchemaVersion()
+	if running == "" {
+		c.Status = DoctorSkipped
+		c.Message = "Hyprland version unknown, the schema covers up to " + schema
+		return c
+	}
+
+	if compareVersions(minorVersion(running), minorVersion(schema)) > 0 {
+		c.Status = DoctorWarning
+		c.Message = fmt.Sprintf("Hyprland %s is newer than the schema, which covers up to %s", running, schema)
+		c.Hint = "options added since " + schema + " are reported as unknown; update hyprmax"
+		return c
+	}
+
+	if tree != nil {
+		var newer []string
+		for _, opt := range registry {
+			if opt.AddedIn != "" && tree.Config.IsSet(opt.Path) && compareVersions(opt.AddedIn, running) > 0 {
+				newer = append(newer, fmt.Sprintf("%s (%s)", opt.Path, opt.AddedIn))
+			}
+		}
+		if len(newer) > 0 {
+			c.Status = DoctorWarning
+			c.Message = fmt.Sprintf("Hyprland %s predates options in use: %s", running, summarize(newer))
+			c.Hint = "update Hyprland, or remove the options"
+			return c
+		}
+	}
+	c.Message = fmt.Sprintf("Hyprland %s is covered by the schema (up to %s)", running, schema)
+	return c
+}
+
+// minorVersion cuts a version to major.minor
+func minorVersion(v string) string {
+	parts := strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3)
+	return strings.Join(parts[:min(len(parts), 2)], ".")
+}
+
+// checkDeprecated reports the options migrate would rewrite for the running
+// version, or for the newest one when it is unknown
+func (d *Doctor) checkDeprecated(tree *ConfigTree, running string) DoctorCheck {
+	c := DoctorCheck{Name: "deprecated options"}
+	var found []string
+	for _, doc := range tree.Documents {
+		// Migrate edits the document, so it works on a copy
+		copied, err := ParseDocument(doc.Path, doc.String())
+		if err != nil {
+			continue
+		}
+		for _, change := range Migrate(copied, running) {
+			found = append(found, fmt.Sprintf("%s:%d %s", doc.Path, change.Line, change.Old))
+		}
+	}
+
+	release := "the latest Hyprland"
+	if running != "" {
+		release = "Hyprland " + running
+	}
+	if len(found) == 0 {
+		c.Message = "none for " + release
+		return c
+	}
+	c.Status = DoctorWarning
+	c.Message = fmt.Sprintf("%d deprecated for %s: %s", len(found), release, summarize(found))
+	c.Hint = "run hyprmax migrate to see the rewrite, hyprmax migrate --apply to save it"
+	return c
+}
+
+// summarize joins the first few items of a list that may be long
+func summarize(items []string) string {
+	const shown = 3
+	if len(items) <= shown {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:shown], ", "), len(items)-shown)
+}
+
+// checkLint summarizes the lint findings of every file
+func (d *Doctor) checkLint(tree *ConfigTree) DoctorCheck {
+	c := DoctorCheck{Name: "lint"}
+	var first *Diagnostic
+	errs, warnings := 0, 0
+	for _, diag := range (Linter{}).LintTree(tree) {
+		switch diag.Severity {
+		case SeverityError:
+			errs++
+		case SeverityWarning:
+			warnings++
+		default:
+			continue
+		}
+		if first == nil || diag.Severity < first.Severity {
+			diag := diag
+			first = &diag
+		}
+	}
+
+	c.Message = fmt.Sprintf("%d error(s), %d warning(s)", errs, warnings)
+	switch {
+	case errs > 0:
+		c.Status = DoctorFailed
+	case warnings > 0:
+		c.Status = DoctorWarning
+	default:
+		return c
+	}
+	c.Message += ", e.g. " + first.String()
+	c.Hint = "run hyprmax lint for the full list"
+	return c
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+// readOnlyFS is the real filesystem with some paths reported read-only,
+// which a test running as root cannot arrange with chmod
+type readOnlyFS struct {
+	osFS
+	readOnly map[string]bool
+}
+
+func (f readOnlyFS) Writable(path string) bool { return !f.readOnly[path] }
+
+type fakeProbe struct {
+	version string
+	errs    []string
+	err     error
+}
+
+func (p fakeProbe) Version() (*ipc.Version, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ipc.Version{Version: p.version}, nil
+}
+
+func (p fakeProbe) ConfigErrors() ([]string, error) { return p.errs, nil }
+
+func TestDoctor(t *testing.T) {
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	connect := func(p fakeProbe) func() (HyprlandProbe, error) {
+		return func() (HyprlandProbe, error) { return p, nil }
+	}
+
+	// A healthy setup: the file Hyprland reads, one sourced file and a backup
+	healthy := t.TempDir()
+	main := filepath.Join(healthy, "hypr", "hyprland.conf")
+	write(main, "source = ./colors.conf\ngeneral {\n    gaps_in = 5\n}\n")
+	write(filepath.Join(healthy, "hypr", "colors.conf"), "$accent = rgb(ff0000)\n")
+	write(main+".20240101_120000.backup", "old\n")
+
+	// A setup with a problem for every check
+	broken := t.TempDir()
+	other := filepath.Join(broken, "other.conf")
+	write(other, "source = ./conf.d/*.conf\nsource = ./linked.conf\ndecoration {\n    blur_size = 8\n}\n")
+	write(filepath.Join(broken, "real.conf"), "$a = 1\n")
+	if err := os.Symlink(filepath.Join(broken, "real.conf"), filepath.Join(broken, "linked.conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		doctor *Doctor
+		want   map[string]DoctorStatus
+		msgs   map[string]string // substrings of messages
+	}{
+		{
+			name: "healthy",
+			doctor: &Doctor{
+				Path:    main,
+				Getenv:  env(map[string]string{"XDG_CONFIG_HOME": healthy}),
+				FS:      osFS{},
+				Connect: connect(fakeProbe{version: "0.45.2"}),
+			},
+			want: map[string]DoctorStatus{
+				"config path": DoctorOK, "sources": DoctorOK, "permissions": DoctorOK, "symlinks": DoctorOK,
+				"backups": DoctorOK, "hyprland": DoctorOK, "version": DoctorOK,
+				"deprecated options": DoctorOK, "lint": DoctorOK,
+			},
+			msgs: map[string]string{
+				"config path": "from $XDG_CONFIG_HOME",
+				"sources":     "2 file(s) read, 1 sourced",
+				"backups":     "1 backup(s) using 4 B",
+				"hyprland":    "Hyprland 0.45.2 is running",
+			},
+		},
+		{
+			name: "broken",
+			doctor: &Doctor{
+				Path:    other,
+				Getenv:  env(map[string]string{ConfigPathEnv: other, "XDG_CONFIG_HOME": broken}),
+				FS:      readOnlyFS{readOnly: map[string]bool{other: true}},
+				Connect: connect(fakeProbe{version: "0.99.0", errs: []string{"config error: bad"}}),
+			},
+			want: map[string]DoctorStatus{
+				"config path": DoctorWarning, "sources": DoctorWarning, "permissions": DoctorWarning,
+				"symlinks": DoctorOK, "backups": DoctorOK, "hyprland": DoctorWarning, "version": DoctorWarning,
+				"deprecated options": DoctorWarning, "lint": DoctorWarning,
+			},
+			msgs: map[string]string{
+				"config path":        "Hyprland reads " + filepath.Join(broken, "hypr", "hyprland.conf"),
+				"sources":            "conf.d/*.conf",
+				"permissions":        other + " is read-only",
+				"symlinks":           "linked.conf -> ",
+				"hyprland":           "1 config error(s)",
+				"version":            "newer than the schema",
+				"deprecated options": "decoration:blur_size",
+			},
+		},
+		{
+			name: "missing",
+			doctor: &Doctor{
+				Path:     filepath.Join(broken, "missing.conf"),
+				Explicit: true,
+				Getenv:   env(nil),
+				FS:       osFS{},
+				Connect:  func() (HyprlandProbe, error) { return nil, ipc.ErrNoInstance },
+			},
+			want: map[string]DoctorStatus{
+				"config path": DoctorFailed, "sources": DoctorFailed, "hyprland": DoctorSkipped,
+				"version": DoctorSkipped, "deprecated options": DoctorSkipped, "lint": DoctorSkipped,
+			},
+			msgs: map[string]string{"config path": "from the command line) does not exist"},
+		},
+		{
+			name: "unreachable",
+			doctor: &Doctor{
+				Path:    main,
+				Getenv:  env(map[string]string{"XDG_CONFIG_HOME": healthy}),
+				FS:      osFS{},
+				Connect: connect(fakeProbe{err: errors.New("connection refused")}),
+			},
+			want: map[string]DoctorStatus{"hyprland": DoctorFailed, "version": DoctorSkipped},
+			msgs: map[string]string{"hyprland": "socket not reachable: connection refused"},
+		},
+	}
+
+	for _, tt := range tests {
+		checks := tt.doctor.Run()
+		if len(checks) != 9 {
+			t.Errorf("%s: got %d checks, want 9", tt.name, len(checks))
+		}
+		for _, c := range checks {
+			if want, ok := tt.want[c.Name]; ok && c.Status != want {
+				t.Errorf("%s: %s is %s (%s), want %s", tt.name, c.Name, c.Status, c.Message, want)
+			}
+			if msg, ok := tt.msgs[c.Name]; ok && !strings.Contains(c.Message, msg) {
+				t.Errorf("%s: %s message %q does not contain %q", tt.name, c.Name, c.Message, msg)
+			}
+			if c.Status >= DoctorWarning && c.Hint == "" {
+				t.Errorf("%s: %s has no hint", tt.name, c.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LineKind classifies a line of hyprland.conf
+type LineKind int
+
+const (
+	LineBlank LineKind = iota
+	LineComment
+	LineAssign // key = value
+	LineOpen   // category {
+	LineClose  // }
+)
+
+// Line is a single line of a config file. Raw is kept verbatim so the
+// document can be written back without losing formatting or comments.
+type Line struct {
+	Raw     string
+	Kind    LineKind
+	Num     int    // 1-based line number
+	Section string // enclosing category path, e.g. "decoration:blur"
+	Key     string // assignment key or category name
+	Value   string // assignment value without trailing comment
+	Comment string // trailing or full-line comment text
+
+	orig int // line number when the document was read, 0 for added lines
+}
+
+// Path returns the full option path of an assignment, e.g. "general:gaps_in"
+func (l *Line) Path() string {
+	if l.Section == "" {
+		return l.Key
+	}
+	return l.Section + ":" + l.Key
+}
+
+// ParseError is a syntax error in a config file
+type ParseError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Message)
+}
+
+// Document is a lossless, line based representation of a config file
+type Document struct {
+	Path  string
+	Lines []*Line
+
+	noFinalNewline bool
+}
+
+// ParseDocument splits content into classified lines and tracks the nesting
+// of category blocks
+func ParseDocument(path, content string) (*Document, error) {
+	doc := &Document{Path: path}
+	doc.noFinalNewline = content != "" && !strings.HasSuffix(content, "\n")
+	var stack []string
+	var opened []int // line numbers of the open blocks in stack
+
+	raw := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		raw = nil
+	}
+	for i, text := range raw {
+		line := &Line{Raw: text, Num: i + 1, Section: strings.Join(stack, ":"), orig: i + 1}
+		body, comment := splitComment(text)
+		line.Comment = comment
+		body = strings.TrimSpace(body)
+
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(text), "#"):
+			line.Kind = LineComment
+		case body == "":
+			line.Kind = LineBlank
+		case body == "}":
+			if len(stack) == 0 {
+				return nil, &ParseError{path, line.Num, "unexpected }"}
+			}
+			line.Kind = LineClose
+			stack, opened = stack[:len(stack)-1], opened[:len(opened)-1]
+			line.Section = strings.Join(stack, ":")
+		case strings.HasSuffix(body, "{"):
+			line.Kind = LineOpen
+			line.Key = strings.TrimSpace(strings.TrimSuffix(body, "{"))
+			if line.Key == "" {
+				return nil, &ParseError{path, line.Num, "block without a name"}
+			}
+			stack, opened = append(stack, line.Key), append(opened, line.Num)
+		default:
+			key, value, ok := strings.Cut(body, "=")
+			if !ok {
+				return nil, &ParseError{path, line.Num, fmt.Sprintf("expected key = value, got %q", body)}
+			}
+			line.Kind = LineAssign
+			line.Key = strings.TrimSpace(key)
+			line.Value = strings.TrimSpace(value)
+		}
+		doc.Lines = append(doc.Lines, line)
+	}
+
+	if len(stack) > 0 {
+		return nil, &ParseError{path, opened[len(opened)-1], fmt.Sprintf("unclosed block %q", strings.Join(stack, ":"))}
+	}
+	return doc, nil
+}
+
+// LoadDocument reads and parses the config file at path
+func LoadDocument(path string) (*Document, error) {
+	file, err := ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		path = file
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDocument(path, string(content))
+}
+
+// String renders the document exactly as it was read
+func (d *Document) String() string {
+	var sb strings.Builder
+	for i, line := range d.Lines {
+		sb.WriteString(line.Raw)
+		if i < len(d.Lines)-1 || !d.noFinalNewline {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// splitComment separates a comment from the line content. Inside values "##"
+// is Hyprland's escape for a literal "#".
+func splitComment(text string) (string, string) {
+	if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "#") {
+		return "", strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+	}
+
+	var body strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '#' {
+			body.WriteByte(text[i])
+			continue
+		}
+		if i+1 < len(text) && text[i+1] == '#' {
+			body.WriteByte('#')
+			i++
+			continue
+		}
+		return body.String(), strings.TrimSpace(text[i+1:])
+	}
+	return body.String(), ""
+}
+
+// indentUnit is used for lines the document creates itself
+const indentUnit = "    "
+
+// Find returns the last assignment to path, which is the one Hyprland uses
+func (d *Document) Find(path string) *Line {
+	for i := len(d.Lines) - 1; i >= 0; i-- {
+		if line := d.Lines[i]; line.Kind == LineAssign && line.Path() == path {
+			return line
+		}
+	}
+	return nil
+}
+
+// Set assigns value to the option at path. An existing assignment is
+// rewritten in place, keeping its indentation and comment; otherwise the
+// option is added to the innermost existing block of its category.
+func (d *Document) Set(path, value string) error {
+	if line := d.Find(path); line != nil {
+		if err := checkValue(value); err != nil {
+			return err
+		}
+		line.Raw = replaceValue(line, value)
+		d.reindex()
+		return nil
+	}
+	return d.insert(path, value, "", nil)
+}
+
+// Delete removes every assignment to path and reports whether there was one
+func (d *Document) Delete(path string) bool {
+	kept := d.Lines[:0]
+	found := false
+	for _, line := range d.Lines {
+		if line.Kind == LineAssign && line.Path() == path {
+			found = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	d.Lines = kept
+	d.reindex()
+	return found
+}
+
+// Rename moves the assignment of from to the option path to. The value,
+// trailing comment and comment lines directly above it move along.
+func (d *Document) Rename(from, to string) bool {
+	line := d.Find(from)
+	if line == nil || checkValue(line.Value) != nil {
+		return false
+	}
+	i := d.index(line)
+
+	// Stay on the same line when the new path can be expressed relative to
+	// the enclosing block without nesting, e.g. a top-level full path
+	if key, ok := relativeKey(line.Section, to); ok && (line.Section == "" || !strings.Contains(key, ":")) {
+		line.Raw = assignmentRaw(leadingSpace(line.Raw), key, line.Value, line.Comment)
+		d.reindex()
+		return true
+	}
+
+	start := i
+	for start > 0 && d.Lines[start-1].Kind == LineComment {
+		start--
+	}
+	leading := append([]*Line(nil), d.Lines[start:i]...)
+	d.Lines = append(d.Lines[:start], d.Lines[i+1:]...)
+	d.reindex()
+	d.insert(to, line.Value, line.Comment, leading)
+	return true
+}
+
+// Replace swaps the raw text of line, e.g. to turn it into a comment. raw
+// must not open or close a block.
+func (d *Document) Replace(line *Line, raw string) {
+	line.Raw = raw
+	d.reindex()
+}
+
+// insert adds an assignment inside the innermost existing block of the
+// path's category, creating the missing nested blocks
+func (d *Document) insert(path, value, comment string, leading []*Line) error {
+	if err := checkValue(value); err != nil {
+		return err
+	}
+	parts := strings.Split(path, ":")
+	categories, key := parts[:len(parts)-1], parts[len(parts)-1]
+
+	at, indent, depth := len(d.Lines), "", 0
+	for n := len(categories); n > 0; n-- {
+		if open, end := d.block(strings.Join(categories[:n], ":")); open >= 0 {
+			at, indent, depth = end, leadingSpace(d.Lines[open].Raw)+indentUnit, n
+			break
+		}
+	}
+
+	var raw []string
+	if depth == 0 && at > 0 && d.Lines[at-1].Kind != LineBlank {
+		raw = append(raw, "")
+	}
+	for _, cat := range categories[depth:] {
+		raw = append(raw, indent+cat+" {")
+		indent += indentUnit
+	}
+	for _, l := range leading {
+		raw = append(raw, indent+strings.TrimLeft(l.Raw, " \t"))
+	}
+	raw = append(raw, assignmentRaw(indent, key, value, comment))
+	for range categories[depth:] {
+		indent = strings.TrimSuffix(indent, indentUnit)
+		raw = append(raw, indent+"}")
+	}
+
+	added := make([]*Line, len(raw))
+	for i, text := range raw {
+		added[i] = &Line{Raw: text}
+	}
+	d.Lines = append(d.Lines[:at], append(added, d.Lines[at:]...)...)
+	d.reindex()
+	return nil
+}
+
+// block returns the indexes of the last block with the given full path and
+// of its closing line, or -1 when there is none
+func (d *Document) block(path string) (int, int) {
+	open := -1
+	for i, line := range d.Lines {
+		if line.Kind == LineOpen && joinPath(line.Section, line.Key) == path {
+			open = i
+		}
+	}
+	if open < 0 {
+		return -1, -1
+	}
+	depth := 0
+	for i := open; i < len(d.Lines); i++ {
+		switch d.Lines[i].Kind {
+		case LineOpen:
+			depth++
+		case LineClose:
+			depth--
+			if depth == 0 {
+				return open, i
+			}
+		}
+	}
+	return -1, -1
+}
+
+func (d *Document) index(line *Line) int {
+	for i, l := range d.Lines {
+		if l == line {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkValue reports values an assignment line cannot hold: read back, the
+// line would open or close a block, or end early
+func checkValue(value string) error {
+	if strings.ContainsAny(value, "\n\r") {
+		return fmt.Errorf("value %q spans several lines", value)
+	}
+	if strings.Count(value, "{") != strings.Count(value, "}") || strings.HasSuffix(strings.TrimSpace(value), "{") {
+		return fmt.Errorf("value %q would open or close a block, { and } must be balanced", value)
+	}
+	return nil
+}
+
+// reindex re-reads the raw lines so numbers, sections and values match
+// the edited text
+func (d *Document) reindex() {
+	fresh, err := ParseDocument(d.Path, d.String())
+	if err != nil {
+		// Edits check their values and only add balanced blocks, so this
+		// cannot happen
+		panic(err)
+	}
+	for i, line := range fresh.Lines {
+		line.orig = d.Lines[i].orig
+	}
+	d.Lines = fresh.Lines
+}
+
+// relativeKey returns path relative to the block section, if it is inside
+func relativeKey(section, path string) (string, bool) {
+	if section == "" {
+		return path, true
+	}
+	return strings.CutPrefix(path, section+":")
+}
+
+func joinPath(section, key string) string {
+	if section == "" {
+		return key
+	}
+	return section + ":" + key
+}
+
+// assignmentRaw renders "key = value # comment", escaping # in the value
+func assignmentRaw(indent, key, value, comment string) string {
+	raw := indent + key + " = " + strings.ReplaceAll(value, "#", "##")
+	if comment != "" {
+		raw += " # " + comment
+	}
+	return raw
+}
+
+// replaceValue swaps the value of an assignment, keeping the spacing around
+// "=" and before the comment as written
+func replaceValue(line *Line, value string) string {
+	escaped := strings.ReplaceAll(value, "#", "##")
+	if eq := strings.Index(line.Raw, "="); eq >= 0 {
+		rest := line.Raw[eq+1:]
+		prefix := line.Raw[:eq+1] + leadingSpace(rest)
+		old := strings.ReplaceAll(line.Value, "#", "##")
+		if after := rest[len(leadingSpace(rest)):]; strings.HasPrefix(after, old) {
+			return prefix + escaped + after[len(old):]
+		}
+	}
+	return assignmentRaw(leadingSpace(line.Raw), line.Key, value, line.Comment)
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	content := `# header
+$mod = SUPER
+
+decoration {
+    rounding = 10 # corners
+    blur {
+        size = 3
+    }
+}
+col = rgba(00ff00ff) ## not a comment
+`
+	doc, err := ParseDocument("test.conf", content)
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	if got := doc.String(); got != content {
+		t.Errorf("String() is not lossless:\n%s", got)
+	}
+
+	tests := []struct {
+		num     int
+		kind    LineKind
+		path    string
+		value   string
+		comment string
+	}{
+		{num: 1, kind: LineComment, comment: "header"},
+		{num: 2, kind: LineAssign, path: "$mod", value: "SUPER"},
+		{num: 3, kind: LineBlank},
+		{num: 4, kind: LineOpen, path: "decoration"},
+		{num: 5, kind: LineAssign, path: "decoration:rounding", value: "10", comment: "corners"},
+		{num: 7, kind: LineAssign, path: "decoration:blur:size", value: "3"},
+		{num: 8, kind: LineClose},
+		{num: 10, kind: LineAssign, path: "col", value: "rgba(00ff00ff) # not a comment"},
+	}
+	for _, tt := range tests {
+		line := doc.Lines[tt.num-1]
+		if line.Kind != tt.kind || line.Comment != tt.comment {
+			t.Errorf("line %d: kind = %v, comment = %q", tt.num, line.Kind, line.Comment)
+		}
+		if tt.kind == LineAssign && (line.Path() != tt.path || line.Value != tt.value) {
+			t.Errorf("line %d: path = %q, value = %q", tt.num, line.Path(), line.Value)
+		}
+	}
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	inputs := map[string]string{
+		"unclosed block":   "general {\n    gaps_in = 5\n",
+		"unexpected close": "gaps_in = 5\n}\n",
+		"missing equals":   "general {\n    gaps_in 5\n}\n",
+	}
+	for name, content := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseDocument("test.conf", content); err == nil {
+				t.Error("ParseDocument() succeeded")
+			}
+		})
+	}
+}
+
+func TestParseDocumentTestdata(t *testing.T) {
+	content, err := os.ReadFile("testdata/hyprland.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ParseDocument("testdata/hyprland.conf", string(content))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	if doc.String() != string(content) {
+		t.Error("testdata does not round-trip")
+	}
+}
+
+func TestDocumentEdits(t *testing.T) {
+	content := `# header
+general {
+    gaps_in = 5 # inner
+}
+
+decoration {
+    rounding = 10
+    # radius of the blur
+    blur_size = 3
+}
+decoration:blur_passes = 2
+`
+	tests := []struct {
+		name string
+		edit func(d *Document)
+		want string
+	}{
+		{
+			name: "set existing keeps comment",
+			edit: func(d *Document) { d.Set("general:gaps_in", "8") },
+			want: strings.Replace(content, "gaps_in = 5 # inner", "gaps_in = 8 # inner", 1),
+		},
+		{
+			name: "set new option in existing block",
+			edit: func(d *Document) { d.Set("general:gaps_out", "20") },
+			want: strings.Replace(content, "# inner\n}", "# inner\n    gaps_out = 20\n}", 1),
+		},
+		{
+			name: "set creates nested block",
+			edit: func(d *Document) { d.Set("decoration:shadow:range", "4") },
+			want: strings.Replace(content, "blur_size = 3\n}", "blur_size = 3\n    shadow {\n        range = 4\n    }\n}", 1),
+		},
+		{
+			name: "set creates top-level block",
+			edit: func(d *Document) { d.Set("cursor:no_warps", "true") },
+			want: content + "\ncursor {\n    no_warps = true\n}\n",
+		},
+		{
+			name: "set escapes hash",
+			edit: func(d *Document) { d.Set("general:col.active_border", "#fff") },
+			want: strings.Replace(content, "# inner\n}", "# inner\n    col.active_border = ##fff\n}", 1),
+		},
+		{
+			name: "delete",
+			edit: func(d *Document) { d.Delete("decoration:rounding") },
+			want: strings.Replace(content, "    rounding = 10\n", "", 1),
+		},
+		{
+			name: "rename into nested block moves comments",
+			edit: func(d *Document) { d.Rename("decoration:blur_size", "decoration:blur:size") },
+			want: strings.Replace(content, "    # radius of the blur\n    blur_size = 3\n}",
+				"    blur {\n        # radius of the blur\n        size = 3\n    }\n}", 1),
+		},
+		{
+			name: "rename full path in place",
+			edit: func(d *Document) { d.Rename("decoration:blur_passes", "decoration:blur:passes") },
+			want: strings.Replace(content, "decoration:blur_passes = 2", "decoration:blur:passes = 2", 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument("test.conf", content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(doc)
+			if got := doc.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentSetKeepsSpacing(t *testing.T) {
+	content := "general {\n\tgaps_in=5\n\tborder_size  =  2   # thin\n}\n"
+	doc, err := ParseDocument("hyprland.conf", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Set("general:gaps_in", "8")
+	doc.Set("general:border_size", "3")
+
+	want := "general {\n\tgaps_in=8\n\tborder_size  =  3   # thin\n}\n"
+	if got := doc.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDocumentSetRejectsBlocks(t *testing.T) {
+	content := "input {\n    kb_options = grp:alt_shift_toggle\n}\n"
+	for _, path := range []string{"input:kb_options", "input:kb_variant"} {
+		for _, value := range []string{"grp {", "}", "a { b", "a\nb"} {
+			doc, err := ParseDocument("hyprland.conf", content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.Set(path, value); err == nil {
+				t.Errorf("Set(%s, %q) accepted a value the line cannot hold", path, value)
+			}
+			if got := doc.String(); got != content {
+				t.Errorf("Set(%s, %q) changed the document to %q", path, value, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+// RuntimeOptions reads and sets option values in the running compositor
+type RuntimeOptions interface {
+	GetOption(name string) (*ipc.Option, error)
+	Keyword(name, value string) error
+}
+
+// Drift is an option whose runtime value differs from the config file
+type Drift struct {
+	Option  string
+	File    string
+	Runtime string
+	Default bool // the option is not set and File is its default
+}
+
+// DetectDrift compares every schema option with its runtime value. Options
+// not set in the file are compared with their default.
+// Options the running Hyprland does not know are skipped.
+func DetectDrift(cfg *HyprlandConfig, r RuntimeOptions) ([]Drift, error) {
+	var drifts []Drift
+	var firstErr error
+	queried := 0
+
+	for _, opt := range registry {
+		fileValue, err := cfg.GetOption(opt.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		runtimeOpt, err := r.GetOption(opt.Path)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		queried++
+
+		runtime := normalizeRuntimeValue(runtimeOpt.Value())
+		if !sameValue(opt.Type, fileValue, runtime) {
+			drifts = append(drifts, Drift{Option: opt.Path, File: fileValue, Runtime: runtime, Default: !cfg.IsSet(opt.Path)})
+		}
+	}
+
+	// Every single query failing means the compositor is unreachable
+	if queried == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return drifts, nil
+}
+
+// PersistDrift takes over the runtime value into the config and its
+// document, keeping the rest of the file as written. The caller writes doc.
+func PersistDrift(cfg *HyprlandConfig, doc *Document, d Drift) error {
+	if err := cfg.SetOption(d.Option, d.Runtime); err != nil {
+		return err
+	}
+	return doc.Set(d.Option, d.Runtime)
+}
+
+// ResetDrift sets the runtime value back to the value from the file
+func ResetDrift(r RuntimeOptions, d Drift) error {
+	return r.Keyword(d.Option, d.File)
+}
+
+// normalizeRuntimeValue collapses values Hyprland expands, e.g. gaps set to
+// "5" are reported as "5 5 5 5"
+func normalizeRuntimeValue(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return strings.TrimSpace(value)
+	}
+	for _, f := range fields[1:] {
+		if f != fields[0] {
+			return strings.Join(fields, " ")
+		}
+	}
+	return fields[0]
+}
+
+func sameValue(t OptionType, file, runtime string) bool {
+	switch t {
+	case TypeBool:
+		a, okA := parseHyprBool(file)
+		b, okB := parseHyprBool(runtime)
+		return okA && okB && a == b
+	case TypeGaps:
+		a, okA := parseGaps(file)
+		b, okB := parseGaps(runtime)
+		if okA && okB {
+			return a == b
+		}
+	case TypeInt, TypeFloat:
+		a, errA := strconv.ParseFloat(file, 64)
+		b, errB := strconv.ParseFloat(runtime, 64)
+		if errA == nil && errB == nil {
+			return math.Abs(a-b) < 1e-6
+		}
+	}
+	return strings.TrimSpace(file) == strings.TrimSpace(runtime)
+}
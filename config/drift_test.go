@@ -0,0 +1,103 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+func TestDetectDrift(t *testing.T) {
+	server, err := ipc.NewFakeServer(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFakeServer() error = %v", err)
+	}
+	defer server.Close()
+
+	server.Handle("j/getoption", func(name string) string {
+		switch name {
+		case "general:border_size":
+			return `{"option":"general:border_size","int":2,"set":true}`
+		case "general:gaps_in":
+			return `{"option":"general:gaps_in","custom":"8 8 8 8","set":true}`
+		case "general:gaps_out":
+			return `{"option":"general:gaps_out","custom":"10 10 10 10","set":true}`
+		case "general:layout":
+			return `{"option":"general:layout","str":"dwindle","set":true}`
+		case "decoration:blur":
+			return `{"option":"decoration:blur","int":1,"set":true}`
+		case "decoration:active_opacity":
+			return `{"option":"decoration:active_opacity","float":0.95,"set":true}`
+		}
+		return "no such option"
+	})
+	server.Handle("keyword", func(string) string { return "ok" })
+
+	cfg := &HyprlandConfig{}
+	err = parseLine(`general {
+		border_size=2
+		gaps_in=5
+		layout=dwindle
+	}`, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = parseLine(`decoration {
+		blur=true
+		active_opacity=0.95
+		blur_size=3
+	}`, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := server.Client()
+	drifts, err := DetectDrift(cfg, client)
+	if err != nil {
+		t.Fatalf("DetectDrift() error = %v", err)
+	}
+
+	// gaps_out is not set, so its default of 20 is compared
+	want := []Drift{
+		{Option: "general:gaps_in", File: "5", Runtime: "8"},
+		{Option: "general:gaps_out", File: "20", Runtime: "10", Default: true},
+	}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Fatalf("DetectDrift() = %+v, want %+v", drifts, want)
+	}
+
+	doc, err := ParseDocument("hyprland.conf", "# gaps\ngeneral {\n    gaps_in = 5 # inner\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range drifts {
+		if err := PersistDrift(cfg, doc, d); err != nil {
+			t.Fatalf("PersistDrift() error = %v", err)
+		}
+	}
+	if cfg.General.GapIn != "8" {
+		t.Errorf("GapIn = %q after persisting, want 8", cfg.General.GapIn)
+	}
+	wantDoc := "# gaps\ngeneral {\n    gaps_in = 8 # inner\n    gaps_out = 10\n}\n"
+	if got := doc.String(); got != wantDoc {
+		t.Errorf("document after persisting:\n%s\nwant:\n%s", got, wantDoc)
+	}
+
+	if err := ResetDrift(client, want[0]); err != nil {
+		t.Fatalf("ResetDrift() error = %v", err)
+	}
+	requests := server.Requests()
+	if last := requests[len(requests)-1]; last != "keyword general:gaps_in 5" {
+		t.Errorf("ResetDrift() sent %q", last)
+	}
+}
+
+func TestDetectDriftUnreachable(t *testing.T) {
+	cfg := &HyprlandConfig{}
+	cfg.SetOption("general:gaps_in", "5")
+
+	client := &ipc.Client{SocketPath: t.TempDir() + "/missing.sock"}
+	if _, err := DetectDrift(cfg, client); err == nil {
+		t.Error("DetectDrift() succeeded without a compositor")
+	}
+}
@@ -0,0 +1,318 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StatFS is the part of the filesystem the exec checks look at, so tests
+// can run against a fake one
+type StatFS interface {
+	Stat(name string) (fs.FileInfo, error)
+}
+
+type osFS struct{}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+// ExecChecker resolves the programs exec entries run
+type ExecChecker struct {
+	Path     []string // directories of $PATH
+	Home     string
+	DataDirs []string // $XDG_DATA_DIRS, searched for desktop entries
+	FS       StatFS
+}
+
+// NewExecChecker returns a checker for the current environment
+func NewExecChecker() *ExecChecker {
+	home, _ := os.UserHomeDir()
+	dataDirs := filepath.SplitList(os.Getenv("XDG_DATA_DIRS"))
+	if len(dataDirs) == 0 {
+		dataDirs = []string{"/usr/local/share", "/usr/share"}
+	}
+	return &ExecChecker{
+		Path:     filepath.SplitList(os.Getenv("PATH")),
+		Home:     home,
+		DataDirs: dataDirs,
+		FS:       osFS{},
+	}
+}
+
+// shellBuiltins run inside the shell and never need a binary
+var shellBuiltins = map[string]bool{
+	"cd": true, "echo": true, "export": true, "source": true, ".": true, "true": true,
+	"false": true, "test": true, "[": true, "eval": true, "set": true, "unset": true,
+	"read": true, "printf": true, "wait": true, "kill": true, "pwd": true, ":": true,
+	"command": true, "builtin": true, "type": true, "hash": true, "exit": true,
+	"return": true, "break": true, "continue": true, "shift": true, "trap": true,
+	"alias": true, "unalias": true, "local": true, "declare": true, "typeset": true,
+	"readonly": true, "let": true, "ulimit": true, "umask": true, "getopts": true,
+	"jobs": true, "fg": true, "bg": true, "disown": true, "times": true,
+}
+
+// shellReserved are reserved words that may come before the program of a
+// command, as in "if pgrep waybar; then pkill waybar; fi"
+var shellReserved = map[string]bool{
+	"!": true, "{": true, "}": true, "if": true, "then": true, "elif": true, "else": true,
+	"fi": true, "while": true, "until": true, "do": true, "done": true, "esac": true,
+	"time": true,
+}
+
+// shellCompound are reserved words whose command names no program, such as
+// "for f in *.png" or "case $1 in start"
+var shellCompound = map[string]bool{
+	"for": true, "select": true, "case": true, "function": true, "[[": true,
+}
+
+// Check tokenizes command like sh would and reports the first program that
+// cannot be found. Problems are *TokenError values pointing into command.
+func (c *ExecChecker) Check(command string) error {
+	words, err := shellSplit(command)
+	if err != nil {
+		return err
+	}
+
+	var simple []shellWord
+	for i := 0; i <= len(words); i++ {
+		if i < len(words) && !words[i].operator {
+			simple = append(simple, words[i])
+			continue
+		}
+		if err := c.checkSimple(simple); err != nil {
+			return err
+		}
+		simple = nil
+	}
+	return nil
+}
+
+// checkSimple checks a command without operators, e.g. "FOO=1 kitty -e htop"
+func (c *ExecChecker) checkSimple(words []shellWord) error {
+	for len(words) > 0 && shellReserved[words[0].text] {
+		words = words[1:]
+	}
+	if len(words) > 0 && shellCompound[words[0].text] {
+		return nil
+	}
+	words = skipRedirections(words)
+	for len(words) > 0 && isAssignment(words[0].text) {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	program, args := words[0], words[1:]
+	switch name := program.text; {
+	case name == "exec" || name == "nohup" || name == "setsid" || name == "env":
+		return c.checkSimple(args)
+	case name == "sh" || name == "bash" || name == "zsh" || name == "dash":
+		if len(args) >= 2 && args[0].text == "-c" {
+			err := c.Check(args[1].text)
+			if terr, ok := err.(*TokenError); ok {
+				// Inner offsets are only exact without quote removal
+				terr.Offset += args[1].offset + 1
+			}
+			return err
+		}
+	case name == "flatpak":
+		if len(args) > 0 && args[0].text == "run" {
+			return c.checkFlatpak(args[1:], program)
+		}
+	case name == "gtk-launch" || name == "dex":
+		if len(args) > 0 {
+			return c.checkDesktopEntry(args[len(args)-1])
+		}
+	case name == "uwsm" || name == "app2unit":
+		// uwsm app [options] -- command, app2unit [options] -- command
+		for i, a := range args {
+			if a.text == "--" {
+				return c.checkSimple(args[i+1:])
+			}
+		}
+		if name == "uwsm" && len(args) > 0 && args[0].text == "app" {
+			rest := args[1:]
+			for len(rest) > 0 && strings.HasPrefix(rest[0].text, "-") {
+				rest = rest[1:]
+			}
+			if len(rest) > 0 && strings.HasSuffix(rest[0].text, ".desktop") {
+				return c.checkDesktopEntry(rest[0])
+			}
+			return c.checkSimple(rest)
+		}
+	}
+	return c.checkProgram(program)
+}
+
+func (c *ExecChecker) checkProgram(program shellWord) error {
+	name := program.text
+	if shellBuiltins[name] || strings.ContainsAny(name, "$`*?") {
+		// Builtins need no binary, expansions cannot be resolved statically
+		return nil
+	}
+	if strings.HasPrefix(name, "~/") {
+		name = filepath.Join(c.Home, name[2:])
+	}
+	if strings.Contains(name, "/") {
+		if !c.isExecutable(name) {
+			return &TokenError{program.text, program.offset, "program not found or not executable"}
+		}
+		return nil
+	}
+	for _, dir := range c.Path {
+		if dir != "" && c.isExecutable(filepath.Join(dir, name)) {
+			return nil
+		}
+	}
+	return &TokenError{program.text, program.offset, "program not found in $PATH"}
+}
+
+func (c *ExecChecker) isExecutable(path string) bool {
+	info, err := c.FS.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
+
+func (c *ExecChecker) exists(path string) bool {
+	_, err := c.FS.Stat(path)
+	return err == nil
+}
+
+// checkFlatpak checks that the application of "flatpak run [options] ID"
+// is installed system-wide or for the user
+func (c *ExecChecker) checkFlatpak(args []shellWord, flatpak shellWord) error {
+	if err := c.checkProgram(flatpak); err != nil {
+		return err
+	}
+	for _, a := range args {
+		if strings.HasPrefix(a.text, "-") {
+			continue
+		}
+		dirs := []string{"/var/lib/flatpak/app", filepath.Join(c.Home, ".local/share/flatpak/app")}
+		for _, dir := range dirs {
+			if c.exists(filepath.Join(dir, a.text)) {
+				return nil
+			}
+		}
+		return &TokenError{a.text, a.offset, "flatpak application is not installed"}
+	}
+	return nil
+}
+
+// checkDesktopEntry looks for an applications/ID.desktop file in the XDG
+// data directories and the flatpak exports
+func (c *ExecChecker) checkDesktopEntry(id shellWord) error {
+	name := id.text
+	if !strings.HasSuffix(name, ".desktop") {
+		name += ".desktop"
+	}
+	dirs := append([]string{filepath.Join(c.Home, ".local/share")}, c.DataDirs...)
+	dirs = append(dirs, "/var/lib/flatpak/exports/share", filepath.Join(c.Home, ".local/share/flatpak/exports/share"))
+	for _, dir := range dirs {
+		if c.exists(filepath.Join(dir, "applications", name)) {
+			return nil
+		}
+	}
+	return &TokenError{id.text, id.offset, "desktop entry not found"}
+}
+
+// shellWord is a word of a shell command after quote removal
+type shellWord struct {
+	text     string
+	offset   int  // byte offset of the word in the command
+	operator bool // ;, &&, ||, |, &, ( or )
+}
+
+// shellSplit splits a command into words and control operators, handling
+// quotes and backslash escapes the way sh does
+func shellSplit(s string) ([]shellWord, error) {
+	var words []shellWord
+	var cur strings.Builder
+	start, inWord := 0, false
+
+	flush := func() {
+		if inWord {
+			words = append(words, shellWord{text: cur.String(), offset: start})
+			cur.Reset()
+			inWord = false
+		}
+	}
+	begin := func(i int) {
+		if !inWord {
+			start, inWord = i, true
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			flush()
+		case ch == '\\' && i+1 < len(s):
+			begin(i)
+			i++
+			cur.WriteByte(s[i])
+		case ch == '\'' || ch == '"':
+			begin(i)
+			end := i + 1
+			for ; end < len(s) && s[end] != ch; end++ {
+				if ch == '"' && s[end] == '\\' && end+1 < len(s) {
+					end++
+				}
+				cur.WriteByte(s[end])
+			}
+			if end >= len(s) {
+				return nil, &TokenError{s[i:], i, "unterminated quote"}
+			}
+			i = end
+		case ch == '&' && (i > 0 && strings.IndexByte("<>", s[i-1]) >= 0 || i+1 < len(s) && s[i+1] == '>'):
+			// Part of a redirection like 2>&1 or &>
+			begin(i)
+			cur.WriteByte(ch)
+		case strings.IndexByte(";&|()", ch) >= 0:
+			flush()
+			op := string(ch)
+			if i+1 < len(s) && (ch == '&' || ch == '|') && s[i+1] == ch {
+				op += string(ch)
+				i++
+			}
+			words = append(words, shellWord{text: op, offset: i - len(op) + 1, operator: true})
+		default:
+			begin(i)
+			cur.WriteByte(ch)
+		}
+	}
+	flush()
+	return words, nil
+}
+
+// isAssignment reports whether word is a NAME=value environment prefix
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !(r == '_' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// skipRedirections drops redirections like "> /dev/null" or "2>&1"
+func skipRedirections(words []shellWord) []shellWord {
+	var kept []shellWord
+	for i := 0; i < len(words); i++ {
+		w := strings.TrimLeft(words[i].text, "0123456789&")
+		if strings.HasPrefix(w, ">") || strings.HasPrefix(w, "<") {
+			if w == ">" || w == "<" || w == ">>" {
+				i++ // the target is the next word
+			}
+			continue
+		}
+		kept = append(kept, words[i])
+	}
+	return kept
+}
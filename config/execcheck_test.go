@@ -0,0 +1,130 @@
+package config
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// rootFS serves absolute paths from a MapFS
+type rootFS struct{ fstest.MapFS }
+
+func (r rootFS) Stat(name string) (fs.FileInfo, error) {
+	return r.MapFS.Stat(strings.TrimPrefix(name, "/"))
+}
+
+func testExecChecker() *ExecChecker {
+	exe := &fstest.MapFile{Mode: 0755}
+	return &ExecChecker{
+		Path:     []string{"/usr/bin", "/home/me/.local/bin"},
+		Home:     "/home/me",
+		DataDirs: []string{"/usr/share"},
+		FS: rootFS{fstest.MapFS{
+			"usr/bin/kitty":               exe,
+			"usr/bin/waybar":              exe,
+			"usr/bin/flatpak":             exe,
+			"usr/bin/gtk-launch":          exe,
+			"usr/bin/uwsm":                exe,
+			"usr/bin/sh":                  exe,
+			"usr/bin/grim":                exe,
+			"usr/bin/slurp":               exe,
+			"usr/bin/pgrep":               exe,
+			"usr/bin/pkill":               exe,
+			"usr/bin/notes.txt":           &fstest.MapFile{Mode: 0644},
+			"home/me/.local/bin/myscript": exe,
+			"home/me/scripts/bar.sh":      exe,
+			"var/lib/flatpak/app/org.mozilla.firefox":           &fstest.MapFile{Mode: fs.ModeDir | 0755},
+			"usr/share/applications/org.gnome.Nautilus.desktop": &fstest.MapFile{},
+		}},
+	}
+}
+
+func TestExecCheck(t *testing.T) {
+	tests := []struct {
+		command   string
+		wantToken string // "" when the command is fine
+		wantCol   int
+	}{
+		{"kitty", "", 0},
+		{"kitty --title \"a b\" -e htop", "", 0},
+		{"myscript", "", 0},
+		{"~/scripts/bar.sh --top", "", 0},
+		{"GDK_BACKEND=wayland kitty", "", 0},
+		{"env FOO=1 waybar", "", 0},
+		{"waybar > /dev/null 2>&1 &", "", 0},
+		{`grim -g "$(slurp)" - | wl-copy`, "wl-copy", 24},
+		{"sh -c 'kitty; waybar'", "", 0},
+		{"flatpak run org.mozilla.firefox", "", 0},
+		{"flatpak run --branch=stable com.spotify.Client", "com.spotify.Client", 29},
+		{"gtk-launch org.gnome.Nautilus", "", 0},
+		{"uwsm app -- kitty", "", 0},
+		{"uwsm app -- alacritty", "alacritty", 13},
+		{"$TERMINAL", "", 0},
+		{"cd ~ && kitty", "", 0},
+		{"command -v waybar && exit 0; type kitty", "", 0},
+		{"if pgrep waybar; then pkill waybar; else waybar & fi", "", 0},
+		{"while true; do { kitty; } > /dev/null; done", "", 0},
+		{"for f in a b; do kitty $f; done", "", 0},
+		{"[[ -n $WAYLAND_DISPLAY ]] && ! foot", "foot", 32},
+		{"if kitty; then foot; fi", "foot", 16},
+		{"alacritty", "alacritty", 1},
+		{"notes.txt", "notes.txt", 1},
+		{"~/scripts/missing.sh", "~/scripts/missing.sh", 1},
+		{"kitty && foot", "foot", 10},
+		{"sh -c 'kitty; foot'", "foot", 15},
+		{"kitty --title 'unterminated", "'unterminated", 15},
+	}
+
+	checker := testExecChecker()
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			err := checker.Check(tt.command)
+			if tt.wantToken == "" {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			terr, ok := err.(*TokenError)
+			if !ok {
+				t.Fatalf("Check() = %v, want a TokenError", err)
+			}
+			if terr.Token != tt.wantToken || terr.Offset+1 != tt.wantCol {
+				t.Errorf("error at %q column %d, want %q column %d", terr.Token, terr.Offset+1, tt.wantToken, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestLintExec(t *testing.T) {
+	content := `$term = alacritty
+exec-once = waybar
+exec-once = swaync
+bind = SUPER, Return, exec, [float] $term
+bind = SUPER, E, exec, kitty -e yazi
+`
+	doc, err := ParseDocument("hyprland.conf", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type pos struct{ Line, Col int }
+	var got []pos
+	for _, d := range (Linter{Exec: testExecChecker()}).Lint(doc) {
+		if d.Rule == "missing-command" {
+			got = append(got, pos{d.Line, d.Col})
+		}
+	}
+	want := []pos{{3, 13}, {4, 37}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("missing-command at %v, want %v", got, want)
+	}
+
+	// Without a checker the exec entries are not looked at
+	for _, d := range Lint(doc) {
+		if d.Rule == "missing-command" {
+			t.Errorf("default Lint() reported %v", d)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ExportVersion is the version of the JSON export schema. It changes only
+// when a field is removed or changes meaning; new fields may be added
+// without a bump.
+const ExportVersion = 1
+
+// Export is the JSON form of a config tree, documented in EXPORT.md
+type Export struct {
+	Version     int                `json:"version"`
+	Files       []string           `json:"files"`
+	Variables   []ExportVariable   `json:"variables"`
+	Options     []ExportOption     `json:"options"`
+	Monitors    []ExportMonitor    `json:"monitors"`
+	Workspaces  []ExportWorkspace  `json:"workspaces"`
+	Devices     []ExportDevice     `json:"devices"`
+	Binds       []ExportBind       `json:"binds"`
+	WindowRules []ExportWindowRule `json:"window_rules"`
+	Beziers     []ExportBezier     `json:"beziers"`
+	Animations  []ExportAnimation  `json:"animations"`
+	Exec        []ExportExec       `json:"exec"`
+	Keywords    []ExportKeyword    `json:"keywords"`
+}
+
+// Source is where an exported entry was read from. On import it picks the
+// file the entry is written to; an empty or unknown file means the main
+// config file.
+type Source struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+type ExportVariable struct {
+	Name  string `json:"name"` // without the leading $
+	Value string `json:"value"`
+	Source
+}
+
+// ExportOption holds the value of an option with variables expanded
+type ExportOption struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+	Source
+}
+
+type ExportMonitor struct {
+	Name       string `json:"name"`
+	Resolution string `json:"resolution"`
+	Position   string `json:"position"`
+	Scale      string `json:"scale"`
+	Args       string `json:"args"`
+	Source
+}
+
+type ExportWorkspace struct {
+	Name    string `json:"name"`
+	Monitor string `json:"monitor,omitempty"`
+	Source
+}
+
+// ExportDevice is a per-device input block
+type ExportDevice struct {
+	Name    string               `json:"name"`
+	Options []ExportDeviceOption `json:"options"`
+	Source
+}
+
+type ExportDeviceOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"` // as written, may reference variables
+}
+
+type ExportBind struct {
+	Flags       string `json:"flags,omitempty"`
+	Mods        string `json:"mods"`
+	Key         string `json:"key"`
+	Dispatcher  string `json:"dispatcher"`
+	Params      string `json:"params,omitempty"`
+	Description string `json:"description,omitempty"`
+	Submap      string `json:"submap,omitempty"`
+	Source
+}
+
+type ExportWindowRule struct {
+	Version int    `json:"version"` // 1 for windowrule, 2 for windowrulev2
+	Rule    string `json:"rule"`
+	Value   string `json:"value,omitempty"`
+	Target  string `json:"target"`
+	Source
+}
+
+type ExportBezier struct {
+	Name   string     `json:"name"`
+	Points [4]float64 `json:"points"`
+	Source
+}
+
+type ExportAnimation struct {
+	Target  string  `json:"target"`
+	Enabled bool    `json:"enabled"`
+	Speed   float64 `json:"speed,omitempty"` // in deciseconds
+	Bezier  string  `json:"bezier,omitempty"`
+	Style   string  `json:"style,omitempty"`
+	Source
+}
+
+type ExportExec struct {
+	Kind    string `json:"kind"` // exec, exec-once or exec-shutdown
+	Command string `json:"command"`
+	Source
+}
+
+// ExportKeyword is a top-level keyword without an entry type of its own,
+// such as env or layerrule
+type ExportKeyword struct {
+	Name  string `json:"name"`
+	Value string `json:"value"` // as written, may reference variables
+	Source
+}
+
+// ExportConfig converts a config tree to its JSON form. Keyword entries
+// keep the order they are read in; variables and options are sorted.
+func ExportConfig(tree *ConfigTree) *Export {
+	cfg := tree.Config
+	// Empty lists are written as [] rather than null
+	e := &Export{
+		Version:     ExportVersion,
+		Files:       []string{},
+		Variables:   []ExportVariable{},
+		Options:     []ExportOption{},
+		Monitors:    []ExportMonitor{},
+		Workspaces:  []ExportWorkspace{},
+		Devices:     []ExportDevice{},
+		Binds:       []ExportBind{},
+		WindowRules: []ExportWindowRule{},
+		Beziers:     []ExportBezier{},
+		Animations:  []ExportAnimation{},
+		Exec:        []ExportExec{},
+		Keywords:    []ExportKeyword{},
+	}
+	for _, doc := range tree.Documents {
+		e.Files = append(e.Files, doc.Path)
+	}
+
+	names := make([]string, 0, len(cfg.Variables))
+	for name := range cfg.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e.Variables = append(e.Variables, ExportVariable{name, cfg.Variables[name], Source{File: cfg.VariableFile(name)}})
+	}
+
+	for _, path := range exportedOptions(cfg) {
+		value, _ := cfg.GetOption(path)
+		e.Options = append(e.Options, ExportOption{path, value, Source{cfg.OptionFile(path), cfg.OptionLine(path)}})
+	}
+
+	for _, m := range cfg.Monitors {
+		e.Monitors = append(e.Monitors, ExportMonitor{m.Name, m.Resolution, m.Position, m.Scale, m.Args, Source{m.File, m.Line}})
+	}
+	for _, ws := range cfg.Workspaces {
+		e.Workspaces = append(e.Workspaces, ExportWorkspace{ws.Name, ws.Monitor, Source{ws.File, ws.Line}})
+	}
+	for _, dev := range cfg.Devices {
+		d := ExportDevice{dev.Name, []ExportDeviceOption{}, Source{dev.File, dev.Line}}
+		for _, o := range dev.Options {
+			d.Options = append(d.Options, ExportDeviceOption(o))
+		}
+		e.Devices = append(e.Devices, d)
+	}
+	for _, b := range cfg.Binds {
+		e.Binds = append(e.Binds, ExportBind{b.Flags, b.Mods, b.Key, b.Dispatcher, b.Params, b.Description, b.Submap, Source{b.File, b.Line}})
+	}
+	for _, r := range cfg.WindowRules {
+		e.WindowRules = append(e.WindowRules, ExportWindowRule{r.Version, r.Rule, r.Value, r.Target, Source{r.File, r.Line}})
+	}
+	for _, c := range cfg.Animations.Beziers {
+		e.Beziers = append(e.Beziers, ExportBezier{c.Name, c.Points, Source{c.File, c.Line}})
+	}
+	for _, a := range cfg.Animations.Animations {
+		e.Animations = append(e.Animations, ExportAnimation{a.Target, a.Enabled, a.Duration, a.Bezier, a.Style, Source{a.File, a.Line}})
+	}
+	for _, x := range cfg.Exec {
+		e.Exec = append(e.Exec, ExportExec{x.Kind, x.Command, Source{x.File, x.Line}})
+	}
+	for _, k := range cfg.Keywords {
+		e.Keywords = append(e.Keywords, ExportKeyword{k.Name, k.Value, Source{k.File, k.Line}})
+	}
+	return e
+}
+
+// exportedOptions returns the sorted paths of the options set in cfg
+func exportedOptions(cfg *HyprlandConfig) []string {
+	paths := make([]string, 0, len(cfg.set))
+	for path := range cfg.set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// clearSources removes the file attribution of every entry
+func (e *Export) clearSources() {
+	e.Files = []string{}
+	for i := range e.Variables {
+		e.Variables[i].Source = Source{}
+	}
+	for i := range e.Options {
+		e.Options[i].Source = Source{}
+	}
+	for i := range e.Monitors {
+		e.Monitors[i].Source = Source{}
+	}
+	for i := range e.Workspaces {
+		e.Workspaces[i].Source = Source{}
+	}
+	for i := range e.Devices {
+		e.Devices[i].Source = Source{}
+	}
+	for i := range e.Binds {
+		e.Binds[i].Source = Source{}
+	}
+	for i := range e.WindowRules {
+		e.WindowRules[i].Source = Source{}
+	}
+	for i := range e.Beziers {
+		e.Beziers[i].Source = Source{}
+	}
+	for i := range e.Animations {
+		e.Animations[i].Source = Source{}
+	}
+	for i := range e.Exec {
+		e.Exec[i].Source = Source{}
+	}
+	for i := range e.Keywords {
+		e.Keywords[i].Source = Source{}
+	}
+}
+
+// WriteJSON writes the export as indented JSON
+func (e *Export) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+// ReadExport decodes a JSON export and checks its version
+func ReadExport(r io.Reader) (*Export, error) {
+	var e Export
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("invalid export: %w", err)
+	}
+	if e.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d, want %d", e.Version, ExportVersion)
+	}
+	return &e, nil
+}
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const exportMain = `# main config
+$mainMod = SUPER
+$gaps = 5
+monitor = DP-1, 2560x1440@144, 0x0, 1
+
+source = binds.conf
+
+general {
+    gaps_in = $gaps # inner
+    border_size = 2
+}
+
+animations {
+    enabled = yes
+    bezier = ease, 0.05, 0.9, 0.1, 1.05
+    animation = windows, 1, 7, ease
+}
+
+windowrulev2 = float, class:^(pavucontrol)$
+exec-once = waybar
+env = XCURSOR_SIZE,24
+`
+
+const exportBinds = `# Terminal
+bind = $mainMod, Return, exec, kitty
+bind = $mainMod, Q, killactive,
+
+submap = resize
+binde = , right, resizeactive, 10 0
+submap = reset
+`
+
+// writeTree writes the test config files and loads them as a tree
+func writeTree(t *testing.T) *ConfigTree {
+	t.Helper()
+	return loadTestTree(t, map[string]string{"hyprland.conf": exportMain, "binds.conf": exportBinds})
+}
+
+// loadTestTree writes files to a temporary directory and loads the tree
+// of its hyprland.conf
+func loadTestTree(t *testing.T, files map[string]string) *ConfigTree {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tree, err := LoadConfigTree(filepath.Join(dir, "hyprland.conf"))
+	if err != nil {
+		t.Fatalf("LoadConfigTree() error = %v", err)
+	}
+	return tree
+}
+
+func TestLoadConfigTree(t *testing.T) {
+	tree := writeTree(t)
+	if len(tree.Documents) != 2 {
+		t.Fatalf("loaded %d documents, want 2", len(tree.Documents))
+	}
+	main, binds := tree.Documents[0].Path, tree.Documents[1].Path
+
+	cfg := tree.Config
+	if len(cfg.Binds) != 3 {
+		t.Fatalf("got %d binds, want 3", len(cfg.Binds))
+	}
+	if b := cfg.Binds[0]; b.File != binds || b.Line != 2 || b.Description != "Terminal" {
+		t.Errorf("bind 0 = %+v, want line 2 of %s with its description", b, binds)
+	}
+	if b := cfg.Binds[2]; b.Submap != "resize" {
+		t.Errorf("bind 2 submap = %q, want resize", b.Submap)
+	}
+	if cfg.OptionFile("general:gaps_in") != main || cfg.VariableFile("mainMod") != main {
+		t.Errorf("options and variables not attributed to %s", main)
+	}
+	if cfg.General.GapIn != "5" {
+		t.Errorf("GapIn = %q, want the expanded variable 5", cfg.General.GapIn)
+	}
+
+	// A file sourcing itself would loop forever
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hyprland.conf")
+	os.WriteFile(path, []byte("source = ./hyprland.conf\n"), 0644)
+	if _, err := LoadConfigTree(path); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("LoadConfigTree() of a cycle = %v", err)
+	}
+}
+
+func TestExportConfig(t *testing.T) {
+	tree := writeTree(t)
+	e := ExportConfig(tree)
+
+	if e.Version != ExportVersion || len(e.Files) != 2 {
+		t.Errorf("version %d, files %v", e.Version, e.Files)
+	}
+	var paths []string
+	for _, o := range e.Options {
+		paths = append(paths, o.Path+"="+o.Value)
+	}
+	want := "animations:enabled=true general:border_size=2 general:gaps_in=5"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("options = %s, want %s", got, want)
+	}
+	if len(e.Binds) != 3 || e.Binds[1].Mods != "$mainMod" || e.Binds[1].Source != (Source{e.Files[1], 3}) {
+		t.Errorf("binds = %+v", e.Binds)
+	}
+	if len(e.Monitors) != 1 || len(e.WindowRules) != 1 || len(e.Beziers) != 1 || len(e.Animations) != 1 || len(e.Exec) != 1 {
+		t.Errorf("export = %+v", e)
+	}
+	if want := (ExportKeyword{"env", "XCURSOR_SIZE,24", Source{e.Files[0], 21}}); len(e.Keywords) != 1 || e.Keywords[0] != want {
+		t.Errorf("keywords = %+v, want [%+v]", e.Keywords, want)
+	}
+
+	// The JSON form reads back to the same export
+	var buf bytes.Buffer
+	if err := e.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadExport(&buf)
+	if err != nil {
+		t.Fatalf("ReadExport() error = %v", err)
+	}
+	if len(back.Binds) != 3 || back.Binds[2] != e.Binds[2] {
+		t.Errorf("ReadExport() binds = %+v", back.Binds)
+	}
+
+	if _, err := ReadExport(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("ReadExport() accepted an unknown version")
+	}
+}
+
+func TestImportConfigUnchanged(t *testing.T) {
+	tree := writeTree(t)
+	changed, err := ImportConfig(tree, ExportConfig(tree))
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	for _, doc := range changed {
+		t.Errorf("%s changed:\n%s", doc.Path, doc.String())
+	}
+}
+
+func TestImportConfigWithoutSources(t *testing.T) {
+	// Entries without a file stay in the file that already holds them, and new
+	// ones join the file with the last entry of their kind
+	tree := writeTree(t)
+	e := ExportConfig(tree)
+	e.clearSources()
+	e.Binds = append(e.Binds, ExportBind{Mods: "SUPER", Key: "B", Dispatcher: "exec", Params: "firefox"})
+
+	changed, err := ImportConfig(tree, e)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	if len(changed) != 1 || changed[0] != tree.Documents[1] {
+		t.Fatalf("changed %d documents, want only binds.conf", len(changed))
+	}
+	if got := changed[0].String(); !strings.Contains(got, "killactive,\nbind = SUPER, B, exec, firefox\n") {
+		t.Errorf("binds.conf:\n%s", got)
+	}
+}
+
+func TestImportConfigWithoutKeywords(t *testing.T) {
+	// Exports made before keywords were added leave env lines alone
+	tree := writeTree(t)
+	e := ExportConfig(tree)
+	e.Keywords = nil
+	changed, err := ImportConfig(tree, e)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("changed %s, want no changes", changed[0].Path)
+	}
+
+	// An empty list removes them
+	e.Keywords = []ExportKeyword{}
+	changed, err = ImportConfig(tree, e)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	if len(changed) != 1 || strings.Contains(changed[0].String(), "env =") {
+		t.Errorf("env was not removed")
+	}
+}
+
+func TestImportConfigDevices(t *testing.T) {
+	const main = `input {
+    sensitivity = 0
+}
+
+device {
+    name = logitech-mouse
+    sensitivity = -0.5 # slow
+}
+
+# touchpad of the laptop
+device {
+    name = elan-touchpad
+    natural_scroll = true
+    sensitivity = 0.3
+}
+`
+	tree := loadTestTree(t, map[string]string{"hyprland.conf": main})
+	file := tree.Documents[0].Path
+
+	e := ExportConfig(tree)
+	want := []ExportDevice{
+		{"logitech-mouse", []ExportDeviceOption{{"sensitivity", "-0.5"}}, Source{file, 5}},
+		{"elan-touchpad", []ExportDeviceOption{{"natural_scroll", "true"}, {"sensitivity", "0.3"}}, Source{file, 11}},
+	}
+	if !reflect.DeepEqual(e.Devices, want) {
+		t.Errorf("devices = %+v, want %+v", e.Devices, want)
+	}
+	if len(e.Options) != 1 || e.Options[0].Path != "input:sensitivity" {
+		t.Errorf("options = %+v, want only input:sensitivity", e.Options)
+	}
+
+	// Through JSON and back nothing changes
+	var buf bytes.Buffer
+	if err := e.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadExport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := ImportConfig(tree, read)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("unchanged export rewrote the config:\n%s", changed[0].String())
+	}
+
+	// Editing one device leaves the other alone
+	read.Devices[1].Options[1].Value = "0.6"
+	changed, err = ImportConfig(tree, read)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	wantMain := strings.Replace(main, `    natural_scroll = true
+    sensitivity = 0.3`, `    natural_scroll = true
+    sensitivity = 0.6`, 1)
+	if len(changed) != 1 || changed[0].String() != wantMain {
+		t.Errorf("import:\n%s", UnifiedDiff("want", "got", wantMain, tree.Documents[0].String()))
+	}
+}
+
+func TestImportConfig(t *testing.T) {
+	tree := writeTree(t)
+	e := ExportConfig(tree)
+	main, binds := e.Files[0], e.Files[1]
+
+	e.Variables = append(e.Variables, ExportVariable{Name: "term", Value: "foot"})
+	e.Options[1].Value = "3" // general:border_size
+	e.Options = append(e.Options, ExportOption{Path: "decoration:rounding", Value: "8"})
+	e.Binds = e.Binds[1:] // drop the terminal bind and its description
+	e.Binds = append(e.Binds,
+		ExportBind{Mods: "$mainMod", Key: "T", Dispatcher: "exec", Params: "$term", Source: Source{File: binds}},
+		ExportBind{Flags: "e", Key: "left", Dispatcher: "resizeactive", Params: "-10 0", Submap: "resize", Source: Source{File: binds}},
+		ExportBind{Key: "escape", Dispatcher: "submap", Params: "reset", Submap: "move"},
+	)
+	e.WindowRules = nil
+	e.Beziers = append(e.Beziers, ExportBezier{Name: "linear2", Points: [4]float64{0, 0, 1, 1}})
+	e.Keywords = append(e.Keywords, ExportKeyword{Name: "env", Value: "QT_QPA_PLATFORM,wayland"})
+
+	changed, err := ImportConfig(tree, e)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	if len(changed) != 2 {
+		t.Errorf("%d documents changed, want 2", len(changed))
+	}
+
+	wantMain := `# main config
+$mainMod = SUPER
+$gaps = 5
+$term = foot
+monitor = DP-1, 2560x1440@144, 0x0, 1
+
+source = binds.conf
+
+general {
+    gaps_in = $gaps # inner
+    border_size = 3
+}
+
+animations {
+    enabled = yes
+    bezier = ease, 0.05, 0.9, 0.1, 1.05
+    bezier = linear2, 0, 0, 1, 1
+    animation = windows, 1, 7, ease
+}
+
+exec-once = waybar
+env = XCURSOR_SIZE,24
+env = QT_QPA_PLATFORM,wayland
+
+submap = move
+bind = , escape, submap, reset
+submap = reset
+
+decoration {
+    rounding = 8
+}
+`
+	wantBinds := `bind = $mainMod, Q, killactive,
+bind = $mainMod, T, exec, $term
+
+submap = resize
+binde = , right, resizeactive, 10 0
+binde = , left, resizeactive, -10 0
+submap = reset
+`
+	if got := tree.Document(main).String(); got != wantMain {
+		t.Errorf("main config:\n%s", UnifiedDiff("want", "got", wantMain, got))
+	}
+	if got := tree.Document(binds).String(); got != wantBinds {
+		t.Errorf("binds.conf:\n%s", UnifiedDiff("want", "got", wantBinds, got))
+	}
+}
+
+func TestImportConfigValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(e *Export)
+		wantErr string
+	}{
+		{"option value", func(e *Export) { e.Options[0].Value = "maybe" }, "animations:enabled"},
+		{"unknown top-level option", func(e *Export) { e.Options = append(e.Options, ExportOption{Path: "rounding", Value: "1"}) }, "unknown option"},
+		{"bind", func(e *Export) {
+			e.Binds = append(e.Binds, ExportBind{Mods: "SUPER", Key: "nokey", Dispatcher: "exec", Params: "kitty"})
+		}, "unknown key"},
+		{"window rule", func(e *Export) {
+			e.WindowRules = append(e.WindowRules, ExportWindowRule{Version: 2, Rule: "flaot", Target: "class:x"})
+		}, "flaot"},
+		{"workspace", func(e *Export) { e.Workspaces = append(e.Workspaces, ExportWorkspace{Name: "3"}) }, "monitor is required"},
+		{"keyword with a list", func(e *Export) {
+			e.Keywords = append(e.Keywords, ExportKeyword{Name: "bindm", Value: "SUPER, mouse:272, movewindow"})
+		}, "has a list of its own"},
+		{"keyword name", func(e *Export) { e.Keywords = append(e.Keywords, ExportKeyword{Name: "a b", Value: "1"}) }, "invalid keyword name"},
+		{"device name", func(e *Export) { e.Devices = append(e.Devices, ExportDevice{}) }, "name is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := writeTree(t)
+			e := ExportConfig(tree)
+			tt.edit(e)
+			_, err := ImportConfig(tree, e)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
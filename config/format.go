@@ -0,0 +1,266 @@
+package config
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formatter rewrites documents into the canonical layout
+type Formatter struct {
+	// SortBinds orders each run of binds by modifiers and key. Comment lines
+	// above a bind are its description and move with it.
+	SortBinds bool
+}
+
+// Format rewrites doc in place: "key = value" with single spaces, blocks
+// indented by four spaces, at most one blank line in a row and none at the
+// start of a block or the file, comma separated fields of monitor, bezier
+// and animation lines spaced evenly and the fields of consecutive binds
+// aligned. Comments are kept.
+func (f Formatter) Format(doc *Document) {
+	var lines []*Line
+	depth := 0
+	for _, line := range doc.Lines {
+		if line.Kind == LineClose {
+			depth--
+		}
+		indent := strings.Repeat(indentUnit, depth)
+
+		switch line.Kind {
+		case LineBlank:
+			if n := len(lines); n == 0 || lines[n-1].Kind == LineBlank || lines[n-1].Kind == LineOpen {
+				continue
+			}
+			line.Raw = ""
+		case LineComment:
+			line.Raw = indent + strings.TrimSpace(line.Raw)
+		case LineOpen:
+			line.Raw = withComment(indent+line.Key+" {", line.Comment)
+			depth++
+		case LineClose:
+			if n := len(lines); n > 0 && lines[n-1].Kind == LineBlank {
+				lines = lines[:n-1]
+			}
+			line.Raw = withComment(indent+"}", line.Comment)
+		case LineAssign:
+			line.Raw = withComment(strings.TrimRight(indent+line.Key+" = "+formatValue(line), " "), line.Comment)
+		}
+		lines = append(lines, line)
+	}
+	if n := len(lines); n > 0 && lines[n-1].Kind == LineBlank {
+		lines = lines[:n-1]
+	}
+
+	for _, run := range bindRuns(lines) {
+		if f.SortBinds {
+			sortBinds(run, documentVariables(doc))
+		}
+		alignBinds(run)
+	}
+
+	doc.Lines = lines
+	doc.noFinalNewline = false
+	doc.reindex()
+}
+
+func withComment(raw, comment string) string {
+	if comment == "" {
+		return raw
+	}
+	return raw + " # " + comment
+}
+
+// formatValue returns the value of an assignment as it is written, with
+// the fields of comma separated keywords spaced evenly
+func formatValue(line *Line) string {
+	value := strings.ReplaceAll(line.Value, "#", "##")
+	spaced := line.Key == "bezier" || line.Key == "animation" || line.Section == "" && line.Key == "monitor"
+	if !spaced {
+		return value
+	}
+	fields := strings.Split(value, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return strings.Join(fields, ", ")
+}
+
+// isBindLine reports whether line is a top-level bind of any flavour
+func isBindLine(line *Line) bool {
+	return line.Kind == LineAssign && line.Section == "" &&
+		strings.HasPrefix(line.Key, "bind") && isBindFlags(line.Key[4:])
+}
+
+// hasDescription reports whether a bind line carries a description field
+func hasDescription(line *Line) bool {
+	return strings.Contains(line.Key[4:], "d")
+}
+
+// bindFields splits the value of a bind line into its fields. The last
+// field is the dispatcher argument and may hold commas itself.
+func bindFields(line *Line) []string {
+	n := 4
+	if hasDescription(line) {
+		n = 5
+	}
+	fields := strings.SplitN(line.Value, ",", n)
+	for i := range fields {
+		fields[i] = strings.TrimSpace(strings.ReplaceAll(fields[i], "#", "##"))
+	}
+	return fields
+}
+
+// bindUnit is a bind line with the comment lines directly above it
+type bindUnit []*Line
+
+// bindRuns returns the runs of binds that are not separated by blank or
+// other lines. Each run is a slice of lines, so reordering it reorders the
+// lines in place.
+func bindRuns(lines []*Line) [][]*Line {
+	var runs [][]*Line
+	start, end := -1, -1 // first line of the run, line after its last bind
+	for i, line := range lines {
+		switch {
+		case isBindLine(line):
+			if start < 0 {
+				start = i
+			}
+			end = i + 1
+		case line.Kind == LineComment && line.Section == "":
+			if start < 0 {
+				start = i
+			}
+		default:
+			if start >= 0 && end > start {
+				runs = append(runs, lines[start:end])
+			}
+			start, end = -1, -1
+		}
+	}
+	if start >= 0 && end > start {
+		runs = append(runs, lines[start:end])
+	}
+	return runs
+}
+
+// sortBinds orders the binds of a run by modifiers, then key, keeping the
+// order of binds on the same combination
+func sortBinds(run []*Line, vars map[string]string) {
+	var units []bindUnit
+	var pending bindUnit
+	for _, line := range run {
+		pending = append(pending, line)
+		if isBindLine(line) {
+			units = append(units, pending)
+			pending = nil
+		}
+	}
+
+	type sortKey struct{ mods, key string }
+	keys := make(map[*Line]sortKey)
+	for _, u := range units {
+		bind := u[len(u)-1]
+		fields := bindFields(bind)
+		var k sortKey
+		if mods, err := NormalizeMods(fields[0], vars); err == nil {
+			k.mods = strings.Join(mods, "+")
+		} else {
+			k.mods = fields[0]
+		}
+		if len(fields) > 1 {
+			k.key = strings.ToLower((&HyprlandConfig{Variables: vars}).expandVariables(fields[1]))
+		}
+		keys[bind] = k
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		a, b := keys[units[i][len(units[i])-1]], keys[units[j][len(units[j])-1]]
+		if a.mods != b.mods {
+			return a.mods < b.mods
+		}
+		// Number keys sort numerically, so 10 follows 9
+		na, errA := strconv.Atoi(a.key)
+		nb, errB := strconv.Atoi(b.key)
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return a.key < b.key
+	})
+
+	i := 0
+	for _, u := range units {
+		i += copy(run[i:], u)
+	}
+}
+
+// alignBinds pads the keywords and fields of the binds in a run so they
+// line up in columns. Modifiers and keys share their columns; the fields
+// after them only line up with binds of the same layout, as bindd puts a
+// description where other binds have the dispatcher. The last field of
+// each bind is not padded.
+func alignBinds(run []*Line) {
+	keyWidth := 0
+	widths := make(map[bool][]int) // by whether the bind has a description
+	for _, line := range run {
+		if !isBindLine(line) {
+			continue
+		}
+		keyWidth = max(keyWidth, len(line.Key))
+		layout := hasDescription(line)
+		fields := bindFields(line)
+		for i, field := range fields[:len(fields)-1] {
+			if i == len(widths[layout]) {
+				widths[layout] = append(widths[layout], 0)
+			}
+			widths[layout][i] = max(widths[layout][i], len(field)+1)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		shared := 0
+		for _, w := range widths {
+			if i < len(w) {
+				shared = max(shared, w[i])
+			}
+		}
+		for _, w := range widths {
+			if i < len(w) {
+				w[i] = shared
+			}
+		}
+	}
+
+	for _, line := range run {
+		if !isBindLine(line) {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString(line.Key + strings.Repeat(" ", keyWidth-len(line.Key)) + " =")
+		fields := bindFields(line)
+		for i, field := range fields {
+			if i == len(fields)-1 {
+				if field != "" {
+					sb.WriteString(" " + field)
+				}
+				break
+			}
+			cell := field + ","
+			sb.WriteString(" " + cell)
+			// Pad up to the next column unless only empty fields follow
+			if i < len(fields)-2 || fields[len(fields)-1] != "" {
+				sb.WriteString(strings.Repeat(" ", widths[hasDescription(line)][i]-len(cell)))
+			}
+		}
+		line.Raw = withComment(sb.String(), line.Comment)
+	}
+}
+
+// documentVariables returns the top-level variables of doc without the "$"
+func documentVariables(doc *Document) map[string]string {
+	vars := make(map[string]string)
+	for _, line := range doc.Lines {
+		if isVariableLine(line) {
+			vars[strings.TrimPrefix(line.Key, "$")] = line.Value
+		}
+	}
+	return vars
+}
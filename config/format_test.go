@@ -0,0 +1,116 @@
+package config
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	input := `$mainMod=SUPER
+
+
+# Monitors
+monitor=DP-1,2560x1440@144,0x0,1   # main
+
+
+general {
+
+  gaps_in=5
+	border_size =  2 # thin
+        col.active_border = rgba(33ccffee) ## not a comment
+
+	}
+decoration{
+blur {
+enabled=true
+}
+}
+animations {
+    bezier=ease,0.05,0.9,0.1,1.05
+}
+
+# Terminal
+bind=$mainMod,Return,exec,kitty
+bind = $mainMod SHIFT, Q, killactive,
+binde=,right,resizeactive,10 0
+bind = $mainMod, E, exec, notify-send "a, b"
+bindd=$mainMod,B,Open browser,exec,firefox
+
+bindm = $mainMod, mouse:272, movewindow
+
+`
+	want := `$mainMod = SUPER
+
+# Monitors
+monitor = DP-1, 2560x1440@144, 0x0, 1 # main
+
+general {
+    gaps_in = 5
+    border_size = 2 # thin
+    col.active_border = rgba(33ccffee) ## not a comment
+}
+decoration {
+    blur {
+        enabled = true
+    }
+}
+animations {
+    bezier = ease, 0.05, 0.9, 0.1, 1.05
+}
+
+# Terminal
+bind  = $mainMod,       Return, exec,         kitty
+bind  = $mainMod SHIFT, Q,      killactive,
+binde = ,               right,  resizeactive, 10 0
+bind  = $mainMod,       E,      exec,         notify-send "a, b"
+bindd = $mainMod,       B,      Open browser, exec, firefox
+
+bindm = $mainMod, mouse:272, movewindow
+`
+	doc, err := ParseDocument("hyprland.conf", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Formatter{}.Format(doc)
+	if got := doc.String(); got != want {
+		t.Errorf("Format():\n%s", UnifiedDiff("want", "got", want, got))
+	}
+
+	// Formatting is stable
+	Formatter{}.Format(doc)
+	if got := doc.String(); got != want {
+		t.Errorf("second Format():\n%s", UnifiedDiff("want", "got", want, got))
+	}
+}
+
+func TestFormatSortBinds(t *testing.T) {
+	input := `$mod = SUPER
+bind = $mod SHIFT, 1, movetoworkspace, 1
+# Terminal
+bind = $mod, Return, exec, kitty
+bind = $mod, 10, workspace, 10
+bind = $mod, 2, workspace, 2
+
+submap = resize
+binde = , right, resizeactive, 10 0
+bind = , escape, submap, reset
+submap = reset
+`
+	want := `$mod = SUPER
+bind = $mod,       2,      workspace,       2
+bind = $mod,       10,     workspace,       10
+# Terminal
+bind = $mod,       Return, exec,            kitty
+bind = $mod SHIFT, 1,      movetoworkspace, 1
+
+submap = resize
+bind  = , escape, submap,       reset
+binde = , right,  resizeactive, 10 0
+submap = reset
+`
+	doc, err := ParseDocument("hyprland.conf", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Formatter{SortBinds: true}.Format(doc)
+	if got := doc.String(); got != want {
+		t.Errorf("Format():\n%s", UnifiedDiff("want", "got", want, got))
+	}
+}
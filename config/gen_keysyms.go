@@ -0,0 +1,84 @@
+//go:build ignore
+
+// gen_keysyms writes keysyms.go from the keysym headers of libxkbcommon
+// (xkbcommon/xkbcommon-keysyms.h) or X11 (X11/keysymdef.h, XF86keysym.h,
+// ...). Run it with go generate.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// define matches a keysym definition and captures the vendor prefix and the
+// name, e.g. XKB_KEY_eacute, XK_eacute or XF86XK_AudioMute
+var define = regexp.MustCompile(`^#define\s+(?:XKB_KEY_|(\w*)XK_)(\w+)\s+\S`)
+
+func main() {
+	out := flag.String("o", "keysyms.go", "output file")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: go run gen_keysyms.go [-o FILE] HEADER...")
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			m := define.FindStringSubmatch(scanner.Text())
+			if m == nil {
+				continue
+			}
+			// The vendor prefix is part of the xkb name: XF86XK_AudioMute
+			// is XF86AudioMute, SunXK_Copy is SunCopy
+			name := m[1] + m[2]
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_keysyms.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package config\n\n")
+	buf.WriteString("// xkbKeysyms are the keysym names xkbcommon knows, in header order\n")
+	buf.WriteString("var xkbKeysyms = []string{\n")
+	line := ""
+	for _, name := range names {
+		entry := fmt.Sprintf("%q,", name)
+		if line != "" && len(line)+len(entry)+1 > 76 {
+			buf.WriteString("\t" + line + "\n")
+			line = ""
+		}
+		line = strings.TrimSpace(line + " " + entry)
+	}
+	if line != "" {
+		buf.WriteString("\t" + line + "\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,682 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImportConfig edits the documents of tree so that reading them again
+// yields the model in e. The export describes the whole config: entries
+// missing from it are removed. Unchanged entries keep their lines,
+// comments and formatting, new ones are added next to entries of the same
+// kind, and only new or changed values are validated. It returns the
+// documents that changed.
+func ImportConfig(tree *ConfigTree, e *Export) ([]*Document, error) {
+	im := &importer{
+		tree:    tree,
+		vars:    make(map[string]string),
+		plans:   make(map[*Document]*importPlan),
+		others:  e.Keywords != nil,
+		devices: e.Devices != nil,
+	}
+	for _, v := range e.Variables {
+		im.vars[v.Name] = v.Value
+	}
+	im.locate()
+
+	before := make([]string, len(tree.Documents))
+	for i, doc := range tree.Documents {
+		before[i] = doc.String()
+		im.plans[doc] = &importPlan{
+			drop:    make(map[int]bool),
+			replace: make(map[int]string),
+			after:   make(map[int][]string),
+		}
+	}
+
+	im.variables(e.Variables)
+	if err := im.options(e.Options); err != nil {
+		return nil, err
+	}
+	if err := im.keywords(e); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range tree.Documents {
+		im.plans[doc].apply(doc)
+	}
+	for _, fn := range im.later {
+		if err := fn(); err != nil {
+			return nil, err
+		}
+	}
+
+	var changed []*Document
+	for i, doc := range tree.Documents {
+		if doc.String() != before[i] {
+			changed = append(changed, doc)
+		}
+	}
+	return changed, nil
+}
+
+// importPlan collects the edits to a document by line index, so they can
+// all be made in one pass without invalidating the indexes
+type importPlan struct {
+	drop    map[int]bool
+	replace map[int]string
+	after   map[int][]string // -1 inserts before the first line
+	tail    []string
+}
+
+func (p *importPlan) apply(doc *Document) {
+	if len(p.drop) == 0 && len(p.replace) == 0 && len(p.after) == 0 && len(p.tail) == 0 {
+		return
+	}
+	added := func(raw []string) []*Line {
+		lines := make([]*Line, len(raw))
+		for i, text := range raw {
+			lines[i] = &Line{Raw: text}
+		}
+		return lines
+	}
+
+	lines := added(p.after[-1])
+	for i, line := range doc.Lines {
+		if !p.drop[i] {
+			if raw, ok := p.replace[i]; ok {
+				line.Raw = raw
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, added(p.after[i])...)
+	}
+	if len(p.tail) > 0 {
+		if n := len(lines); n > 0 && strings.TrimSpace(lines[n-1].Raw) != "" {
+			lines = append(lines, &Line{})
+		}
+		lines = append(lines, added(p.tail)...)
+	}
+	doc.Lines = lines
+	doc.reindex()
+}
+
+type importer struct {
+	tree  *ConfigTree
+	vars  map[string]string
+	plans map[*Document]*importPlan
+	later []func() error // edits that go through Document.Set and insert
+
+	// others and devices are false for exports made before the keywords
+	// and devices lists were added; those lines are left alone
+	others  bool
+	devices bool
+
+	// located holds the document that already contains an entry, by
+	// "$name" for variables, path for options and key() for keywords, and
+	// the last document with a keyword of a family by "\x00"+family
+	located map[string]*Document
+}
+
+// locate records where the entries of the tree are, so entries without a
+// file stay where they are
+func (im *importer) locate() {
+	im.located = make(map[string]*Document)
+	for _, doc := range im.tree.Documents {
+		for _, line := range doc.Lines {
+			switch {
+			case isVariableLine(line):
+				im.located[line.Key] = doc
+			case isOptionLine(line):
+				im.located[line.Path()] = doc
+			}
+		}
+		for _, k := range documentKeywords(doc, im.vars) {
+			if _, ok := im.located[k.key()]; !ok {
+				im.located[k.key()] = doc
+			}
+			im.located["\x00"+k.family] = doc
+		}
+	}
+}
+
+// target returns the document an entry is written to: the file it names,
+// else the first file found under keys, else the main file
+func (im *importer) target(file string, keys ...string) *Document {
+	if doc := im.tree.Document(file); doc != nil {
+		return doc
+	}
+	for _, key := range keys {
+		if doc, ok := im.located[key]; ok {
+			return doc
+		}
+	}
+	return im.tree.Documents[0]
+}
+
+func (im *importer) expand(value string) string {
+	return (&HyprlandConfig{Variables: im.vars}).expandVariables(value)
+}
+
+// variables updates $name definitions, adding new ones after the last
+// definition of their file or at its top
+func (im *importer) variables(vars []ExportVariable) {
+	type wanted struct {
+		value string
+		doc   *Document
+		found bool
+	}
+	want := make(map[string]*wanted)
+	for _, v := range vars {
+		want[v.Name] = &wanted{value: v.Value, doc: im.target(v.File, "$"+v.Name)}
+	}
+
+	for _, doc := range im.tree.Documents {
+		plan := im.plans[doc]
+		last, lastVar := make(map[string]int), -1
+		for i, line := range doc.Lines {
+			if isVariableLine(line) {
+				last[line.Key[1:]], lastVar = i, i
+			}
+		}
+		for i, line := range doc.Lines {
+			if !isVariableLine(line) {
+				continue
+			}
+			name := line.Key[1:]
+			w := want[name]
+			switch {
+			case w == nil || w.doc != doc:
+				plan.drop[i] = true
+			case i == last[name]:
+				w.found = true
+				if line.Value != w.value {
+					plan.replace[i] = replaceValue(line, w.value)
+				}
+			}
+		}
+		for _, v := range vars {
+			if w := want[v.Name]; w.doc == doc && !w.found {
+				w.found = true
+				plan.after[lastVar] = append(plan.after[lastVar], assignmentRaw("", "$"+v.Name, v.Value, ""))
+			}
+		}
+	}
+}
+
+// options updates option values, comparing them with variables expanded
+// so "$gaps" is kept when it still resolves to the exported value
+func (im *importer) options(opts []ExportOption) error {
+	type wanted struct {
+		value string
+		doc   *Document
+		found bool
+	}
+	want := make(map[string]*wanted)
+	for _, o := range opts {
+		if _, known := Lookup(o.Path); !known && !strings.Contains(o.Path, ":") {
+			return fmt.Errorf("options: unknown option: %s", o.Path)
+		}
+		want[o.Path] = &wanted{value: o.Value, doc: im.target(o.File, o.Path)}
+	}
+	check := &HyprlandConfig{Variables: im.vars}
+
+	for _, doc := range im.tree.Documents {
+		plan := im.plans[doc]
+		last := make(map[string]int)
+		for i, line := range doc.Lines {
+			if isOptionLine(line) {
+				last[line.Path()] = i
+			}
+		}
+		for i, line := range doc.Lines {
+			if !isOptionLine(line) {
+				continue
+			}
+			path := line.Path()
+			w := want[path]
+			switch {
+			case w == nil || w.doc != doc:
+				plan.drop[i] = true
+			case i == last[path]:
+				w.found = true
+				if !sameOptionValue(path, im.expand(line.Value), w.value) {
+					if err := checkImportedOption(check, path, w.value); err != nil {
+						return err
+					}
+					plan.replace[i] = replaceValue(line, w.value)
+				}
+			}
+		}
+	}
+
+	for _, o := range opts {
+		w := want[o.Path]
+		if w.found {
+			continue
+		}
+		if err := checkImportedOption(check, o.Path, w.value); err != nil {
+			return err
+		}
+		w.found = true
+		doc, path, value := w.doc, o.Path, w.value
+		im.later = append(im.later, func() error { return doc.Set(path, value) })
+	}
+	return nil
+}
+
+func checkImportedOption(check *HyprlandConfig, path, value string) error {
+	if err := checkValue(value); err != nil {
+		return fmt.Errorf("options: %s: %w", path, err)
+	}
+	if _, known := Lookup(path); !known {
+		return nil
+	}
+	if err := check.CheckOption(path, value); err != nil {
+		return fmt.Errorf("options: %w", err)
+	}
+	return nil
+}
+
+func sameOptionValue(path, a, b string) bool {
+	if opt, ok := Lookup(path); ok {
+		return a == b || sameValue(opt.Type, a, b)
+	}
+	return a == b
+}
+
+// keyword is a line holding a list entry such as a bind or monitor
+type keyword struct {
+	family string // kind of entry; binds are grouped per submap
+	id     string // identity, equal for lines that decode the same
+	raw    []string
+	index  int // line index, for lines of a document
+	span   int // lines above index that belong to the entry, e.g. a bind description
+
+	// check validates an exported entry before it is written
+	check func() error
+}
+
+func (k *keyword) key() string {
+	return k.family + "\x00" + k.id
+}
+
+// keywords reconciles monitors, workspaces, devices, binds, window rules, beziers,
+// animations, exec entries and other keywords
+func (im *importer) keywords(e *Export) error {
+	wanted := make(map[*Document][]*keyword)
+	add := func(file string, k *keyword) {
+		doc := im.target(file, k.key(), "\x00"+k.family)
+		wanted[doc] = append(wanted[doc], k)
+	}
+
+	for _, m := range e.Monitors {
+		k := monitorKeyword(Monitor{Name: m.Name, Resolution: m.Resolution, Position: m.Position, Scale: m.Scale, Args: m.Args})
+		k.check = required("name", m.Name)
+		add(m.File, k)
+	}
+	for _, ws := range e.Workspaces {
+		k := workspaceKeyword(Workspace{Name: ws.Name, Monitor: ws.Monitor})
+		k.check = required("monitor", ws.Monitor)
+		add(ws.File, k)
+	}
+	for _, dv := range e.Devices {
+		dev := Device{Name: dv.Name}
+		for _, o := range dv.Options {
+			dev.Options = append(dev.Options, DeviceOption(o))
+		}
+		k := deviceKeyword(dev)
+		k.check = func() error { return checkDevice(dev) }
+		add(dv.File, k)
+	}
+	for _, b := range e.Binds {
+		add(b.File, bindKeyword(Bind{Mods: b.Mods, Key: b.Key, Dispatcher: b.Dispatcher, Params: b.Params,
+			Flags: b.Flags, Description: b.Description, Submap: b.Submap}))
+	}
+	for _, r := range e.WindowRules {
+		add(r.File, ruleKeyword(WindowRule{Rule: r.Rule, Value: r.Value, Target: r.Target, Version: r.Version}))
+	}
+	for _, c := range e.Beziers {
+		add(c.File, bezierKeyword(BezierCurve{Name: c.Name, Points: c.Points}))
+	}
+	for _, a := range e.Animations {
+		add(a.File, animationKeyword(Animation{Target: a.Target, Enabled: a.Enabled, Duration: a.Speed, Bezier: a.Bezier, Style: a.Style}))
+	}
+	for _, x := range e.Exec {
+		k := execKeyword(ExecCommand{Kind: x.Kind, Command: x.Command})
+		k.check = func() error {
+			return ValidateEnum("kind", x.Kind, []string{"exec", "exec-once", "exec-shutdown"})
+		}
+		add(x.File, k)
+	}
+	for _, kw := range e.Keywords {
+		k := otherKeyword(Keyword{Name: kw.Name, Value: kw.Value})
+		k.check = func() error { return checkKeywordName(kw.Name) }
+		add(kw.File, k)
+	}
+
+	for _, doc := range im.tree.Documents {
+		if err := im.reconcile(doc, wanted[doc]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func required(field, value string) func() error {
+	return func() error {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%s is required", field)
+		}
+		return nil
+	}
+}
+
+// reconcile keeps the keyword lines of doc that match a wanted entry,
+// drops the others and adds the wanted entries that are missing
+func (im *importer) reconcile(doc *Document, wanted []*keyword) error {
+	plan := im.plans[doc]
+	existing := documentKeywords(doc, im.vars)
+
+	keep := make(map[string]int)
+	for _, k := range wanted {
+		keep[k.key()]++
+	}
+	anchor := make(map[string]int) // last line index per family
+	available := make(map[string]int)
+	for _, k := range existing {
+		anchor[k.family] = k.index
+		if !im.others && strings.HasPrefix(k.family, "keyword:") || !im.devices && k.family == "device" {
+			continue
+		}
+		if keep[k.key()] > 0 {
+			keep[k.key()]--
+			available[k.key()]++
+			continue
+		}
+		for i := k.index - k.span; i <= k.index; i++ {
+			plan.drop[i] = true
+		}
+	}
+
+	var submaps []string
+	blocks := make(map[string][]string)
+	for _, k := range wanted {
+		if available[k.key()] > 0 {
+			available[k.key()]--
+			continue
+		}
+		if err := im.validate(k); err != nil {
+			return err
+		}
+
+		if at, ok := anchor[k.family]; ok {
+			indent := leadingSpace(doc.Lines[at].Raw)
+			for _, raw := range k.raw {
+				plan.after[at] = append(plan.after[at], indent+raw)
+			}
+			continue
+		}
+		switch submap, isBind := strings.CutPrefix(k.family, "bind:"); {
+		case isBind && submap != "":
+			if _, ok := blocks[submap]; !ok {
+				submaps = append(submaps, submap)
+			}
+			blocks[submap] = append(blocks[submap], k.raw...)
+		case k.family == "bezier" || k.family == "animation":
+			family := k.family
+			_, value, _ := strings.Cut(k.raw[0], " = ")
+			im.later = append(im.later, func() error { return doc.insert("animations:"+family, value, "", nil) })
+		default:
+			plan.tail = append(plan.tail, k.raw...)
+		}
+	}
+	for i, submap := range submaps {
+		if i > 0 || len(plan.tail) > 0 {
+			plan.tail = append(plan.tail, "")
+		}
+		plan.tail = append(plan.tail, "submap = "+submap)
+		plan.tail = append(plan.tail, blocks[submap]...)
+		plan.tail = append(plan.tail, "submap = reset")
+	}
+	return nil
+}
+
+// validate checks an entry that is about to be written
+func (im *importer) validate(k *keyword) error {
+	if k.family == "device" && k.check != nil {
+		if err := k.check(); err != nil {
+			return fmt.Errorf("devices: %w", err)
+		}
+		return nil
+	}
+	line := k.raw[len(k.raw)-1]
+	key, value, _ := strings.Cut(line, "=")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if k.check != nil {
+		if err := k.check(); err != nil {
+			return fmt.Errorf("%s: %w", line, err)
+		}
+	}
+	if err := checkValue(value); err != nil {
+		return fmt.Errorf("%s: %w", line, err)
+	}
+
+	switch {
+	case strings.HasPrefix(k.family, "bind:"):
+		cfg := &HyprlandConfig{}
+		if err := parseKeybind(key[4:], value, "", cfg); err != nil {
+			return err
+		}
+		if err := ValidateBind(cfg.Binds[0], im.vars); err != nil {
+			return fmt.Errorf("%s: %w", line, err)
+		}
+	case k.family == "windowrule":
+		if err := ValidateWindowRule(key, value); err != nil {
+			return fmt.Errorf("%s: %w", line, err)
+		}
+	}
+	return nil
+}
+
+// documentKeywords decodes the keyword lines of doc one by one. Lines that
+// do not decode are left alone by the import.
+func documentKeywords(doc *Document, vars map[string]string) []*keyword {
+	cfg := &HyprlandConfig{Variables: vars}
+	d := &decoder{config: cfg}
+	var found []*keyword
+	open := 0 // line index of the device block being read
+	for i, line := range doc.Lines {
+		switch {
+		case line.Kind == LineOpen || line.Kind == LineClose:
+			inside := d.device
+			d.block(line)
+			switch {
+			case d.device && !inside:
+				open = i
+			case inside && !d.device:
+				// The whole block is one entry, ending at its closing line
+				k := deviceKeyword(cfg.Devices[len(cfg.Devices)-1])
+				k.index, k.span = i, i-open
+				found = append(found, k)
+			}
+		case line.Kind == LineAssign && d.device:
+			d.assignment(line)
+		case line.Kind == LineAssign && isKeywordLine(line):
+			if k := decodeKeyword(d, line); k != nil {
+				k.index = i
+				found = append(found, k)
+			}
+		}
+		d.previous = line
+	}
+	return found
+}
+
+// decodeKeyword decodes line into the config of d and returns the entry
+// it added, or nil
+func decodeKeyword(d *decoder, line *Line) *keyword {
+	cfg := d.config
+	n := keywordCounts(cfg)
+	if err := d.assignment(line); err != nil {
+		return nil
+	}
+
+	switch {
+	case len(cfg.Monitors) > n[0]:
+		return monitorKeyword(cfg.Monitors[n[0]])
+	case len(cfg.Workspaces) > n[1]:
+		return workspaceKeyword(cfg.Workspaces[n[1]])
+	case len(cfg.Binds) > n[2]:
+		b := cfg.Binds[n[2]]
+		k := bindKeyword(b)
+		if b.Description != "" && !strings.Contains(b.Flags, "d") {
+			k.span = 1
+		}
+		return k
+	case len(cfg.WindowRules) > n[3]:
+		return ruleKeyword(cfg.WindowRules[n[3]])
+	case len(cfg.Animations.Beziers) > n[4]:
+		return bezierKeyword(cfg.Animations.Beziers[n[4]])
+	case len(cfg.Animations.Animations) > n[5]:
+		return animationKeyword(cfg.Animations.Animations[n[5]])
+	case len(cfg.Exec) > n[6]:
+		return execKeyword(cfg.Exec[n[6]])
+	case len(cfg.Keywords) > n[7]:
+		return otherKeyword(cfg.Keywords[n[7]])
+	}
+	return nil // submap and source lines
+}
+
+func keywordCounts(cfg *HyprlandConfig) [8]int {
+	return [8]int{len(cfg.Monitors), len(cfg.Workspaces), len(cfg.Binds), len(cfg.WindowRules),
+		len(cfg.Animations.Beziers), len(cfg.Animations.Animations), len(cfg.Exec), len(cfg.Keywords)}
+}
+
+func monitorKeyword(m Monitor) *keyword {
+	value := strings.Join(m.Fields(), ", ")
+	return &keyword{family: "monitor", id: value, raw: []string{"monitor = " + value}}
+}
+
+func workspaceKeyword(ws Workspace) *keyword {
+	value := ws.Name + ", monitor:" + ws.Monitor
+	return &keyword{family: "workspace", id: value, raw: []string{"workspace = " + value}}
+}
+
+// deviceKeyword is a device block, written in the current "device {" form
+func deviceKeyword(dev Device) *keyword {
+	raw := []string{"device {", assignmentRaw(indentUnit, "name", dev.Name, "")}
+	for _, o := range dev.Options {
+		raw = append(raw, assignmentRaw(indentUnit, o.Name, o.Value, ""))
+	}
+	raw = append(raw, "}")
+	return &keyword{family: "device", id: strings.Join(raw, "\n"), raw: raw}
+}
+
+// checkDevice rejects device blocks that would not read back the same
+func checkDevice(dev Device) error {
+	if err := required("name", dev.Name)(); err != nil {
+		return err
+	}
+	if err := checkValue(dev.Name); err != nil {
+		return err
+	}
+	for _, o := range dev.Options {
+		if o.Name == "" || o.Name == "name" || strings.ContainsAny(o.Name, "=:{}# \t") {
+			return fmt.Errorf("invalid device option name %q", o.Name)
+		}
+		if err := checkValue(o.Value); err != nil {
+			return fmt.Errorf("%s: %w", o.Name, err)
+		}
+	}
+	return nil
+}
+
+func bindKeyword(b Bind) *keyword {
+	var sb strings.Builder
+	writeBind(&sb, b)
+	raw := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	return &keyword{family: "bind:" + b.Submap, id: strings.Join(raw, "\n"), raw: raw}
+}
+
+func ruleKeyword(r WindowRule) *keyword {
+	value := r.Keyword() + " = " + r.Text()
+	return &keyword{family: "windowrule", id: value, raw: []string{value}}
+}
+
+func bezierKeyword(c BezierCurve) *keyword {
+	fields := []string{c.Name}
+	for _, p := range c.Points {
+		fields = append(fields, strconv.FormatFloat(p, 'f', -1, 64))
+	}
+	value := "bezier = " + strings.Join(fields, ", ")
+	return &keyword{family: "bezier", id: value, raw: []string{value}}
+}
+
+func animationKeyword(a Animation) *keyword {
+	value := "animation = " + formatAnimation(a)
+	return &keyword{family: "animation", id: value, raw: []string{value}}
+}
+
+func execKeyword(x ExecCommand) *keyword {
+	value := x.Kind + " = " + x.Command
+	return &keyword{family: "exec", id: value, raw: []string{value}}
+}
+
+// otherKeyword is an env, layerrule or other keyword line; each name is a
+// family of its own
+func otherKeyword(k Keyword) *keyword {
+	value := k.Name + " = " + k.Value
+	return &keyword{family: "keyword:" + k.Name, id: value, raw: []string{value}}
+}
+
+// checkKeywordName rejects names that would not read back as the same
+// top-level keyword
+func checkKeywordName(name string) error {
+	switch {
+	case name == "" || strings.ContainsAny(name, "=:{}# \t"):
+		return fmt.Errorf("invalid keyword name %q", name)
+	case strings.HasPrefix(name, "$"):
+		return fmt.Errorf("keyword %s: variables go in the variables list", name)
+	}
+	if modelledKeyword(name) {
+		return fmt.Errorf("keyword %s has a list of its own", name)
+	}
+	return nil
+}
+
+// isKeywordLine reports whether line holds a list entry or a keyword that
+// steers them, as opposed to an option
+func isKeywordLine(line *Line) bool {
+	switch line.Key {
+	case "bezier", "animation":
+		return true
+	}
+	// Options always have a category, other top-level keys are keywords
+	return line.Section == "" && !strings.HasPrefix(line.Key, "$") && !strings.Contains(line.Key, ":")
+}
+
+// modelledKeyword reports whether the keyword name has an entry type of
+// its own rather than being kept as a Keyword
+func modelledKeyword(name string) bool {
+	switch name {
+	case "monitor", "workspace", "windowrule", "windowrulev2", "exec", "exec-once", "exec-shutdown",
+		"submap", "source", "bezier", "animation":
+		return true
+	}
+	return strings.HasPrefix(name, "bind") && isBindFlags(name[4:])
+}
+
+func isVariableLine(line *Line) bool {
+	return line.Kind == LineAssign && line.Section == "" && strings.HasPrefix(line.Key, "$")
+}
+
+// isOptionLine reports whether line assigns an option the export covers
+func isOptionLine(line *Line) bool {
+	if line.Kind != LineAssign || isKeywordLine(line) || isVariableLine(line) || inDeviceBlock(line) {
+		return false
+	}
+	_, known := Lookup(line.Path())
+	return known || strings.Contains(line.Path(), ":")
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run gen_keysyms.go -o keysyms.go /usr/include/xkbcommon/xkbcommon-keysyms.h
+
+// scrollKeys are the pseudo keys Hyprland uses for mouse wheel binds
+var scrollKeys = []string{"mouse_up", "mouse_down", "mouse_left", "mouse_right"}
+
+// keysyms indexes xkbKeysyms by lower-case name. Hyprland matches key names
+// case-insensitively, and like xkbcommon prefers the lower-case keysym when
+// names differ only in case, so "Eacute" binds eacute.
+var keysyms = make(map[string]string)
+
+func init() {
+	for _, name := range xkbKeysyms {
+		lower := strings.ToLower(name)
+		// Lower-case letters sort after upper-case ones
+		if prev, ok := keysyms[lower]; !ok || name > prev {
+			keysyms[lower] = name
+		}
+	}
+	for _, name := range scrollKeys {
+		keysyms[name] = name
+	}
+	// Inside a submap, catchall matches every key
+	keysyms["catchall"] = "catchall"
+}
+
+// CanonicalKey returns the canonical spelling of a bind key, e.g. "return"
+// becomes "Return". Keycodes, mouse buttons and switches are returned as-is.
+func CanonicalKey(key string) (string, bool) {
+	if name, ok := keysyms[strings.ToLower(key)]; ok {
+		return name, true
+	}
+	if checkSpecialKey(key) == "" && strings.Contains(key, ":") {
+		return key, true
+	}
+	return "", false
+}
+
+// checkSpecialKey validates the code:, mouse: and switch: key syntaxes and
+// returns a message describing the problem, or "" if the key is fine or
+// does not use one of them
+func checkSpecialKey(key string) string {
+	prefix, arg, ok := strings.Cut(key, ":")
+	if !ok {
+		return ""
+	}
+	switch strings.ToLower(prefix) {
+	case "code":
+		// xkb keycodes are evdev codes offset by 8
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 8 || n > 255 {
+			return "keycode must be a number between 8 and 255"
+		}
+	case "mouse":
+		// evdev button codes, BTN_MISC (256) up to the last BTN_TRIGGER_HAPPY
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 256 || n > 767 {
+			return "mouse button must be an evdev code like 272 (left) or 273 (right)"
+		}
+	case "switch":
+		// switch:NAME, switch:on:NAME or switch:off:NAME
+		name := arg
+		if state, rest, ok := strings.Cut(arg, ":"); ok && (state == "on" || state == "off") {
+			name = rest
+		}
+		if strings.TrimSpace(name) == "" {
+			return "switch needs a device name, e.g. switch:on:Lid Switch"
+		}
+	default:
+		return fmt.Sprintf("unknown key prefix %q, expected code:, mouse: or switch:", prefix)
+	}
+	return ""
+}
+
+// suggestKey returns the known key name closest to a misspelled one, or ""
+// if nothing is close enough
+func suggestKey(key string) string {
+	lower := strings.ToLower(key)
+	best, bestDist := "", len(lower)/3+1
+	for candidate, name := range keysyms {
+		if d := levenshtein(lower, candidate); d < bestDist || (d == bestDist && best != "" && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,404 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Diagnostic is a problem found by Lint, located in a file
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Col      int      `json:"col"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s [%s] %s", d.File, d.Line, d.Col, d.Severity, d.Rule, d.Message)
+}
+
+// MarshalText lets severities appear as words in JSON
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// LintRule describes a rule Lint can report
+type LintRule struct {
+	ID          string
+	Severity    Severity
+	Description string
+}
+
+var lintRules = []LintRule{
+	{"parse-error", SeverityError, "The file cannot be parsed"},
+	{"invalid-value", SeverityError, "Option value does not match its type, range or allowed values"},
+	{"unknown-option", SeverityWarning, "Option is not known, but closely matches one that is"},
+	{"deprecated-option", SeverityWarning, "Option was removed or renamed by a newer Hyprland release"},
+	{"invalid-bind", SeverityError, "Bind has an unknown modifier, key or dispatcher, or bad arguments"},
+	{"duplicate-bind", SeverityWarning, "The same key combination is bound twice to the same action"},
+	{"shadowed-bind", SeverityWarning, "A key combination is bound again to a different action"},
+	{"system-shortcut", SeverityWarning, "Bind clashes with a well-known system or application shortcut"},
+	{"invalid-windowrule", SeverityError, "Window rule is unknown or has bad arguments or matchers"},
+	{"missing-command", SeverityWarning, "An exec entry runs a program that is not installed"},
+	{"invalid-source", SeverityError, "A source entry names a file that does not exist or is sourced twice"},
+}
+
+// LintRules returns every rule Lint can report, including the semantic
+// checks of Analyze
+func LintRules() []LintRule {
+	rules := append([]LintRule(nil), lintRules...)
+	for _, r := range semanticRules {
+		rules = append(rules, LintRule{r.id, r.severity, r.description})
+	}
+	return rules
+}
+
+// lintSeverity returns the severity of a rule
+func lintSeverity(id string) Severity {
+	for _, r := range LintRules() {
+		if r.ID == id {
+			return r.Severity
+		}
+	}
+	return SeverityError
+}
+
+// conflictRules maps bind conflicts to their rule IDs
+var conflictRules = map[ConflictKind]string{
+	ConflictDuplicate: "duplicate-bind",
+	ConflictShadowed:  "shadowed-bind",
+	ConflictSystem:    "system-shortcut",
+}
+
+// Linter runs the lint checks. The zero value runs every check that only
+// needs the file itself.
+type Linter struct {
+	// Exec resolves the programs of exec entries; nil skips that check
+	Exec *ExecChecker
+}
+
+// Lint runs the default checks over doc
+func Lint(doc *Document) []Diagnostic {
+	return Linter{}.Lint(doc)
+}
+
+// LintFile runs the default checks over the file at path
+func LintFile(path string) ([]Diagnostic, error) {
+	return Linter{}.LintFile(path)
+}
+
+// LintFile parses and lints the config file at path and the files it
+// sources, so variables and other definitions are shared between them. A
+// file that cannot be parsed yields a single parse-error diagnostic.
+func (l Linter) LintFile(path string) ([]Diagnostic, error) {
+	doc, err := LoadDocument(path)
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		return []Diagnostic{parseDiagnostic(path, perr)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l.lint(doc, LoadDocument), nil
+}
+
+// LintTree lints the documents of tree, following its source entries
+func (l Linter) LintTree(tree *ConfigTree) []Diagnostic {
+	return l.lint(tree.Documents[0], func(path string) (*Document, error) {
+		if doc := tree.Document(path); doc != nil {
+			return doc, nil
+		}
+		return LoadDocument(path)
+	})
+}
+
+// Lint runs the parser, validators and semantic checks over doc. Unlike
+// LoadConfig it keeps going after errors. Diagnostics are sorted by
+// position and filtered by hyprmax-ignore comments. Source entries are not
+// followed, use LintFile or LintTree for a config split into files.
+func (l Linter) Lint(doc *Document) []Diagnostic {
+	return l.lint(doc, nil)
+}
+
+// lint checks doc and, when load is set, the files its source entries
+// name, in the order Hyprland reads them. Diagnostics are sorted by file,
+// in that order, and position.
+func (l Linter) lint(main *Document, load func(path string) (*Document, error)) []Diagnostic {
+	cfg := &HyprlandConfig{}
+	dec := &decoder{config: cfg}
+	var diags []Diagnostic
+	var docs []*Document
+	lines := make(map[string]map[int]*Line)
+
+	var walk func(doc *Document)
+	walk = func(doc *Document) {
+		docs = append(docs, doc)
+		byNum := make(map[int]*Line)
+		lines[doc.Path] = byNum
+		at := func(line *Line, col int, rule, message string) {
+			diags = append(diags, Diagnostic{doc.Path, line.Num, col, lintSeverity(rule), rule, message})
+		}
+
+		file, previous := dec.file, dec.previous
+		dec.file, dec.previous = doc.Path, nil
+		for _, line := range doc.Lines {
+			byNum[line.Num] = line
+			if line.Kind == LineAssign {
+				col := valueColumn(line)
+				if err := dec.assignment(line); err != nil {
+					at(line, col, assignmentRule(line), err.Error())
+				} else {
+					lintLine(cfg, line, col, at)
+				}
+			}
+			dec.previous = line
+		}
+		dec.file, dec.previous = file, previous
+	}
+
+	if load != nil {
+		// Relative source paths are resolved against the main config directory
+		dir := filepath.Dir(main.Path)
+		dec.source = func(value string) error {
+			files, err := sourceFiles(dir, cfg.expandVariables(value))
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				if _, seen := lines[file]; seen {
+					return fmt.Errorf("%s is sourced more than once", file)
+				}
+				doc, err := load(file)
+				var perr *ParseError
+				if errors.As(err, &perr) {
+					lines[file] = nil
+					diags = append(diags, parseDiagnostic(file, perr))
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				walk(doc)
+			}
+			return nil
+		}
+	}
+	walk(main)
+
+	// Checks over the whole model. Entries without a file come from main.
+	lineOf := func(file string, num int) (string, *Line) {
+		if file == "" {
+			file = main.Path
+		}
+		if line, ok := lines[file][num]; ok {
+			return file, line
+		}
+		return file, &Line{Num: num}
+	}
+	at := func(file string, line *Line, col int, rule, message string) {
+		diags = append(diags, Diagnostic{file, line.Num, col, lintSeverity(rule), rule, message})
+	}
+	for _, bind := range cfg.Binds {
+		if err := ValidateBind(bind, cfg.Variables); err != nil {
+			file, line := lineOf(bind.File, bind.Line)
+			at(file, line, valueColumn(line), "invalid-bind", err.Error())
+		}
+	}
+	for _, c := range FindBindConflicts(cfg) {
+		file, line := lineOf(c.Bind.File, c.Bind.Line)
+		at(file, line, valueColumn(line), conflictRules[c.Kind], c.Message)
+	}
+	if l.Exec != nil {
+		l.checkExec(cfg, lineOf, at)
+	}
+	for _, f := range Analyze(cfg) {
+		file, line := lineOf(f.File, f.Line)
+		diags = append(diags, Diagnostic{file, f.Line, valueColumn(line), f.Severity, f.Rule, f.Message})
+	}
+
+	order := make(map[string]int)
+	for i, doc := range docs {
+		order[doc.Path] = i
+		diags = suppress(doc, diags)
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.File != b.File {
+			return order[a.File] < order[b.File]
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+	return diags
+}
+
+// parseDiagnostic reports a file that cannot be parsed
+func parseDiagnostic(path string, perr *ParseError) Diagnostic {
+	return Diagnostic{File: path, Line: perr.Line, Col: 1, Severity: SeverityError,
+		Rule: "parse-error", Message: perr.Message}
+}
+
+// checkExec resolves the programs run by exec keywords and exec binds
+func (l Linter) checkExec(cfg *HyprlandConfig, lineOf func(string, int) (string, *Line),
+	at func(string, *Line, int, string, string)) {
+	check := func(file string, num int, command string) {
+		file, line := lineOf(file, num)
+		col := valueColumn(line)
+		// Binds carry the command at the end of the value
+		if strings.HasSuffix(line.Value, command) {
+			col += len(line.Value) - len(command)
+		}
+		if strings.HasPrefix(command, "[") {
+			// Skip the window rules of "exec [float] cmd"
+			if end := strings.Index(command, "]"); end >= 0 {
+				rest := command[end+1:]
+				col += len(command) - len(strings.TrimLeft(rest, " \t"))
+				command = strings.TrimLeft(rest, " \t")
+			}
+		}
+
+		expanded := cfg.expandVariables(command)
+		var terr *TokenError
+		if err := l.Exec.Check(expanded); errors.As(err, &terr) {
+			if expanded == command {
+				col += terr.Offset
+			}
+			at(file, line, col, "missing-command", terr.Error())
+		}
+	}
+
+	for _, e := range cfg.Exec {
+		check(e.File, e.Line, e.Command)
+	}
+	for _, b := range cfg.Binds {
+		if b.Dispatcher == "exec" || b.Dispatcher == "execr" {
+			check(b.File, b.Line, b.Params)
+		}
+	}
+}
+
+// assignmentRule picks the rule for an assignment the decoder rejected
+func assignmentRule(line *Line) string {
+	switch {
+	case line.Section == "" && strings.HasPrefix(line.Key, "bind"):
+		return "invalid-bind"
+	case line.Section == "" && strings.HasPrefix(line.Key, "windowrule"):
+		return "invalid-windowrule"
+	case line.Section == "" && line.Key == "source":
+		return "invalid-source"
+	}
+	return "invalid-value"
+}
+
+// lintLine checks a single assignment that parsed without errors
+func lintLine(cfg *HyprlandConfig, line *Line, col int, at func(*Line, int, string, string)) {
+	switch {
+	case line.Section == "" && (line.Key == "windowrule" || line.Key == "windowrulev2"):
+		var terr *TokenError
+		if err := ValidateWindowRule(line.Key, line.Value); errors.As(err, &terr) {
+			at(line, col+terr.Offset, "invalid-windowrule", terr.Error())
+		}
+		return
+	case line.Section == "" && !strings.Contains(line.Key, ":"):
+		// Other keywords and $variables
+		return
+	}
+
+	path := line.Path()
+	opt, ok := Lookup(path)
+	if !ok {
+		if suggestion := suggestOption(path); suggestion != "" {
+			at(line, 1+len(leadingSpace(line.Raw)), "unknown-option",
+				fmt.Sprintf("unknown option %s, did you mean %s?", path, suggestion))
+		}
+		return
+	}
+	if err := opt.Validate(cfg.expandVariables(line.Value)); err != nil {
+		at(line, col, "invalid-value", err.Error())
+	}
+	if opt.DeprecatedIn != "" {
+		msg := fmt.Sprintf("%s was deprecated in Hyprland %s", path, opt.DeprecatedIn)
+		for _, m := range migrations {
+			if m.From == path && m.To != "" {
+				msg += fmt.Sprintf(", use %s (hyprmax migrate)", m.To)
+			}
+		}
+		at(line, 1+len(leadingSpace(line.Raw)), "deprecated-option", msg)
+	}
+}
+
+// suggestOption returns a registered option in the same category whose
+// name is a likely typo of path
+func suggestOption(path string) string {
+	i := strings.LastIndex(path, ":")
+	if i < 0 {
+		return ""
+	}
+	category, name := path[:i], path[i+1:]
+	for _, opt := range OptionsIn(category) {
+		if opt.Category() == category && levenshtein(name, opt.Name()) <= 2 {
+			return opt.Path
+		}
+	}
+	return ""
+}
+
+// valueColumn returns the 1-based column where the value of an assignment
+// starts, or 1 for other lines
+func valueColumn(line *Line) int {
+	eq := strings.Index(line.Raw, "=")
+	if line.Kind != LineAssign || eq < 0 {
+		return 1
+	}
+	rest := line.Raw[eq+1:]
+	return eq + 2 + len(rest) - len(strings.TrimLeft(rest, " \t"))
+}
+
+const suppressionPrefix = "hyprmax-ignore"
+
+func isSuppression(comment string) bool {
+	return strings.HasPrefix(comment, suppressionPrefix)
+}
+
+// suppress drops diagnostics silenced by "# hyprmax-ignore: RULE-ID, ..."
+// comments. A trailing comment applies to its own line, a comment line to
+// the next line with content. Without rule IDs every rule is silenced.
+// Only diagnostics in doc are affected.
+func suppress(doc *Document, diags []Diagnostic) []Diagnostic {
+	ignored := make(map[int][]string)
+	var pending []string
+	for _, line := range doc.Lines {
+		var rules []string
+		if isSuppression(line.Comment) {
+			_, list, _ := strings.Cut(strings.TrimPrefix(line.Comment, suppressionPrefix), ":")
+			rules = []string{"*"}
+			if ids := strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }); len(ids) > 0 {
+				rules = ids
+			}
+		}
+
+		switch line.Kind {
+		case LineComment:
+			pending = append(pending, rules...)
+		case LineBlank:
+		default:
+			ignored[line.Num] = append(append(ignored[line.Num], rules...), pending...)
+			pending = nil
+		}
+	}
+
+	kept := diags[:0]
+	for _, d := range diags {
+		if d.File != doc.Path || !containsString(ignored[d.Line], d.Rule) && !containsString(ignored[d.Line], "*") {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
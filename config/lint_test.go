@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	content := `$mod = SUPER
+general {
+    gaps_in = lots
+    gaps_ot = 5
+    gaps_out = 5,10,5,10
+}
+decoration {
+    blur_size = 3 # hyprmax-ignore: deprecated-option
+    # hyprmax-ignore
+    drop_shadow = yes
+    inactive_opacity = 0.9
+    active_opacity = 0.8
+}
+animation = windows, 1, 7, missing
+windowrulev2 = size 50% tall, class:^(kitty)$
+bind = $mod, Q, killactive
+bind = $mod, Q, exec, kitty
+bind = $mod, Retrun, exec, kitty
+# hyprmax-ignore: system-shortcut
+bind = ALT, F4, killactive
+`
+	doc, err := ParseDocument("hyprland.conf", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type pos struct {
+		Line, Col int
+		Rule      string
+	}
+	var got []pos
+	for _, d := range Lint(doc) {
+		got = append(got, pos{d.Line, d.Col, d.Rule})
+	}
+	want := []pos{
+		{3, 15, "invalid-value"},
+		{4, 5, "unknown-option"},
+		{11, 24, "opacity-order"},
+		{14, 13, "undefined-bezier"},
+		{15, 25, "invalid-windowrule"},
+		{17, 8, "shadowed-bind"},
+		{18, 8, "invalid-bind"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestLintDiagnosticString(t *testing.T) {
+	d := Diagnostic{File: "hyprland.conf", Line: 3, Col: 15, Severity: SeverityError,
+		Rule: "invalid-value", Message: "general:gaps_in: must be a number (got: lots)"}
+	want := "hyprland.conf:3:15: error [invalid-value] general:gaps_in: must be a number (got: lots)"
+	if got := d.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestLintFileParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hyprland.conf")
+	if err := os.WriteFile(path, []byte("general {\n    gaps_in = 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	diags, err := LintFile(path)
+	if err != nil {
+		t.Fatalf("LintFile() error = %v", err)
+	}
+	if len(diags) != 1 || diags[0].Rule != "parse-error" || diags[0].Line != 1 {
+		t.Errorf("LintFile() = %v, want one parse-error on line 1", diags)
+	}
+}
+
+func TestLintFileSources(t *testing.T) {
+	dir := t.TempDir()
+	main, binds := filepath.Join(dir, "hyprland.conf"), filepath.Join(dir, "binds.conf")
+	files := map[string]string{
+		main:  "source = missing.conf\n$mainMod = SUPER\nsource = binds.conf\n",
+		binds: "bind = $mainMod, Return, exec, kitty\nbind = $mainMod, E, exec, $files\nbind = $terminal, T, exec, kitty\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type pos struct {
+		File string
+		Line int
+		Rule string
+	}
+	lint := func(path string) []pos {
+		t.Helper()
+		diags, err := LintFile(path)
+		if err != nil {
+			t.Fatalf("LintFile() error = %v", err)
+		}
+		var got []pos
+		for _, d := range diags {
+			got = append(got, pos{d.File, d.Line, d.Rule})
+		}
+		return got
+	}
+
+	// $mainMod comes from the file sourcing binds.conf
+	want := []pos{
+		{main, 1, "invalid-source"},
+		{binds, 3, "invalid-bind"},
+		{binds, 3, "undefined-variable"},
+	}
+	if got := lint(main); !reflect.DeepEqual(got, want) {
+		t.Errorf("LintFile() =\n%v\nwant\n%v", got, want)
+	}
+
+	// Linted alone it is undefined
+	want = []pos{
+		{binds, 1, "invalid-bind"},
+		{binds, 1, "undefined-variable"},
+		{binds, 2, "invalid-bind"},
+		{binds, 2, "undefined-variable"},
+		{binds, 3, "invalid-bind"},
+		{binds, 3, "undefined-variable"},
+	}
+	if got := lint(binds); !reflect.DeepEqual(got, want) {
+		t.Errorf("LintFile() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestLintRulesUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, r := range LintRules() {
+		if seen[r.ID] {
+			t.Errorf("rule %s defined twice", r.ID)
+		}
+		seen[r.ID] = true
+	}
+}
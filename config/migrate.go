@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration rewrites syntax a Hyprland release made obsolete
+type Migration struct {
+	ID    string
+	Since string // Hyprland version that dropped the old syntax
+	From  string // old option path, or keyword for keyword migrations
+	To    string // new option path, "" when the option was removed
+	Note  string
+
+	// convert maps the old value to the new one, nil keeps it
+	convert func(value string) (string, bool)
+	// rewrite handles migrations that are not a plain option rename
+	rewrite func(d *Document, m Migration) []Change
+}
+
+// Change is one edit made by a migration
+type Change struct {
+	Migration string
+	File      string // path of the document
+	Line      int    // line in the document before migrating
+	Old       string // the assignment as it was, with its full path
+	New       string // what it became, "" when it was removed
+}
+
+func (c Change) String() string {
+	at := fmt.Sprintf("line %d", c.Line)
+	if c.File != "" {
+		at = fmt.Sprintf("%s:%d", c.File, c.Line)
+	}
+	if c.New == "" {
+		return fmt.Sprintf("%s [%s]: removed %s", at, c.Migration, c.Old)
+	}
+	return fmt.Sprintf("%s [%s]: %s → %s", at, c.Migration, c.Old, c.New)
+}
+
+var migrations = []Migration{
+	{ID: "blur-enabled", Since: "0.28.0", From: "decoration:blur", To: "decoration:blur:enabled"},
+	{ID: "blur-size", Since: "0.28.0", From: "decoration:blur_size", To: "decoration:blur:size"},
+	{ID: "blur-passes", Since: "0.28.0", From: "decoration:blur_passes", To: "decoration:blur:passes"},
+	{ID: "general-sensitivity", Since: "0.32.0", From: "general:sensitivity",
+		Note: "use input:sensitivity, which takes a -1 to 1 offset instead of a multiplier"},
+	{ID: "apply-sens-to-raw", Since: "0.32.0", From: "general:apply_sens_to_raw"},
+	{ID: "cursor-inactive-timeout", Since: "0.39.0", From: "general:cursor_inactive_timeout", To: "cursor:inactive_timeout"},
+	{ID: "no-cursor-warps", Since: "0.39.0", From: "general:no_cursor_warps", To: "cursor:no_warps"},
+	{ID: "drop-shadow", Since: "0.42.0", From: "decoration:drop_shadow", To: "decoration:shadow:enabled"},
+	{ID: "shadow-range", Since: "0.42.0", From: "decoration:shadow_range", To: "decoration:shadow:range"},
+	{ID: "shadow-color", Since: "0.42.0", From: "decoration:col.shadow", To: "decoration:shadow:color"},
+	{ID: "shadow-offset", Since: "0.42.0", From: "decoration:shadow_offset", To: "decoration:shadow:offset"},
+	{ID: "direct-scanout", Since: "0.42.0", From: "misc:no_direct_scanout", To: "render:direct_scanout",
+		convert: invertBool},
+	{ID: "windowrule-v1", Since: "0.48.0", From: "windowrule", rewrite: migrateWindowRuleV1,
+		Note: "windowrule takes the windowrulev2 matcher syntax"},
+}
+
+// Migrations returns the migrations needed to bring a config up to target.
+// An empty target selects all of them.
+func Migrations(target string) []Migration {
+	var list []Migration
+	for _, m := range migrations {
+		if target == "" || compareVersions(m.Since, target) <= 0 {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// Migrate applies the migrations for target to doc and returns the changes
+// made. Lines that are not migrated, including comments, stay untouched.
+func Migrate(doc *Document, target string) []Change {
+	var changes []Change
+	for _, m := range Migrations(target) {
+		if m.rewrite != nil {
+			changes = append(changes, m.rewrite(doc, m)...)
+			continue
+		}
+		for line := doc.Find(m.From); line != nil; line = doc.Find(m.From) {
+			change := Change{Migration: m.ID, File: doc.Path, Line: line.orig, Old: m.From + " = " + line.Value}
+			value, ok := line.Value, true
+			if m.convert != nil {
+				value, ok = m.convert(value)
+				ok = ok && checkValue(value) == nil
+			}
+
+			switch {
+			case m.To == "" || !ok:
+				// Removed options are commented out so nothing is lost
+				note := "removed in Hyprland " + m.Since
+				if m.Note != "" {
+					note += ", " + m.Note
+				}
+				doc.Replace(line, leadingSpace(line.Raw)+"# "+strings.TrimSpace(line.Raw)+" # "+note)
+			case doc.Find(m.To) != nil:
+				// The new option is already set and wins
+				doc.Delete(m.From)
+			default:
+				doc.Rename(m.From, m.To)
+				if value != line.Value {
+					doc.Set(m.To, value)
+				}
+				change.New = m.To + " = " + value
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Line < changes[j].Line })
+	return changes
+}
+
+func migrateWindowRuleV1(doc *Document, m Migration) []Change {
+	var changes []Change
+	for _, line := range doc.Lines {
+		if line.Kind != LineAssign || line.Section != "" || line.Key != "windowrule" {
+			continue
+		}
+		rule, matcher, ok := strings.Cut(line.Value, ",")
+		if !ok {
+			continue
+		}
+		matcher = strings.TrimSpace(matcher)
+		field, _, _ := strings.Cut(matcher, ":")
+		if _, known := matcherFields[field]; !known {
+			// v1 matchers without a field match the class
+			matcher = "class:" + matcher
+		}
+		raw := assignmentRaw(leadingSpace(line.Raw), "windowrulev2", strings.TrimSpace(rule)+", "+matcher, line.Comment)
+		changes = append(changes, Change{Migration: m.ID, File: doc.Path, Line: line.orig,
+			Old: "windowrule = " + line.Value, New: "windowrulev2 = " + strings.TrimSpace(rule) + ", " + matcher})
+		line.Raw = raw
+	}
+	if len(changes) > 0 {
+		doc.reindex()
+	}
+	return changes
+}
+
+func invertBool(value string) (string, bool) {
+	b, ok := parseHyprBool(value)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatBool(!b), true
+}
+
+// compareVersions compares dotted version numbers like "0.42.0", returning
+// -1, 0 or 1. Missing components count as zero.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
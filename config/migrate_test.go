@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const legacyConfig = `# my config
+general {
+    gaps_in = 5
+    cursor_inactive_timeout = 3 # seconds
+    no_cursor_warps = true
+    sensitivity = 1.0
+}
+
+decoration {
+    rounding = 10
+    blur = true
+    blur_size = 3
+    drop_shadow = false
+    col.shadow = rgba(1a1a1aee)
+}
+
+misc {
+    no_direct_scanout = true
+}
+
+windowrule = float, ^(pavucontrol)$
+windowrule = opacity 0.9, title:^(Firefox)$
+`
+
+func TestMigrate(t *testing.T) {
+	doc, err := ParseDocument("hyprland.conf", legacyConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := Migrate(doc, "")
+	want := `# my config
+general {
+    gaps_in = 5
+    # sensitivity = 1.0 # removed in Hyprland 0.32.0, use input:sensitivity, which takes a -1 to 1 offset instead of a multiplier
+}
+
+decoration {
+    rounding = 10
+    blur {
+        enabled = true
+        size = 3
+    }
+    shadow {
+        enabled = false
+        color = rgba(1a1a1aee)
+    }
+}
+
+misc {
+}
+
+windowrulev2 = float, class:^(pavucontrol)$
+windowrulev2 = opacity 0.9, title:^(Firefox)$
+
+cursor {
+    inactive_timeout = 3 # seconds
+    no_warps = true
+}
+
+render {
+    direct_scanout = false
+}
+`
+	if got := doc.String(); got != want {
+		t.Errorf("migrated document:\n%s\nwant:\n%s", got, want)
+	}
+
+	wantLines := []int{4, 5, 6, 11, 12, 13, 14, 18, 21, 22}
+	if len(changes) != len(wantLines) {
+		t.Fatalf("got %d changes, want %d: %v", len(changes), len(wantLines), changes)
+	}
+	for i, c := range changes {
+		if c.Line != wantLines[i] {
+			t.Errorf("change %d (%s) on line %d, want %d", i, c.Migration, c.Line, wantLines[i])
+		}
+	}
+
+	// Migrating again changes nothing
+	if again := Migrate(doc, ""); len(again) != 0 {
+		t.Errorf("second migration made changes: %v", again)
+	}
+}
+
+func TestMigrateTarget(t *testing.T) {
+	doc, err := ParseDocument("hyprland.conf", legacyConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range Migrate(doc, "0.39.1") {
+		if m := c.Migration; m == "drop-shadow" || m == "windowrule-v1" {
+			t.Errorf("migration %s applied although the target predates it", m)
+		}
+	}
+	if !strings.Contains(doc.String(), "drop_shadow = false") {
+		t.Error("drop_shadow was migrated for target 0.39.1")
+	}
+}
+
+func TestMigrateKeepsExistingTarget(t *testing.T) {
+	doc, err := ParseDocument("hyprland.conf", "decoration {\n    blur_size = 3\n    blur {\n        size = 5\n    }\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Migrate(doc, "")
+	want := "decoration {\n    blur {\n        size = 5\n    }\n}\n"
+	if got := doc.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMigrateSourcedFiles(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "hyprland.conf")
+	legacy := filepath.Join(dir, "conf.d", "legacy.conf")
+	// The broken monitor line keeps LoadConfigTree from reading the config
+	files := map[string]string{
+		main:   "$conf = conf.d\nmonitor = broken\nsource = $conf/legacy.conf\n",
+		legacy: "decoration {\n    drop_shadow = false\n}\n",
+	}
+	for path, content := range files {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := LoadConfigTree(main); err == nil {
+		t.Fatal("LoadConfigTree() read the broken config")
+	}
+
+	docs, err := LoadDocuments(main)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	if len(docs) != 2 || docs[0].Path != main || docs[1].Path != legacy {
+		t.Fatalf("LoadDocuments() = %d documents", len(docs))
+	}
+	if changes := Migrate(docs[0], ""); len(changes) != 0 {
+		t.Errorf("main file changes = %v", changes)
+	}
+	changes := Migrate(docs[1], "")
+	if len(changes) != 1 || changes[0].File != legacy || changes[0].Line != 2 {
+		t.Fatalf("sourced file changes = %v", changes)
+	}
+	if want := legacy + ":2 [drop-shadow]: decoration:drop_shadow = false → decoration:shadow:enabled = false"; changes[0].String() != want {
+		t.Errorf("String() = %q, want %q", changes[0].String(), want)
+	}
+
+	os.WriteFile(main, []byte("source = ./hyprland.conf\n"), 0644)
+	if _, err := LoadDocuments(main); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("LoadDocuments() of a cycle = %v", err)
+	}
+}
+
+func TestDeprecatedOptionsHaveMigrations(t *testing.T) {
+	from := make(map[string]bool)
+	for _, m := range migrations {
+		from[m.From] = true
+	}
+	for _, opt := range Options() {
+		if opt.DeprecatedIn != "" && !from[opt.Path] {
+			t.Errorf("deprecated option %s has no migration", opt.Path)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.42.0", "0.42.0", 0},
+		{"0.9.0", "0.42.0", -1},
+		{"v0.43", "0.42.9", 1},
+		{"0.42", "0.42.0", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,705 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Nix support targets home-manager's wayland.windowManager.hyprland module.
+// Its settings attribute mirrors hyprland.conf: categories are nested
+// attribute sets, keywords such as bind or env are lists of strings and
+// variables are attributes named "$name". Submaps cannot be expressed in
+// settings, so their binds go to extraConfig.
+
+// nixLiteral is a value already rendered as Nix
+type nixLiteral string
+
+// nixItem is a list element, optionally preceded by a comment line
+type nixItem struct {
+	comment string
+	value   nixLiteral
+}
+
+type nixList []nixItem
+
+// WriteNix writes cfg as the attribute set for
+// wayland.windowManager.hyprland, holding settings and, when submaps are
+// used, extraConfig
+func WriteNix(w io.Writer, cfg *HyprlandConfig) error {
+	settings := make(map[string]any)
+	appendItem := func(m map[string]any, key, comment, value string) {
+		list, _ := m[key].(nixList)
+		m[key] = append(list, nixItem{comment, nixString(value)})
+	}
+
+	for name, value := range cfg.Variables {
+		settings["$"+name] = nixString(value)
+	}
+	for _, path := range exportedOptions(cfg) {
+		value, _ := cfg.GetOption(path)
+		setNested(settings, strings.Split(path, ":"), nixOption(path, value))
+	}
+
+	for _, m := range cfg.Monitors {
+		appendItem(settings, "monitor", "", keywordValue(monitorKeyword(m)))
+	}
+	for _, ws := range cfg.Workspaces {
+		appendItem(settings, "workspace", "", keywordValue(workspaceKeyword(ws)))
+	}
+	for _, dev := range cfg.Devices {
+		list, _ := settings["device"].(nixList)
+		settings["device"] = append(list, nixItem{"", nixDevice(dev)})
+	}
+	var extra strings.Builder
+	var submaps []string
+	for _, b := range cfg.Binds {
+		if b.Submap != "" {
+			if !containsString(submaps, b.Submap) {
+				submaps = append(submaps, b.Submap)
+			}
+			continue
+		}
+		k := bindKeyword(b)
+		comment := ""
+		if len(k.raw) > 1 {
+			comment = b.Description
+		}
+		appendItem(settings, "bind"+b.Flags, comment, keywordValue(k))
+	}
+	for i, submap := range submaps {
+		if i > 0 {
+			extra.WriteString("\n")
+		}
+		extra.WriteString("submap = " + submap + "\n")
+		for _, b := range cfg.Binds {
+			if b.Submap == submap {
+				writeBind(&extra, b)
+			}
+		}
+		extra.WriteString("submap = reset\n")
+	}
+	for _, r := range cfg.WindowRules {
+		appendItem(settings, r.Keyword(), "", r.Text())
+	}
+	for _, x := range cfg.Exec {
+		appendItem(settings, x.Kind, "", x.Command)
+	}
+	for _, k := range cfg.Keywords {
+		appendItem(settings, k.Name, "", k.Value)
+	}
+
+	if len(cfg.Animations.Beziers) > 0 || len(cfg.Animations.Animations) > 0 {
+		animations, ok := settings["animations"].(map[string]any)
+		if !ok {
+			animations = make(map[string]any)
+			settings["animations"] = animations
+		}
+		for _, c := range cfg.Animations.Beziers {
+			appendItem(animations, "bezier", "", keywordValue(bezierKeyword(c)))
+		}
+		for _, a := range cfg.Animations.Animations {
+			appendItem(animations, "animation", "", formatAnimation(a))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{\n  settings = ")
+	writeNixAttrs(&sb, settings, "  ")
+	sb.WriteString(";\n")
+	if extra.Len() > 0 {
+		sb.WriteString("\n  extraConfig = ''\n")
+		for _, line := range strings.Split(strings.TrimSuffix(extra.String(), "\n"), "\n") {
+			if line != "" {
+				sb.WriteString("    " + nixIndentedEscape(line))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("  '';\n")
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// nixDevice renders a device block as an attribute set, which home-manager
+// writes as a device { ... } block
+func nixDevice(dev Device) nixLiteral {
+	var sb strings.Builder
+	sb.WriteString("{ name = " + string(nixString(dev.Name)) + ";")
+	for _, o := range dev.Options {
+		sb.WriteString(" " + nixName(o.Name) + " = " + string(nixString(o.Value)) + ";")
+	}
+	sb.WriteString(" }")
+	return nixLiteral(sb.String())
+}
+
+// keywordValue returns the value of the last line of k, after "key = "
+func keywordValue(k *keyword) string {
+	_, value, _ := strings.Cut(k.raw[len(k.raw)-1], " = ")
+	return value
+}
+
+// setNested stores value under the nested attribute path. When a prefix
+// of the path is already a plain value the rest is kept as one
+// "a:b" attribute, which Hyprland reads the same way.
+func setNested(m map[string]any, parts []string, value nixLiteral) {
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			if _, taken := m[part]; taken {
+				m[strings.Join(parts[i:], ":")] = value
+				return
+			}
+			next = make(map[string]any)
+			m[part] = next
+		}
+		m = next
+	}
+	key := parts[len(parts)-1]
+	if _, isMap := m[key].(map[string]any); isMap {
+		key = strings.Join(parts, ":")
+	}
+	m[key] = value
+}
+
+// nixOption renders an option value as a Nix number, boolean or string
+// according to its schema type
+func nixOption(path, value string) nixLiteral {
+	if opt, ok := Lookup(path); ok {
+		switch opt.Type {
+		case TypeBool:
+			if b, ok := parseHyprBool(value); ok {
+				return nixLiteral(strconv.FormatBool(b))
+			}
+		case TypeInt, TypeFloat:
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				return nixLiteral(value)
+			}
+		case TypeGaps:
+			// A single number, the same on every side
+			if _, err := strconv.Atoi(value); err == nil {
+				return nixLiteral(value)
+			}
+		}
+	}
+	return nixString(value)
+}
+
+func nixString(s string) nixLiteral {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`, "\n", `\n`, "\t", `\t`)
+	return nixLiteral(`"` + r.Replace(s) + `"`)
+}
+
+// nixIndentedEscape escapes text for an indented string, the Nix string
+// delimited by two single quotes
+func nixIndentedEscape(s string) string {
+	return strings.NewReplacer("''", "'''", "${", "''${").Replace(s)
+}
+
+var nixIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_'-]*$`)
+
+var nixKeywords = map[string]bool{
+	"assert": true, "else": true, "if": true, "in": true, "inherit": true,
+	"let": true, "or": true, "rec": true, "then": true, "with": true,
+}
+
+func nixName(name string) string {
+	if nixIdentifier.MatchString(name) && !nixKeywords[name] {
+		return name
+	}
+	return string(nixString(name))
+}
+
+// writeNixAttrs renders an attribute set with sorted names, variables first
+func writeNixAttrs(sb *strings.Builder, m map[string]any, indent string) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		vi, vj := strings.HasPrefix(names[i], "$"), strings.HasPrefix(names[j], "$")
+		if vi != vj {
+			return vi
+		}
+		return names[i] < names[j]
+	})
+
+	inner := indent + "  "
+	sb.WriteString("{\n")
+	for _, name := range names {
+		sb.WriteString(inner + nixName(name) + " = ")
+		switch v := m[name].(type) {
+		case map[string]any:
+			writeNixAttrs(sb, v, inner)
+		case nixList:
+			sb.WriteString("[\n")
+			for _, item := range v {
+				if item.comment != "" {
+					sb.WriteString(inner + "  # " + item.comment + "\n")
+				}
+				sb.WriteString(inner + "  " + string(item.value) + "\n")
+			}
+			sb.WriteString(inner + "]")
+		case nixLiteral:
+			sb.WriteString(string(v))
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString(indent + "}")
+}
+
+// ParseNix reads home-manager Hyprland settings written in a subset of
+// Nix: attribute sets (also rec and dotted names), lists, strings without
+// interpolation, numbers, booleans, null and comments. The file may hold
+// the settings themselves, an attribute set with settings and extraConfig,
+// or a module setting wayland.windowManager.hyprland.
+func ParseNix(path string, src []byte) (*Export, error) {
+	p := &nixParser{path: path, src: string(src), line: 1}
+	p.skip()
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skip()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q after the value", p.src[p.pos])
+	}
+	return nixExport(path, value)
+}
+
+// ParseNixJSON reads the JSON evaluation of home-manager Hyprland settings,
+// e.g. the output of nix eval --json, in the shapes ParseNix accepts
+func ParseNixJSON(path string, src []byte) (*Export, error) {
+	dec := json.NewDecoder(strings.NewReader(string(src)))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return nixExport(path, value)
+}
+
+// nixExport turns evaluated settings into an export by rendering them as
+// hyprland.conf the way home-manager does and decoding the result
+func nixExport(path string, value any) (*Export, error) {
+	top, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected an attribute set", path)
+	}
+	for _, name := range []string{"wayland", "windowManager", "hyprland"} {
+		if next, ok := top[name].(map[string]any); ok {
+			top = next
+		}
+	}
+	settings, extraConfig := top, ""
+	if s, ok := top["settings"].(map[string]any); ok {
+		settings = s
+		extraConfig, _ = top["extraConfig"].(string)
+	}
+
+	var sb strings.Builder
+	if err := writeHyprconf(&sb, settings, ""); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	sb.WriteString(extraConfig)
+
+	doc, err := ParseDocument("", sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s: generated config: %w", path, err)
+	}
+	cfg := &HyprlandConfig{}
+	if err := decodeDocument(doc, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// Lines of the generated text mean nothing to the user
+	e := ExportConfig(&ConfigTree{Config: cfg, Documents: []*Document{doc}})
+	e.clearSources()
+	return e, nil
+}
+
+// writeHyprconf renders settings as hyprland.conf. Like home-manager it
+// writes variables and bezier curves before everything else.
+func writeHyprconf(sb *strings.Builder, m map[string]any, indent string) error {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	rank := func(name string) int {
+		switch {
+		case strings.HasPrefix(name, "$"):
+			return 0
+		case name == "bezier":
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := rank(names[i]), rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		switch v := m[name].(type) {
+		case map[string]any:
+			sb.WriteString(indent + name + " {\n")
+			if err := writeHyprconf(sb, v, indent+indentUnit); err != nil {
+				return err
+			}
+			sb.WriteString(indent + "}\n")
+		case []any:
+			for _, item := range v {
+				// A list of attribute sets, such as device, repeats the block
+				if block, ok := item.(map[string]any); ok {
+					sb.WriteString(indent + name + " {\n")
+					if err := writeHyprconf(sb, block, indent+indentUnit); err != nil {
+						return err
+					}
+					sb.WriteString(indent + "}\n")
+					continue
+				}
+				text, err := hyprconfValue(name, item)
+				if err != nil {
+					return err
+				}
+				sb.WriteString(indent + name + " = " + text + "\n")
+			}
+		case nil:
+		default:
+			text, err := hyprconfValue(name, v)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(indent + name + " = " + text + "\n")
+		}
+	}
+	return nil
+}
+
+func hyprconfValue(name string, v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return strings.ReplaceAll(v, "#", "##"), nil
+	case json.Number:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("%s: unsupported value %v", name, v)
+}
+
+// nixParser is a recursive descent parser for the Nix subset ParseNix
+// accepts. Values are returned the way encoding/json decodes them with
+// UseNumber.
+type nixParser struct {
+	path string
+	src  string
+	pos  int
+	line int
+}
+
+func (p *nixParser) errorf(format string, args ...any) error {
+	return &ParseError{p.path, p.line, fmt.Sprintf(format, args...)}
+}
+
+func (p *nixParser) advance(n int) {
+	p.line += strings.Count(p.src[p.pos:p.pos+n], "\n")
+	p.pos += n
+}
+
+// skip moves past white space and comments
+func (p *nixParser) skip() {
+	for p.pos < len(p.src) {
+		switch rest := p.src[p.pos:]; {
+		case strings.ContainsRune(" \t\r\n", rune(rest[0])):
+			p.advance(1)
+		case rest[0] == '#':
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			p.advance(end)
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				end = len(rest) - 2
+			}
+			p.advance(end + 2)
+		default:
+			return
+		}
+	}
+}
+
+func (p *nixParser) peek(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func (p *nixParser) expect(s string) error {
+	p.skip()
+	if !p.peek(s) {
+		if p.pos >= len(p.src) {
+			return p.errorf("expected %q, got end of file", s)
+		}
+		return p.errorf("expected %q", s)
+	}
+	p.advance(len(s))
+	return nil
+}
+
+var nixNumber = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?`)
+
+func (p *nixParser) value() (any, error) {
+	p.skip()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of file")
+	}
+	rest := p.src[p.pos:]
+	switch {
+	case rest[0] == '{':
+		return p.attrs()
+	case strings.HasPrefix(rest, "rec") && len(rest) > 3 && !nixIdentifier.MatchString(rest[:4]):
+		p.advance(3)
+		p.skip()
+		return p.attrs()
+	case rest[0] == '[':
+		return p.list()
+	case rest[0] == '"':
+		return p.str()
+	case strings.HasPrefix(rest, "''"):
+		return p.indentedStr()
+	}
+	if num := nixNumber.FindString(rest); num != "" {
+		p.advance(len(num))
+		return json.Number(num), nil
+	}
+	word := p.identifier()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, p.errorf("unexpected %q", rest[0])
+	}
+	return nil, p.errorf("unsupported expression %q; only plain values are understood", word)
+}
+
+func (p *nixParser) identifier() string {
+	end := p.pos
+	for end < len(p.src) {
+		c := p.src[end]
+		if c == '_' || c == '\'' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			end++
+			continue
+		}
+		break
+	}
+	word := p.src[p.pos:end]
+	p.advance(len(word))
+	return word
+}
+
+func (p *nixParser) attrs() (any, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	for {
+		p.skip()
+		if p.peek("}") {
+			p.advance(1)
+			return m, nil
+		}
+
+		var path []string
+		for {
+			p.skip()
+			var name string
+			if p.peek(`"`) {
+				s, err := p.str()
+				if err != nil {
+					return nil, err
+				}
+				name = s.(string)
+			} else if name = p.identifier(); name == "" {
+				return nil, p.errorf("expected an attribute name")
+			}
+			path = append(path, name)
+			p.skip()
+			if !p.peek(".") {
+				break
+			}
+			p.advance(1)
+		}
+		if path[0] == "inherit" {
+			return nil, p.errorf("inherit is not supported")
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(";"); err != nil {
+			return nil, err
+		}
+		if err := p.assign(m, path, value); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// assign stores value under a dotted attribute path, merging attribute
+// sets the way Nix does for a.b = 1; a.c = 2;
+func (p *nixParser) assign(m map[string]any, path []string, value any) error {
+	for _, name := range path[:len(path)-1] {
+		next, ok := m[name].(map[string]any)
+		if !ok {
+			if _, taken := m[name]; taken {
+				return p.errorf("attribute %q is already defined", strings.Join(path, "."))
+			}
+			next = make(map[string]any)
+			m[name] = next
+		}
+		m = next
+	}
+	name := path[len(path)-1]
+	existing, taken := m[name]
+	if !taken {
+		m[name] = value
+		return nil
+	}
+	a, okA := existing.(map[string]any)
+	b, okB := value.(map[string]any)
+	if !okA || !okB {
+		return p.errorf("attribute %q is already defined", strings.Join(path, "."))
+	}
+	for k, v := range b {
+		if err := p.assign(a, []string{k}, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *nixParser) list() (any, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	items := []any{}
+	for {
+		p.skip()
+		if p.peek("]") {
+			p.advance(1)
+			return items, nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+func (p *nixParser) str() (any, error) {
+	p.advance(1) // opening quote
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.advance(1)
+			return sb.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			switch e := p.src[p.pos+1]; e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(e)
+			}
+			p.advance(2)
+		case p.peek("${"):
+			return nil, p.errorf("string interpolation is not supported")
+		default:
+			sb.WriteByte(c)
+			p.advance(1)
+		}
+	}
+	return nil, p.errorf("unterminated string")
+}
+
+// indentedStr reads an indented string and strips the indentation common
+// to its non-blank lines, as Nix does
+func (p *nixParser) indentedStr() (any, error) {
+	p.advance(2)
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		switch {
+		case p.peek("'''"):
+			sb.WriteString("''")
+			p.advance(3)
+		case p.peek("''$"):
+			sb.WriteByte('$')
+			p.advance(3)
+		case p.peek(`''\`) && p.pos+3 < len(p.src):
+			switch e := p.src[p.pos+3]; e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(e)
+			}
+			p.advance(4)
+		case p.peek("''"):
+			p.advance(2)
+			return stripIndent(sb.String()), nil
+		case p.peek("${"):
+			return nil, p.errorf("string interpolation is not supported")
+		default:
+			sb.WriteByte(p.src[p.pos])
+			p.advance(1)
+		}
+	}
+	return nil, p.errorf("unterminated string")
+}
+
+func stripIndent(s string) string {
+	lines := strings.Split(s, "\n")
+	// The first line is dropped when it only holds white space
+	if strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " ")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
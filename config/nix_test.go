@@ -0,0 +1,145 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteNix(t *testing.T) {
+	tree := writeTree(t)
+	var sb strings.Builder
+	if err := WriteNix(&sb, tree.Config); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"  settings = {\n    \"$gaps\" = \"5\";\n    \"$mainMod\" = \"SUPER\";\n",
+		"    animations = {\n      animation = [\n        \"windows, 1, 7, ease\"\n      ];\n",
+		"      enabled = true;\n",
+		"    bind = [\n      # Terminal\n      \"$mainMod, Return, exec, kitty\"\n",
+		"    exec-once = [\n      \"waybar\"\n    ];\n",
+		"    env = [\n      \"XCURSOR_SIZE,24\"\n    ];\n",
+		"      gaps_in = 5;\n",
+		"  extraConfig = ''\n    submap = resize\n    binde = , right, resizeactive, 10 0\n    submap = reset\n  '';\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteNix() output lacks %q:\n%s", want, out)
+		}
+	}
+	// Binds of a submap only appear in extraConfig
+	if strings.Contains(out, "binde = [") {
+		t.Errorf("submap bind written to settings:\n%s", out)
+	}
+
+	// Reading the output back gives the same model,
+	back, err := ParseNix("hyprland.nix", []byte(out))
+	if err != nil {
+		t.Fatalf("ParseNix() of WriteNix output error = %v\n%s", err, out)
+	}
+	// except for file attribution and descriptions, which become comments
+	want := ExportConfig(tree)
+	want.clearSources()
+	want.Binds[0].Description = ""
+	if !reflect.DeepEqual(back, want) {
+		t.Errorf("round trip:\n got %+v\nwant %+v", back, want)
+	}
+}
+
+func TestParseNix(t *testing.T) {
+	src := `# home.nix
+{
+  wayland.windowManager.hyprland = rec {
+    enable = true;
+    settings = {
+      "$mod" = "SUPER";
+      general.gaps_in = 4;
+      general = { border_size = 2; };
+      decoration.blur = {
+        enabled = true; /* inline */ size = 3;
+      };
+      input.touchpad."tap-to-click" = false;
+      bind = [
+        "$mod, Q, killactive,"
+        "$mod, F, exec, firefox ## not a comment"
+      ];
+      env = [ "XCURSOR_SIZE,24" ];
+      animations.bezier = [ "ease, 0.05, 0.9, 0.1, 1.05" ];
+      animations.animation = [ "windows, 1, 7, ease" ];
+      device = [
+        { name = "epic-mouse"; sensitivity = -0.5; }
+        { name = "tablet"; "output" = "DP-1"; }
+      ];
+    };
+    extraConfig = ''
+      submap = resize
+      binde = , right, resizeactive, 10 0
+      submap = reset
+    '';
+  };
+}
+`
+	e, err := ParseNix("home.nix", []byte(src))
+	if err != nil {
+		t.Fatalf("ParseNix() error = %v", err)
+	}
+
+	var opts []string
+	for _, o := range e.Options {
+		opts = append(opts, o.Path+"="+o.Value)
+	}
+	want := "decoration:blur:enabled=true decoration:blur:size=3 general:border_size=2 general:gaps_in=4 input:touchpad:tap-to-click=false"
+	if got := strings.Join(opts, " "); got != want {
+		t.Errorf("options = %s\nwant %s", got, want)
+	}
+	if len(e.Variables) != 1 || e.Variables[0] != (ExportVariable{Name: "mod", Value: "SUPER"}) {
+		t.Errorf("variables = %+v", e.Variables)
+	}
+	if len(e.Binds) != 3 || e.Binds[1].Params != "firefox ## not a comment" || e.Binds[2].Submap != "resize" {
+		t.Errorf("binds = %+v", e.Binds)
+	}
+	if len(e.Beziers) != 1 || len(e.Animations) != 1 || e.Animations[0].Bezier != "ease" {
+		t.Errorf("beziers = %+v, animations = %+v", e.Beziers, e.Animations)
+	}
+	if len(e.Devices) != 2 || e.Devices[0].Name != "epic-mouse" || len(e.Devices[0].Options) != 1 ||
+		e.Devices[0].Options[0] != (ExportDeviceOption{Name: "sensitivity", Value: "-0.5"}) ||
+		e.Devices[1].Name != "tablet" || e.Devices[1].Options[0].Value != "DP-1" {
+		t.Errorf("devices = %+v", e.Devices)
+	}
+}
+
+func TestParseNixJSON(t *testing.T) {
+	src := `{"$mod": "SUPER", "general": {"gaps_in": 4, "allow_tearing": false},
+		"decoration": {"active_opacity": 0.9}, "bind": ["$mod, Q, killactive,"], "exec-once": ["waybar"]}`
+	e, err := ParseNixJSON("settings.json", []byte(src))
+	if err != nil {
+		t.Fatalf("ParseNixJSON() error = %v", err)
+	}
+	if len(e.Options) != 3 || e.Options[0].Value != "0.9" || e.Options[1].Value != "false" || e.Options[2].Value != "4" {
+		t.Errorf("options = %+v", e.Options)
+	}
+	if len(e.Binds) != 1 || len(e.Exec) != 1 || e.Exec[0].Kind != "exec-once" {
+		t.Errorf("binds = %+v, exec = %+v", e.Binds, e.Exec)
+	}
+}
+
+func TestParseNixErrors(t *testing.T) {
+	tests := []struct {
+		src, wantErr string
+	}{
+		{`{ a = "x${y}"; }`, "interpolation"},
+		{`{ a = import ./x.nix; }`, `unsupported expression "import"`},
+		{"{\n  a = 1;\n  a = 2;\n}", `hyprland.nix:3: attribute "a" is already defined`},
+		{`{ a = "open`, "unterminated string"},
+		{`{ a = 1 }`, `expected ";"`},
+		{`[ 1 ]`, "expected an attribute set"},
+		{`{ inherit foo; }`, "inherit"},
+	}
+	for _, tt := range tests {
+		_, err := ParseNix("hyprland.nix", []byte(tt.src))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseNix(%q) error = %v, want %q", tt.src, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// optionField locates an option with a typed field inside HyprlandConfig
+type optionField struct {
+	index []int
+	kind  reflect.Kind
+}
+
+var optionFields = make(map[string]optionField)
+
+// Typed fields are bound to option paths through their hypr tags: the
+// section tag on HyprlandConfig plus the field tag, e.g. "decoration:rounding"
+func init() {
+	root := reflect.TypeOf(HyprlandConfig{})
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		category := section.Tag.Get("hypr")
+		if category == "" || section.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for j := 0; j < section.Type.NumField(); j++ {
+			field := section.Type.Field(j)
+			if name := field.Tag.Get("hypr"); name != "" {
+				optionFields[category+":"+name] = optionField{
+					index: []int{i, j},
+					kind:  field.Type.Kind(),
+				}
+			}
+		}
+	}
+}
+
+// IsSet reports whether the option was explicitly assigned
+func (c *HyprlandConfig) IsSet(path string) bool {
+	return c.set[path]
+}
+
+func (c *HyprlandConfig) markSet(path string) {
+	if c.set == nil {
+		c.set = make(map[string]bool)
+	}
+	c.set[path] = true
+}
+
+// OptionLine returns the line the option was assigned on, or 0
+func (c *HyprlandConfig) OptionLine(path string) int {
+	return c.lines[path]
+}
+
+func (c *HyprlandConfig) setLine(path string, line int) {
+	if c.lines == nil {
+		c.lines = make(map[string]int)
+	}
+	c.lines[path] = line
+}
+
+// OptionFile returns the file the option was assigned in, or ""
+func (c *HyprlandConfig) OptionFile(path string) string {
+	return c.files[path]
+}
+
+// VariableFile returns the file the variable was defined in, or ""
+func (c *HyprlandConfig) VariableFile(name string) string {
+	return c.files["$"+name]
+}
+
+func (c *HyprlandConfig) setFile(key, file string) {
+	if file == "" {
+		return
+	}
+	if c.files == nil {
+		c.files = make(map[string]string)
+	}
+	c.files[key] = file
+}
+
+// optionValue returns the value of an option, or its schema default when
+// it was never set
+func (c *HyprlandConfig) optionValue(path string) string {
+	value, _ := c.GetOption(path)
+	return value
+}
+
+// GetOption returns the value of an option formatted as in hyprland.conf.
+// Options that were never set report their schema default.
+func (c *HyprlandConfig) GetOption(path string) (string, error) {
+	// Typed fields of unset options hold the Go zero value, not the default
+	if opt, ok := Lookup(path); ok && !c.IsSet(path) {
+		return opt.Default, nil
+	}
+	// Values the typed field could not hold are kept as written
+	if value, ok := c.extra[path]; ok {
+		return value, nil
+	}
+	if f, ok := optionFields[path]; ok {
+		v := reflect.ValueOf(c).Elem().FieldByIndex(f.index)
+		switch f.kind {
+		case reflect.Bool:
+			return strconv.FormatBool(v.Bool()), nil
+		case reflect.Int:
+			return strconv.FormatInt(v.Int(), 10), nil
+		case reflect.Float64:
+			return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+		}
+		return v.String(), nil
+	}
+	if opt, ok := Lookup(path); ok {
+		return opt.Default, nil
+	}
+	return "", fmt.Errorf("unknown option: %s", path)
+}
+
+// SetOption stores value for a registered option
+func (c *HyprlandConfig) SetOption(path, value string) error {
+	if _, ok := Lookup(path); !ok {
+		return fmt.Errorf("unknown option: %s", path)
+	}
+	return c.setRaw(path, value)
+}
+
+// CheckOption validates value for the option at path the way the parser
+// would read it, with variables expanded
+func (c *HyprlandConfig) CheckOption(path, value string) error {
+	opt, ok := Lookup(path)
+	if !ok {
+		if suggestion := suggestOption(path); suggestion != "" {
+			return fmt.Errorf("unknown option: %s, did you mean %s?", path, suggestion)
+		}
+		return fmt.Errorf("unknown option: %s", path)
+	}
+	return opt.Validate(c.expandVariables(value))
+}
+
+// setRaw stores value for any option path. Values of options without a
+// typed field, and values a typed field cannot hold, are kept verbatim so
+// they survive a rewrite; CheckOption and lint report the bad ones.
+func (c *HyprlandConfig) setRaw(path, value string) error {
+	value = strings.TrimSpace(value)
+
+	f, ok := optionFields[path]
+	if !ok {
+		if c.extra == nil {
+			c.extra = make(map[string]string)
+		}
+		c.extra[path] = value
+		c.markSet(path)
+		return nil
+	}
+
+	v := reflect.ValueOf(c).Elem().FieldByIndex(f.index)
+	parsed := true
+	switch f.kind {
+	case reflect.Bool:
+		var b bool
+		b, parsed = parseHyprBool(value)
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		parsed = err == nil
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		parsed = err == nil
+		v.SetFloat(n)
+	default:
+		v.SetString(value)
+	}
+	if parsed {
+		delete(c.extra, path)
+	} else {
+		if c.extra == nil {
+			c.extra = make(map[string]string)
+		}
+		c.extra[path] = value
+	}
+
+	c.markSet(path)
+	return nil
+}
+
+// ExtraOptions returns the set options that are not in the schema, by path
+func (c *HyprlandConfig) ExtraOptions() map[string]string {
+	extra := make(map[string]string)
+	for path, value := range c.extra {
+		if _, known := Lookup(path); !known {
+			extra[path] = value
+		}
+	}
+	return extra
+}
+
+// parseHyprBool accepts the boolean spellings Hyprland does
+func parseHyprBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true, true
+	case "false", "no", "off", "0":
+		return false, true
+	}
+	return false, false
+}
@@ -1,10 +1,9 @@
 package config
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -12,309 +11,390 @@ import (
 // DefaultConfigPath is the default location of hyprland.conf
 const DefaultConfigPath = "~/.config/hypr/hyprland.conf"
 
-// TestConfigPath is the path to the test configuration file
-const TestConfigPath = "config/testdata/hyprland.conf"
-
-// LoadConfig reads and parses the Hyprland configuration file
-func LoadConfig(path string, testMode bool) (*HyprlandConfig, error) {
-	if testMode {
-		path = TestConfigPath
-	} else if path == "" {
-		path = DefaultConfigPath
+// LoadConfig reads and parses the Hyprland configuration file. An empty
+// path loads the file ResolveConfigPath picks.
+func LoadConfig(path string) (*HyprlandConfig, error) {
+	if path == "" {
 		// Create backup before loading real config
 		if err := BackupConfig(path); err != nil {
 			return nil, fmt.Errorf("failed to create backup: %w", err)
 		}
 	}
 
-	// Expand home directory
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
-		}
-		path = filepath.Join(home, path[2:])
+	path, err := ExpandPath(path)
+	if err != nil {
+		return nil, err
 	}
 
-	file, err := os.Open(path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := ParseDocument(path, string(content))
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
 	config := &HyprlandConfig{}
-	scanner := bufio.NewScanner(file)
+	if err := decodeDocument(doc, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+// parseLine parses a config snippet, which may span several lines
+func parseLine(line string, config *HyprlandConfig) error {
+	doc, err := ParseDocument("", line)
+	if err != nil {
+		return err
+	}
+	return decodeDocument(doc, config)
+}
 
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+// decoder carries the state needed while walking a document
+type decoder struct {
+	config   *HyprlandConfig
+	previous *Line  // line before the current one, for bind descriptions
+	submap   string // submap binds are currently added to
+	file     string // path of the document being decoded
+	device   bool   // inside a device block, which fills the last of config.Devices
 
-		// Parse the line and populate config structure
-		if err := parseLine(line, config); err != nil {
-			return nil, err
-		}
-	}
+	// source is called for source lines when included files are followed
+	source func(value string) error
+}
 
-	return config, scanner.Err()
+// decodeDocument populates config from the assignments of doc
+func decodeDocument(doc *Document, config *HyprlandConfig) error {
+	return (&decoder{config: config}).decode(doc)
 }
 
-func parseLine(line string, config *HyprlandConfig) error {
-	// Handle monitor declarations
-	if strings.HasPrefix(line, "monitor=") {
-		return parseMonitor(line[8:], config)
+// decode walks the lines of doc. The decoder can be reused for included
+// files, which continue the submap of the including file.
+func (d *decoder) decode(doc *Document) error {
+	file, previous := d.file, d.previous
+	d.file, d.previous = doc.Path, nil
+	defer func() { d.file, d.previous = file, previous }()
+
+	for _, line := range doc.Lines {
+		switch line.Kind {
+		case LineAssign:
+			if err := d.assignment(line); err != nil {
+				if doc.Path == "" {
+					return err
+				}
+				return fmt.Errorf("%s:%d: %w", doc.Path, line.Num, err)
+			}
+		case LineOpen, LineClose:
+			d.block(line)
+		}
+		d.previous = line
 	}
+	return nil
+}
 
-	// Handle section blocks
-	if strings.Contains(line, "{") {
-		return parseSection(line, config)
+// block tracks the device blocks. The old "device:NAME {" form names the
+// device in the block header.
+func (d *decoder) block(line *Line) {
+	switch {
+	case line.Kind == LineOpen && line.Section == "" && isDeviceBlock(line.Key):
+		name := strings.TrimPrefix(strings.TrimPrefix(line.Key, "device"), ":")
+		d.config.Devices = append(d.config.Devices, Device{Name: name, Line: line.Num, File: d.file})
+		d.device = true
+	case line.Kind == LineClose && line.Section == "":
+		d.device = false
 	}
+}
 
-	// Handle direct assignments
-	if strings.Contains(line, "=") {
-		return parseAssignment(line, config)
-	}
+func isDeviceBlock(key string) bool {
+	return key == "device" || strings.HasPrefix(key, "device:")
+}
 
-	return nil
+// inDeviceBlock reports whether line is inside a device block
+func inDeviceBlock(line *Line) bool {
+	category, _, _ := strings.Cut(line.Section, ":")
+	return category == "device"
 }
 
-func parseMonitor(value string, config *HyprlandConfig) error {
-	parts := strings.Split(value, ",")
-	if len(parts) < 4 {
-		return fmt.Errorf("invalid monitor configuration: %s", value)
+// assignment handles a single key = value line
+func (d *decoder) assignment(line *Line) error {
+	config := d.config
+	key, value := line.Key, line.Value
+
+	if d.device {
+		device := &config.Devices[len(config.Devices)-1]
+		if key == "name" {
+			device.Name = value
+		} else {
+			device.Options = append(device.Options, DeviceOption{key, value})
+		}
+		return nil
 	}
 
-	monitor := Monitor{
-		Name:       parts[0],
-		Resolution: parts[1],
-		Position:   parts[2],
+	// Variable definitions
+	if strings.HasPrefix(key, "$") && line.Section == "" {
+		if config.Variables == nil {
+			config.Variables = make(map[string]string)
+		}
+		config.Variables[key[1:]] = value
+		config.setFile(key, d.file)
+		return nil
 	}
 
-	config.Monitors = append(config.Monitors, monitor)
+	// Keywords are valid at the top level; bezier and animation are
+	// normally written inside the animations block
+	switch {
+	case key == "bezier":
+		if err := parseBezier(value, config); err != nil {
+			return err
+		}
+		curves := config.Animations.Beziers
+		curves[len(curves)-1].Line, curves[len(curves)-1].File = line.Num, d.file
+		return nil
+	case key == "animation":
+		if err := parseAnimation(value, config); err != nil {
+			return err
+		}
+		anims := config.Animations.Animations
+		anims[len(anims)-1].Line, anims[len(anims)-1].File = line.Num, d.file
+		return nil
+	case line.Section != "":
+		// Options inside a category block
+	case key == "monitor":
+		if err := parseMonitor(value, config); err != nil {
+			return err
+		}
+		monitor := &config.Monitors[len(config.Monitors)-1]
+		monitor.Line, monitor.File = line.Num, d.file
+		return nil
+	case key == "submap":
+		d.submap = value
+		if value == "reset" {
+			d.submap = ""
+		}
+		return nil
+	case strings.HasPrefix(key, "bind") && isBindFlags(key[4:]):
+		description := ""
+		if d.previous != nil && d.previous.Kind == LineComment && !isSuppression(d.previous.Comment) {
+			description = d.previous.Comment
+		}
+		if err := parseKeybind(key[4:], value, description, config); err != nil {
+			return err
+		}
+		bind := &config.Binds[len(config.Binds)-1]
+		bind.Submap, bind.Line, bind.File = d.submap, line.Num, d.file
+		return nil
+	case key == "workspace":
+		if err := parseWorkspace(value, config); err != nil {
+			return err
+		}
+		workspace := &config.Workspaces[len(config.Workspaces)-1]
+		workspace.Line, workspace.File = line.Num, d.file
+		return nil
+	case key == "windowrule" || key == "windowrulev2":
+		if err := parseWindowRule(key, value, config); err != nil {
+			return err
+		}
+		rule := &config.WindowRules[len(config.WindowRules)-1]
+		rule.Line, rule.File = line.Num, d.file
+		return nil
+	case key == "exec" || key == "exec-once" || key == "exec-shutdown":
+		config.Exec = append(config.Exec, ExecCommand{Kind: key, Command: value, Line: line.Num, File: d.file})
+		return nil
+	case key == "source":
+		config.Sources = append(config.Sources, value)
+		if d.source != nil {
+			return d.source(value)
+		}
+		return nil
+	case !strings.Contains(key, ":"):
+		config.Keywords = append(config.Keywords, Keyword{Name: key, Value: value, Line: line.Num, File: d.file})
+		return nil
+	}
+
+	if err := config.setRaw(line.Path(), config.expandVariables(value)); err != nil {
+		return err
+	}
+	config.setLine(line.Path(), line.Num)
+	config.setFile(line.Path(), d.file)
 	return nil
 }
 
-func parseSection(line string, config *HyprlandConfig) error {
-	sectionName := strings.TrimSpace(strings.Split(line, "{")[0])
+// expandVariables substitutes $name references, longest names first so
+// $mod does not clobber $modShift
+func (c *HyprlandConfig) expandVariables(value string) string {
+	if len(c.Variables) == 0 || !strings.Contains(value, "$") {
+		return value
+	}
 
-	switch sectionName {
-	case "general":
-		return parseGeneralSection(line, config)
-	case "decoration":
-		return parseDecorationSection(line, config)
-	case "animations":
-		return parseAnimationsSection(line, config)
-		// Add other sections as needed
+	names := make([]string, 0, len(c.Variables))
+	for name := range c.Variables {
+		names = append(names, name)
 	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
 
-	return fmt.Errorf("unknown section: %s", sectionName)
+	for _, name := range names {
+		value = strings.ReplaceAll(value, "$"+name, c.Variables[name])
+	}
+	return value
 }
 
-// parseAssignment handles direct key=value assignments
-func parseAssignment(line string, config *HyprlandConfig) error {
-	parts := strings.SplitN(line, "=", 2)
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid assignment: %s", line)
+func parseMonitor(value string, config *HyprlandConfig) error {
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid monitor configuration: %s", value)
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
 	}
 
-	key := strings.TrimSpace(parts[0])
-	value := strings.TrimSpace(parts[1])
-
-	switch key {
-	case "monitor":
-		return parseMonitor(value, config)
-	case "bind":
-		return parseKeybind(value, config)
-	case "workspace":
-		return parseWorkspace(value, config)
+	monitor := Monitor{Name: parts[0], Resolution: parts[1]}
+	switch parts[1] {
+	case "disable", "disabled", "addreserved", "transform":
+		// Short rules take their own arguments instead of a mode
+		monitor.Args = strings.Join(parts[2:], ", ")
 	default:
-		return fmt.Errorf("unknown setting: %s", key)
+		if len(parts) < 4 {
+			return fmt.Errorf("invalid monitor configuration: %s", value)
+		}
+		monitor.Position, monitor.Scale = parts[2], parts[3]
+		monitor.Args = strings.Join(parts[4:], ", ")
 	}
-}
 
-// Helper function to parse section content
-type sectionParser struct {
-	content string
-	pos     int
-	inBlock bool
+	config.Monitors = append(config.Monitors, monitor)
+	return nil
 }
 
-func newSectionParser(line string) *sectionParser {
-	return &sectionParser{
-		content: line,
-		pos:     0,
-		inBlock: false,
+// isBindFlags reports whether s only holds valid bind flag letters
+func isBindFlags(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("lrcgoenmtidsp", c) {
+			return false
+		}
 	}
+	return true
 }
 
-func (sp *sectionParser) parseBlock() (map[string]string, error) {
-	values := make(map[string]string)
-	var currentLine string
-
-	for sp.pos < len(sp.content) {
-		char := sp.content[sp.pos]
-
-		switch char {
-		case '{':
-			sp.inBlock = true
-		case '}':
-			sp.inBlock = false
-			return values, nil
-		case '\n':
-			if sp.inBlock && len(strings.TrimSpace(currentLine)) > 0 {
-				parts := strings.SplitN(currentLine, "=", 2)
-				if len(parts) == 2 {
-					key := strings.TrimSpace(parts[0])
-					value := strings.TrimSpace(parts[1])
-					values[key] = value
-				}
-			}
-			currentLine = ""
-		default:
-			if sp.inBlock {
-				currentLine += string(char)
-			}
-		}
-		sp.pos++
+func parseKeybind(flags, value, description string, config *HyprlandConfig) error {
+	// bindd carries its description as the third field
+	fields := 4
+	if strings.Contains(flags, "d") {
+		fields = 5
 	}
 
-	return values, nil
-}
-
-func parseGeneralSection(line string, config *HyprlandConfig) error {
-	parser := newSectionParser(line)
-	values, err := parser.parseBlock()
-	if err != nil {
-		return err
+	parts := strings.SplitN(value, ",", fields)
+	if len(parts) < fields-1 {
+		return fmt.Errorf("invalid keybind: %s", value)
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	if fields == 5 {
+		description = parts[2]
+		parts = append(parts[:2], parts[3:]...)
 	}
 
-	// Parse values into GeneralSection struct
-	for key, value := range values {
-		switch key {
-		case "border_size":
-			if size, err := strconv.Atoi(value); err == nil {
-				config.General.BorderSize = size
-			}
-		case "gaps_in":
-			if gaps, err := strconv.Atoi(value); err == nil {
-				config.General.GapIn = gaps
-			}
-		case "gaps_out":
-			if gaps, err := strconv.Atoi(value); err == nil {
-				config.General.GapOut = gaps
-			}
-		case "cursor_inactive_timeout":
-			config.General.Cursor = value
-		case "layout":
-			config.General.Layout = value
-		case "no_focus_fallback":
-			config.General.NoFocusFollowMouse = value == "true"
-		}
+	bind := Bind{
+		Mods:        parts[0],
+		Key:         parts[1],
+		Dispatcher:  parts[2],
+		Flags:       flags,
+		Description: description,
+	}
+	if len(parts) > 3 {
+		bind.Params = parts[3]
 	}
 
+	config.Binds = append(config.Binds, bind)
 	return nil
 }
 
-func parseDecorationSection(line string, config *HyprlandConfig) error {
-	parser := newSectionParser(line)
-	values, err := parser.parseBlock()
-	if err != nil {
-		return err
+func parseWorkspace(value string, config *HyprlandConfig) error {
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid workspace: %s", value)
 	}
 
-	for key, value := range values {
-		switch key {
-		case "rounding":
-			if r, err := strconv.Atoi(value); err == nil {
-				config.Decoration.Rounding = r
-			}
-		case "blur":
-			config.Decoration.BlurEnabled = value == "true"
-		case "blur_size":
-			if size, err := strconv.Atoi(value); err == nil {
-				config.Decoration.BlurSize = size
-			}
-		case "blur_passes":
-			if passes, err := strconv.Atoi(value); err == nil {
-				config.Decoration.BlurPasses = passes
-			}
-		case "active_opacity":
-			if opacity, err := strconv.ParseFloat(value, 64); err == nil {
-				config.Decoration.Opacity = opacity
-			}
-		case "inactive_opacity":
-			if opacity, err := strconv.ParseFloat(value, 64); err == nil {
-				config.Decoration.InactiveOpacity = opacity
-			}
-		case "drop_shadow":
-			config.Decoration.DropShadow = value == "true"
-		case "shadow_range":
-			if range_, err := strconv.Atoi(value); err == nil {
-				config.Decoration.ShadowRange = range_
-			}
-		case "shadow_color":
-			config.Decoration.ShadowColor = value
+	workspace := Workspace{Name: strings.TrimSpace(parts[0])}
+	for _, rule := range parts[1:] {
+		rule = strings.TrimSpace(rule)
+		if monitor, ok := strings.CutPrefix(rule, "monitor:"); ok {
+			workspace.Monitor = monitor
+		} else if workspace.Monitor == "" && !strings.Contains(rule, ":") {
+			// Short form: workspace = NAME, MONITOR
+			workspace.Monitor = rule
 		}
 	}
 
+	config.Workspaces = append(config.Workspaces, workspace)
 	return nil
 }
 
-func parseAnimationsSection(line string, config *HyprlandConfig) error {
-	parser := newSectionParser(line)
-	values, err := parser.parseBlock()
-	if err != nil {
-		return err
+func parseWindowRule(keyword, value string, config *HyprlandConfig) error {
+	rule, target, ok := strings.Cut(value, ",")
+	if !ok {
+		return fmt.Errorf("invalid %s: %s", keyword, value)
 	}
 
-	for key, value := range values {
-		switch key {
-		case "enabled":
-			config.Animations.Enabled = value == "true"
-			// Add other animation settings as needed
-		}
+	name, args, _ := strings.Cut(strings.TrimSpace(rule), " ")
+	windowRule := WindowRule{
+		Rule:    name,
+		Value:   strings.TrimSpace(args),
+		Target:  strings.TrimSpace(target),
+		Version: 1,
+	}
+	if keyword == "windowrulev2" {
+		windowRule.Version = 2
 	}
 
+	config.WindowRules = append(config.WindowRules, windowRule)
 	return nil
 }
 
-func parseKeybind(value string, config *HyprlandConfig) error {
+func parseBezier(value string, config *HyprlandConfig) error {
 	parts := strings.Split(value, ",")
-	if len(parts) < 4 {
-		return fmt.Errorf("invalid keybind: %s", value)
-	}
-
-	bind := Bind{
-		Mods:       strings.TrimSpace(parts[0]),
-		Key:        strings.TrimSpace(parts[1]),
-		Dispatcher: strings.TrimSpace(parts[2]),
-		Params:     strings.TrimSpace(parts[3]),
+	if len(parts) != 5 {
+		return fmt.Errorf("invalid bezier: %s", value)
 	}
 
-	if len(parts) > 4 {
-		bind.Description = strings.TrimSpace(parts[4])
+	curve := BezierCurve{Name: strings.TrimSpace(parts[0])}
+	for i := range curve.Points {
+		p, err := strconv.ParseFloat(strings.TrimSpace(parts[i+1]), 64)
+		if err != nil {
+			return fmt.Errorf("invalid bezier point %q: %s", parts[i+1], value)
+		}
+		curve.Points[i] = p
 	}
 
-	config.Binds = append(config.Binds, bind)
+	config.Animations.Beziers = append(config.Animations.Beziers, curve)
 	return nil
 }
 
-func parseWorkspace(value string, config *HyprlandConfig) error {
+func parseAnimation(value string, config *HyprlandConfig) error {
 	parts := strings.Split(value, ",")
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid workspace: %s", value)
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid animation: %s", value)
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
 	}
 
-	workspace := Workspace{
-		Name:    strings.TrimSpace(parts[0]),
-		Monitor: strings.TrimSpace(parts[1]),
+	// NAME, ONOFF, SPEED, CURVE [,STYLE]; disabled ones may stop after ONOFF
+	animation := Animation{Target: parts[0], Enabled: parts[1] == "1"}
+	if len(parts) > 2 {
+		speed, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return fmt.Errorf("invalid animation speed %q: %s", parts[2], value)
+		}
+		animation.Duration = speed
+	}
+	if len(parts) > 3 {
+		animation.Bezier = parts[3]
+	}
+	if len(parts) > 4 {
+		animation.Style = parts[4]
 	}
 
-	config.Workspaces = append(config.Workspaces, workspace)
+	config.Animations.Animations = append(config.Animations.Animations, animation)
 	return nil
 }
-
-// Add other parsing functions...
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -20,6 +22,21 @@ func TestParseMonitor(t *testing.T) {
 			input:   "monitor=eDP-1",
 			wantErr: true,
 		},
+		{
+			name:    "missing scale",
+			input:   "monitor=eDP-1,1920x1080,0x0",
+			wantErr: true,
+		},
+		{
+			name:    "disabled monitor",
+			input:   "monitor=HDMI-A-1,disable",
+			wantErr: false,
+		},
+		{
+			name:    "reserved area",
+			input:   "monitor=DP-1,addreserved,30,0,0,0",
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -33,6 +50,34 @@ func TestParseMonitor(t *testing.T) {
 	}
 }
 
+func TestParseMonitorFields(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Monitor
+	}{
+		{"eDP-1, 1920x1080, 0x0, 1", Monitor{Name: "eDP-1", Resolution: "1920x1080", Position: "0x0", Scale: "1"}},
+		{"DP-1, preferred, auto, 1, transform, 1, vrr, 1",
+			Monitor{Name: "DP-1", Resolution: "preferred", Position: "auto", Scale: "1", Args: "transform, 1, vrr, 1"}},
+		{"HDMI-A-1, disable", Monitor{Name: "HDMI-A-1", Resolution: "disable"}},
+		{"DP-2, addreserved, 30, 0, 0, 0", Monitor{Name: "DP-2", Resolution: "addreserved", Args: "30, 0, 0, 0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			config := &HyprlandConfig{}
+			if err := parseMonitor(tt.input, config); err != nil {
+				t.Fatalf("parseMonitor() error = %v", err)
+			}
+			if got := config.Monitors[0]; got != tt.want {
+				t.Errorf("monitor = %+v, want %+v", got, tt.want)
+			}
+			if got := strings.Join(config.Monitors[0].Fields(), ", "); got != tt.input {
+				t.Errorf("Fields() = %q, want %q", got, tt.input)
+			}
+		})
+	}
+}
+
 func TestParseSection(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -53,8 +98,8 @@ func TestParseSection(t *testing.T) {
 				if c.General.BorderSize != 2 {
 					t.Errorf("expected BorderSize=2, got %d", c.General.BorderSize)
 				}
-				if c.General.GapIn != 5 {
-					t.Errorf("expected GapIn=5, got %d", c.General.GapIn)
+				if c.General.GapIn != "5" {
+					t.Errorf("expected GapIn=5, got %q", c.General.GapIn)
 				}
 			},
 		},
@@ -91,3 +136,114 @@ func TestParseSection(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("testdata/hyprland.conf")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if len(cfg.Monitors) != 2 || cfg.Monitors[1].Scale != "1" {
+		t.Errorf("Monitors = %+v", cfg.Monitors)
+	}
+	if cfg.General.GapOut != "10" || cfg.Decoration.InactiveOpacity != 0.9 {
+		t.Errorf("General = %+v, Decoration = %+v", cfg.General, cfg.Decoration)
+	}
+	if !cfg.Animations.Enabled || !cfg.IsSet("animations:enabled") {
+		t.Error("animations:enabled not loaded")
+	}
+	if cfg.IsSet("decoration:dim_inactive") {
+		t.Error("option not in the file reported as set")
+	}
+}
+
+func TestParseUnreadableValue(t *testing.T) {
+	// Values a typed field cannot hold are kept for lint to report
+	input := `general {
+    gaps_out = 5,10,5,10
+    border_size = thick
+}`
+	cfg := &HyprlandConfig{}
+	if err := parseLine(input, cfg); err != nil {
+		t.Fatalf("parseLine() error = %v", err)
+	}
+	for path, want := range map[string]string{"general:gaps_out": "5,10,5,10", "general:border_size": "thick"} {
+		if got, _ := cfg.GetOption(path); got != want {
+			t.Errorf("GetOption(%s) = %q, want %q", path, got, want)
+		}
+	}
+
+	// A later valid value replaces it
+	if err := cfg.SetOption("general:border_size", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cfg.GetOption("general:border_size"); got != "3" {
+		t.Errorf("GetOption(general:border_size) = %q after SetOption, want 3", got)
+	}
+}
+
+func TestParseKeywords(t *testing.T) {
+	input := `$mod = SUPER
+$gap = 7
+general {
+    gaps_in = $gap
+    col.active_border = rgba(33ccffee)
+    some_future_option = 1
+}
+animations {
+    bezier = ease, 0.05, 0.9, 0.1, 1.05
+    animation = windows, 1, 7, ease, slide
+}
+# Launch terminal
+bind = $mod, Return, exec, kitty --title "a, b"
+bindd = $mod, Q, Close window, killactive,
+binde = , XF86AudioRaiseVolume, exec, wpctl set-volume @DEFAULT_SINK@ 5%+
+windowrulev2 = opacity 0.9 0.8, class:^(kitty)$
+workspace = 1, monitor:DP-1, default:true
+exec-once = waybar
+env = A,1
+env = B,$gap
+`
+	cfg := &HyprlandConfig{}
+	if err := parseLine(input, cfg); err != nil {
+		t.Fatalf("parseLine() error = %v", err)
+	}
+
+	if cfg.General.GapIn != "7" {
+		t.Errorf("GapIn = %q, want variable expanded to 7", cfg.General.GapIn)
+	}
+	if got := cfg.ExtraOptions()["general:some_future_option"]; got != "1" {
+		t.Errorf("unknown option not preserved, got %q", got)
+	}
+
+	wantBinds := []Bind{
+		{Mods: "$mod", Key: "Return", Dispatcher: "exec", Params: `kitty --title "a, b"`, Description: "Launch terminal", Line: 13},
+		{Mods: "$mod", Key: "Q", Dispatcher: "killactive", Flags: "d", Description: "Close window", Line: 14},
+		{Key: "XF86AudioRaiseVolume", Dispatcher: "exec", Params: "wpctl set-volume @DEFAULT_SINK@ 5%+", Flags: "e", Line: 15},
+	}
+	if len(cfg.Binds) != len(wantBinds) {
+		t.Fatalf("Binds = %+v", cfg.Binds)
+	}
+	for i, want := range wantBinds {
+		if cfg.Binds[i] != want {
+			t.Errorf("Binds[%d] = %+v, want %+v", i, cfg.Binds[i], want)
+		}
+	}
+
+	if rule := cfg.WindowRules[0]; rule.Rule != "opacity" || rule.Value != "0.9 0.8" || rule.Target != "class:^(kitty)$" {
+		t.Errorf("WindowRules[0] = %+v", rule)
+	}
+	if ws := cfg.Workspaces[0]; ws.Name != "1" || ws.Monitor != "DP-1" {
+		t.Errorf("Workspaces[0] = %+v", ws)
+	}
+	if len(cfg.Animations.Beziers) != 1 || len(cfg.Animations.Animations) != 1 {
+		t.Errorf("Animations = %+v", cfg.Animations)
+	}
+	if len(cfg.Exec) != 1 || cfg.Exec[0].Kind != "exec-once" {
+		t.Errorf("Exec = %+v", cfg.Exec)
+	}
+	wantKeywords := []Keyword{{Name: "env", Value: "A,1", Line: 19}, {Name: "env", Value: "B,$gap", Line: 20}}
+	if !reflect.DeepEqual(cfg.Keywords, wantKeywords) {
+		t.Errorf("Keywords = %+v, want %+v", cfg.Keywords, wantKeywords)
+	}
+}
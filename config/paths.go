@@ -0,0 +1,249 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ConfigPathEnv names the environment variable that overrides the config
+// file location
+const ConfigPathEnv = "HYPRMAX_CONFIG"
+
+// sampleConfig is the bundled configuration used by test mode
+//
+//go:embed testdata/hyprland.conf
+var sampleConfig []byte
+
+// ResolveConfigPath returns the config file to use when none was given:
+// $HYPRMAX_CONFIG, then $XDG_CONFIG_HOME/hypr/hyprland.conf, then
+// DefaultConfigPath
+func ResolveConfigPath() string {
+	return resolveConfigPath(os.Getenv)
+}
+
+func resolveConfigPath(getenv func(string) string) string {
+	if path := getenv(ConfigPathEnv); path != "" {
+		return path
+	}
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "hypr", "hyprland.conf")
+	}
+	return DefaultConfigPath
+}
+
+// ExpandPath turns a config path into a file name that can be opened. An
+// empty path resolves to ResolveConfigPath and a leading ~ to the home
+// directory.
+func ExpandPath(path string) (string, error) {
+	if path == "" {
+		path = ResolveConfigPath()
+	}
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// ProfileDir returns the directory profiles are saved in:
+// $XDG_CONFIG_HOME/hyprmax/profiles, else ~/.config/hyprmax/profiles
+func ProfileDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "hyprmax", "profiles"), nil
+	}
+	return ExpandPath("~/.config/hyprmax/profiles")
+}
+
+// ProfilePath returns the file of a profile, a JSON export named after it
+func ProfilePath(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	dir, err := ProfileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Profiles returns the names of the saved profiles, sorted. A missing
+// profile directory holds none.
+func Profiles() ([]string, error) {
+	dir, err := ProfileDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && name != "" && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Sandbox is a throwaway copy of a config tree. Edits made to Path never
+// reach the original files.
+type Sandbox struct {
+	Dir  string // temporary directory holding the copy
+	Path string // config file inside Dir
+}
+
+// NewSandbox copies the config file at path and the files it sources into
+// a temporary directory. Files below the directory of the config keep
+// their place relative to it, so relative source entries resolve next to
+// the copy. Files sourced from elsewhere are copied below Dir/external and
+// the source entries of the copy are pointed at them. Nothing else is
+// copied.
+func NewSandbox(path string) (*Sandbox, error) {
+	file, err := ExpandPath(path)
+	if err == nil {
+		file, err = filepath.Abs(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "hyprmax-sandbox-")
+	if err != nil {
+		return nil, err
+	}
+	sb := &Sandbox{Dir: dir, Path: filepath.Join(dir, filepath.Base(file))}
+	if err := sb.copyConfig(file); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to copy %s: %w", file, err)
+	}
+	return sb, nil
+}
+
+// copyConfig copies file and the files it sources into the sandbox
+func (s *Sandbox) copyConfig(file string) error {
+	src := filepath.Dir(file)
+
+	// Edits reparse the document, so the source lines pointing outside
+	// src are collected first and rewritten by position afterwards
+	type rewrite struct {
+		doc   *Document
+		index int
+		raw   string
+	}
+	var rewrites []rewrite
+	docs, _ := loadDocuments(file, func(doc *Document, line *Line, value string) {
+		pattern, err := ExpandPath(strings.TrimSpace(value))
+		if err != nil {
+			return
+		}
+		if !filepath.IsAbs(pattern) {
+			if within(src, filepath.Join(src, pattern)) {
+				return
+			}
+			pattern = filepath.Join(src, pattern)
+		}
+		index := slices.Index(doc.Lines, line)
+		rewrites = append(rewrites, rewrite{doc, index, replaceValue(line, s.copyPath(src, pattern))})
+	})
+	edited := make(map[*Document]bool)
+	for _, r := range rewrites {
+		r.doc.Replace(r.doc.Lines[r.index], r.raw)
+		edited[r.doc] = true
+	}
+
+	// A config that does not load is copied as far as it was read; commands
+	// reading the copy report the error themselves
+	if len(docs) == 0 {
+		return copyFile(file, s.Path)
+	}
+
+	for _, doc := range docs {
+		target := s.copyPath(src, doc.Path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		var err error
+		if edited[doc] {
+			err = os.WriteFile(target, []byte(doc.String()), 0644)
+		} else {
+			// Symlinked files, as dotfile managers make them, are copied by
+			// content
+			err = copyFile(doc.Path, target)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyPath returns where the sandbox keeps its copy of path: at the same
+// place relative to Dir for files below the config directory src, else
+// below Dir/external
+func (s *Sandbox) copyPath(src, path string) string {
+	if within(src, path) {
+		rel, _ := filepath.Rel(src, path)
+		return filepath.Join(s.Dir, rel)
+	}
+	return filepath.Join(s.Dir, "external", path)
+}
+
+// within reports whether path is dir or below it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// NewTestSandbox returns a sandbox holding the bundled sample config
+func NewTestSandbox() (*Sandbox, error) {
+	dir, err := os.MkdirTemp("", "hyprmax-test-")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "hyprland.conf")
+	if err := os.WriteFile(path, sampleConfig, 0644); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Sandbox{Dir: dir, Path: path}, nil
+}
+
+// Remove deletes the sandbox and everything in it
+func (s *Sandbox) Remove() error {
+	return os.RemoveAll(s.Dir)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
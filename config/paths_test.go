@@ -0,0 +1,215 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveConfigPath(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"default", nil, DefaultConfigPath},
+		{"xdg", map[string]string{"XDG_CONFIG_HOME": "/cfg"}, "/cfg/hypr/hyprland.conf"},
+		{"override", map[string]string{"XDG_CONFIG_HOME": "/cfg", ConfigPathEnv: "/tmp/h.conf"}, "/tmp/h.conf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveConfigPath(func(key string) string { return tt.env[key] })
+			if got != tt.want {
+				t.Errorf("resolveConfigPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct{ path, want string }{
+		{"~/.config/hypr/hyprland.conf", filepath.Join(home, ".config/hypr/hyprland.conf")},
+		{"~", home},
+		{"/etc/hypr.conf", "/etc/hypr.conf"},
+		{"testdata/hyprland.conf", "testdata/hyprland.conf"},
+		{"~user/x", "~user/x"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandPath(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("ExpandPath(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if names, err := Profiles(); err != nil || names != nil {
+		t.Fatalf("Profiles() without a directory = %v, %v", names, err)
+	}
+
+	path, err := ProfilePath("work")
+	if err != nil || path != filepath.Join(dir, "hyprmax/profiles/work.json") {
+		t.Fatalf("ProfilePath(work) = %q, %v", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"work.json", "gaming.json", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(path), name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := Profiles()
+	if err != nil || len(names) != 2 || names[0] != "gaming" || names[1] != "work" {
+		t.Errorf("Profiles() = %v, %v, want [gaming work]", names, err)
+	}
+
+	for _, name := range []string{"", "..", "a/b"} {
+		if _, err := ProfilePath(name); err == nil {
+			t.Errorf("ProfilePath(%q) succeeded", name)
+		}
+	}
+}
+
+func TestNewSandbox(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"hyprland.conf":      "source = binds.conf\nsource = themes/*.conf\nsource = " + src + "/linked.conf\n",
+		"binds.conf":         "bind = SUPER, Q, killactive\n",
+		"themes/mocha.conf":  "$base = 0xff1e1e2e\n",
+		"scripts/bar.sh":     "#!/bin/sh\n",
+		"themes/.hidden.txt": "x",
+	}
+	// Files the config does not source stay behind
+	unsourced := []string{"scripts/bar.sh", "themes/.hidden.txt"}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Dotfile managers link the config into place
+	if err := os.Symlink(filepath.Join(src, "binds.conf"), filepath.Join(src, "linked.conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	sb, err := NewSandbox(filepath.Join(src, "hyprland.conf"))
+	if err != nil {
+		t.Fatalf("NewSandbox() error = %v", err)
+	}
+	defer sb.Remove()
+
+	if sb.Path != filepath.Join(sb.Dir, "hyprland.conf") {
+		t.Errorf("Path = %q, not inside %q", sb.Path, sb.Dir)
+	}
+	files["linked.conf"] = files["binds.conf"]
+	files["hyprland.conf"] = "source = binds.conf\nsource = themes/*.conf\nsource = " + sb.Dir + "/linked.conf\n"
+	for _, name := range unsourced {
+		delete(files, name)
+		if _, err := os.Stat(filepath.Join(sb.Dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was copied although the config does not source it", name)
+		}
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(sb.Dir, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	// Writing to the sandbox leaves the original alone
+	if err := os.WriteFile(sb.Path, []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(src, "hyprland.conf")); !strings.Contains(string(got), src+"/linked.conf") {
+		t.Errorf("original changed to %q", got)
+	}
+
+	if err := sb.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sb.Dir); !os.IsNotExist(err) {
+		t.Errorf("sandbox still exists after Remove()")
+	}
+
+	if _, err := NewSandbox(filepath.Join(src, "missing.conf")); err == nil {
+		t.Error("NewSandbox() accepted a missing config")
+	}
+}
+
+func TestNewSandboxExternalSources(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "hypr")
+	ext := filepath.Join(root, "dotfiles")
+	files := map[string]string{
+		"hypr/hyprland.conf":  "source = " + ext + "/extra.conf\nsource = ../shared/*.conf\nsource = " + src + "/binds.conf\n",
+		"hypr/binds.conf":     "bind = SUPER, Q, killactive\n",
+		"dotfiles/extra.conf": "$dir = " + ext + "\nsource = $dir/more.conf # nested\n",
+		"dotfiles/more.conf":  "general {\n    gaps_in = 3\n}\n",
+		"shared/a.conf":       "env = A,1\n",
+		"shared/b.conf":       "env = B,2\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sb, err := NewSandbox(filepath.Join(src, "hyprland.conf"))
+	if err != nil {
+		t.Fatalf("NewSandbox() error = %v", err)
+	}
+	defer sb.Remove()
+
+	tree, err := LoadConfigTree(sb.Path)
+	if err != nil {
+		t.Fatalf("LoadConfigTree() error = %v", err)
+	}
+	if len(tree.Documents) != 6 {
+		t.Errorf("got %d documents, want 6", len(tree.Documents))
+	}
+	if tree.Config.General.GapIn != "3" {
+		t.Errorf("gaps_in = %q from the nested source, want 3", tree.Config.General.GapIn)
+	}
+	for _, doc := range tree.Documents {
+		if !within(sb.Dir, doc.Path) {
+			t.Errorf("%s is read from outside the sandbox", doc.Path)
+		}
+		if err := os.WriteFile(doc.Path, []byte("changed\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range files {
+		if got, _ := os.ReadFile(filepath.Join(root, name)); string(got) != want {
+			t.Errorf("original %s changed to %q", name, got)
+		}
+	}
+}
+
+func TestNewTestSandbox(t *testing.T) {
+	sb, err := NewTestSandbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Remove()
+
+	cfg, err := LoadConfig(sb.Path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.General.BorderSize != 2 {
+		t.Errorf("BorderSize = %d, want the sample config value 2", cfg.General.BorderSize)
+	}
+}
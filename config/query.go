@@ -0,0 +1,655 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Query selects entries of a config. Its text form is a collection
+// optionally followed by a filter in brackets:
+//
+//	binds[dispatcher=exec && mods~SUPER]
+//	windowrules[match.class=firefox]
+//	options[section=decoration || section~^input]
+//
+// A filter combines conditions with &&, || and !, grouped by parentheses.
+// A condition compares a field with a value: = and != compare ignoring
+// case, ~ and !~ match a case-insensitive regular expression, and <, <=, >
+// and >= compare numbers. A bare field holds when it is neither empty, 0
+// nor false. Values containing spaces, && or || are quoted with " or '.
+type Query struct {
+	Collection string
+	coll       *queryCollection
+	filter     queryExpr // nil matches every record
+}
+
+// QueryRecord is an entry of a config as queries see it, by field name.
+// Values are strings with variables expanded.
+type QueryRecord map[string]string
+
+// QueryResult holds the records a query matched
+type QueryResult struct {
+	Collection string
+	Fields     []string // fields of the collection in column order
+	Columns    []string // fields shown by default
+	Records    []QueryRecord
+}
+
+// queryCollection is an entry list of the config that can be queried
+type queryCollection struct {
+	name    string
+	fields  []string
+	columns []string
+	records func(cfg *HyprlandConfig) []QueryRecord
+}
+
+var queryCollections = []*queryCollection{
+	{"binds",
+		[]string{"submap", "flags", "mods", "key", "combo", "dispatcher", "params", "description", "file", "line", "source"},
+		[]string{"combo", "dispatcher", "params", "submap", "source"},
+		bindRecords},
+	{"windowrules",
+		[]string{"keyword", "rule", "value", "target", "file", "line", "source"},
+		[]string{"keyword", "rule", "value", "target", "source"},
+		windowRuleRecords},
+	{"options",
+		[]string{"path", "section", "name", "value", "default", "type", "set", "file", "line", "source"},
+		[]string{"path", "value", "default", "source"},
+		optionRecords},
+	{"monitors",
+		[]string{"name", "resolution", "position", "scale", "args", "file", "line", "source"},
+		[]string{"name", "resolution", "position", "scale", "args", "source"},
+		monitorRecords},
+	{"workspaces",
+		[]string{"name", "monitor", "file", "line", "source"},
+		[]string{"name", "monitor", "source"},
+		workspaceRecords},
+	{"variables",
+		[]string{"name", "value", "file", "source"},
+		[]string{"name", "value", "source"},
+		variableRecords},
+	{"beziers",
+		[]string{"name", "points", "file", "line", "source"},
+		[]string{"name", "points", "source"},
+		bezierRecords},
+	{"animations",
+		[]string{"target", "enabled", "speed", "bezier", "style", "file", "line", "source"},
+		[]string{"target", "enabled", "speed", "bezier", "style", "source"},
+		animationRecords},
+	{"exec",
+		[]string{"kind", "command", "file", "line", "source"},
+		[]string{"kind", "command", "source"},
+		execRecords},
+}
+
+// QueryCollections returns the names of the collections queries select from
+func QueryCollections() []string {
+	var names []string
+	for _, c := range queryCollections {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func lookupCollection(name string) *queryCollection {
+	for _, c := range queryCollections {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// hasField reports whether records of the collection can have the field.
+// Window rules have a match.FIELD per matcher of their target.
+func (c *queryCollection) hasField(name string) bool {
+	for _, f := range c.fields {
+		if f == name {
+			return true
+		}
+	}
+	if c.name == "windowrules" {
+		_, known := matcherFields[strings.TrimPrefix(name, "match.")]
+		return known && strings.HasPrefix(name, "match.")
+	}
+	return false
+}
+
+// HasField reports whether the records the query selects can have a field
+func (q *Query) HasField(name string) bool {
+	return q.coll.hasField(name)
+}
+
+// Run returns the records of cfg the query matches, in config order
+func (q *Query) Run(cfg *HyprlandConfig) *QueryResult {
+	res := &QueryResult{Collection: q.coll.name, Fields: q.coll.fields, Columns: q.coll.columns}
+	for _, r := range q.coll.records(cfg) {
+		if q.filter == nil || q.filter.match(r) {
+			res.Records = append(res.Records, r)
+		}
+	}
+	return res
+}
+
+// ParseQuery parses the text form of a query. Errors are *TokenError
+// values pointing at the offending part.
+func ParseQuery(s string) (*Query, error) {
+	p := &queryParser{src: s}
+	p.skipSpace()
+	start := p.pos
+	name := p.name()
+	if name == "" {
+		return nil, p.errorf("expected a collection: %s", strings.Join(QueryCollections(), ", "))
+	}
+	coll := lookupCollection(name)
+	if coll == nil {
+		return nil, &TokenError{name, start, "unknown collection, want one of " + strings.Join(QueryCollections(), ", ")}
+	}
+	q := &Query{Collection: coll.name, coll: coll}
+	p.coll = coll
+
+	p.skipSpace()
+	if p.done() {
+		return q, nil
+	}
+	if !p.accept("[") {
+		return nil, p.errorf("expected [ after the collection")
+	}
+	p.skipSpace()
+	if !p.accept("]") {
+		filter, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.accept("]") {
+			return nil, p.errorf("expected ] or an operator")
+		}
+		q.filter = filter
+	}
+	p.skipSpace()
+	if !p.done() {
+		return nil, p.errorf("unexpected text after the filter")
+	}
+	return q, nil
+}
+
+// queryParser is a recursive descent parser over the text of a query
+type queryParser struct {
+	src  string
+	pos  int
+	coll *queryCollection
+}
+
+// queryOperators are the comparison operators, longest first
+var queryOperators = []string{"!=", "!~", "<=", ">=", "==", "=", "~", "<", ">"}
+
+func (p *queryParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *queryParser) skipSpace() {
+	for !p.done() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// accept consumes tok if the input continues with it
+func (p *queryParser) accept(tok string) bool {
+	if strings.HasPrefix(p.src[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// errorf returns an error pointing at the rest of the input
+func (p *queryParser) errorf(format string, args ...any) error {
+	return &TokenError{p.src[p.pos:], p.pos, fmt.Sprintf(format, args...)}
+}
+
+// name consumes a collection or field name such as match.initialClass
+func (p *queryParser) name() string {
+	start := p.pos
+	for !p.done() {
+		c := p.src[p.pos]
+		if c != '_' && c != '.' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *queryParser) or() (queryExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.accept("||"); p.skipSpace() {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) and() (queryExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.accept("&&"); p.skipSpace() {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) unary() (queryExpr, error) {
+	p.skipSpace()
+	switch {
+	case p.accept("!"):
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{expr}, nil
+	case p.accept("("):
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.accept(")") {
+			return nil, p.errorf("expected ) or an operator")
+		}
+		return expr, nil
+	}
+	return p.condition()
+}
+
+func (p *queryParser) condition() (queryExpr, error) {
+	start := p.pos
+	field := p.name()
+	if field == "" {
+		return nil, p.errorf("expected a field of %s", p.coll.name)
+	}
+	if !p.coll.hasField(field) {
+		msg := fmt.Sprintf("unknown field of %s, want one of %s", p.coll.name, strings.Join(p.coll.fields, ", "))
+		if p.coll.name == "windowrules" {
+			msg += " or match.FIELD"
+			if m, ok := strings.CutPrefix(field, "match."); ok {
+				msg = "unknown matcher field"
+				if s := suggestMatcherField(m); s != "" {
+					msg += fmt.Sprintf(", did you mean %q?", "match."+s)
+				}
+			}
+		}
+		return nil, &TokenError{field, start, msg}
+	}
+
+	p.skipSpace()
+	op := ""
+	for _, o := range queryOperators {
+		if p.accept(o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return queryTruth{field}, nil
+	}
+	if op == "==" {
+		op = "="
+	}
+
+	p.skipSpace()
+	valueStart := p.pos
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	cond := queryCond{field: field, op: op, value: value}
+	switch op {
+	case "~", "!~":
+		re, err := regexp.Compile("(?i)" + value)
+		if err != nil {
+			return nil, &TokenError{value, valueStart, "invalid regular expression"}
+		}
+		cond.re = re
+	case "<", "<=", ">", ">=":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, &TokenError{value, valueStart, op + " needs a number"}
+		}
+		cond.num = n
+	}
+	return cond, nil
+}
+
+// value consumes a quoted or bare value. A bare value ends at a space, &&,
+// || or a bracket that closes the filter; brackets and parentheses it
+// opens itself, as in ^(kitty)$, are part of it.
+func (p *queryParser) value() (string, error) {
+	start := p.pos
+	if !p.done() && (p.src[p.pos] == '"' || p.src[p.pos] == '\'') {
+		quote := p.src[p.pos]
+		p.pos++
+		var sb strings.Builder
+		for {
+			if p.done() {
+				return "", &TokenError{p.src[start:], start, "unterminated string"}
+			}
+			c := p.src[p.pos]
+			if c == quote {
+				p.pos++
+				return sb.String(), nil
+			}
+			// Inside double quotes \" and \\ are escapes, other backslashes
+			// are kept for regular expressions
+			if c == '\\' && quote == '"' && p.pos+1 < len(p.src) && (p.src[p.pos+1] == '"' || p.src[p.pos+1] == '\\') {
+				p.pos++
+				c = p.src[p.pos]
+			}
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	depth := 0
+	for !p.done() {
+		c := p.src[p.pos]
+		rest := p.src[p.pos:]
+		if c == ' ' || c == '\t' || strings.HasPrefix(rest, "&&") || strings.HasPrefix(rest, "||") {
+			break
+		}
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			if depth == 0 {
+				return p.src[start:p.pos], p.emptyValue(start)
+			}
+			depth--
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos], p.emptyValue(start)
+}
+
+func (p *queryParser) emptyValue(start int) error {
+	if p.pos == start {
+		return p.errorf("expected a value")
+	}
+	return nil
+}
+
+// queryExpr is a parsed filter
+type queryExpr interface {
+	match(r QueryRecord) bool
+}
+
+type queryAnd struct{ left, right queryExpr }
+
+func (e queryAnd) match(r QueryRecord) bool { return e.left.match(r) && e.right.match(r) }
+
+type queryOr struct{ left, right queryExpr }
+
+func (e queryOr) match(r QueryRecord) bool { return e.left.match(r) || e.right.match(r) }
+
+type queryNot struct{ expr queryExpr }
+
+func (e queryNot) match(r QueryRecord) bool { return !e.expr.match(r) }
+
+// queryTruth is a bare field
+type queryTruth struct{ field string }
+
+func (e queryTruth) match(r QueryRecord) bool {
+	v := r[e.field]
+	return v != "" && v != "0" && !strings.EqualFold(v, "false")
+}
+
+type queryCond struct {
+	field, op, value string
+	re               *regexp.Regexp
+	num              float64
+}
+
+func (e queryCond) match(r QueryRecord) bool {
+	v := r[e.field]
+	switch e.op {
+	case "=":
+		return strings.EqualFold(v, e.value)
+	case "!=":
+		return !strings.EqualFold(v, e.value)
+	case "~":
+		return e.re.MatchString(v)
+	case "!~":
+		return !e.re.MatchString(v)
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return false
+	}
+	switch e.op {
+	case "<":
+		return n < e.num
+	case "<=":
+		return n <= e.num
+	case ">":
+		return n > e.num
+	}
+	return n >= e.num
+}
+
+// withSource adds the file, line and file:line fields to r
+func withSource(r QueryRecord, file string, line int) QueryRecord {
+	r["file"] = file
+	r["source"] = file
+	if line > 0 {
+		r["line"] = strconv.Itoa(line)
+		r["source"] = fmt.Sprintf("%s:%d", file, line)
+	}
+	return r
+}
+
+func bindRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	for _, b := range cfg.Binds {
+		mods := strings.ToUpper(cfg.expandVariables(b.Mods))
+		if normalized, err := NormalizeMods(b.Mods, cfg.Variables); err == nil {
+			mods = strings.Join(normalized, " ")
+		}
+		records = append(records, withSource(QueryRecord{
+			"submap":      b.Submap,
+			"flags":       b.Flags,
+			"mods":        mods,
+			"key":         cfg.expandVariables(b.Key),
+			"combo":       b.Combo(cfg.Variables),
+			"dispatcher":  cfg.expandVariables(b.Dispatcher),
+			"params":      cfg.expandVariables(b.Params),
+			"description": b.Description,
+		}, b.File, b.Line))
+	}
+	return records
+}
+
+func windowRuleRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	for _, r := range cfg.WindowRules {
+		target := cfg.expandVariables(r.Target)
+		record := QueryRecord{
+			"keyword": r.Keyword(),
+			"rule":    r.Rule,
+			"value":   cfg.expandVariables(r.Value),
+			"target":  target,
+		}
+		for field, pattern := range windowMatchers(r.Version, target) {
+			record["match."+field] = pattern
+		}
+		records = append(records, withSource(record, r.File, r.Line))
+	}
+	return records
+}
+
+// windowMatchers returns the patterns of a window rule target by matcher
+// field. The ^(...)$ anchors configs usually wrap patterns in are removed,
+// so match.class=firefox finds class:^(firefox)$.
+func windowMatchers(version int, target string) map[string]string {
+	matchers := make(map[string]string)
+	if version == 1 {
+		field, pattern := "class", strings.TrimSpace(target)
+		if rest, ok := strings.CutPrefix(pattern, "title:"); ok {
+			field, pattern = "title", rest
+		}
+		matchers[field] = stripAnchors(strings.TrimPrefix(pattern, "class:"))
+		return matchers
+	}
+	for _, m := range splitMatchers(target, 0) {
+		field, pattern, ok := strings.Cut(m.text, ":")
+		if ok {
+			matchers[field] = stripAnchors(strings.TrimSpace(pattern))
+		}
+	}
+	return matchers
+}
+
+// stripAnchors turns ^(pattern)$ and ^pattern$ into pattern
+func stripAnchors(pattern string) string {
+	if len(pattern) < 2 || pattern[0] != '^' || pattern[len(pattern)-1] != '$' || strings.HasSuffix(pattern, `\$`) {
+		return pattern
+	}
+	inner := pattern[1 : len(pattern)-1]
+	if len(inner) >= 2 && inner[0] == '(' && inner[len(inner)-1] == ')' && !strings.ContainsAny(inner[1:len(inner)-1], "()") {
+		inner = inner[1 : len(inner)-1]
+	}
+	return inner
+}
+
+// optionRecords returns every option of the schema with its current value,
+// followed by the set options the schema does not know and a record per
+// assignment of other keywords, such as env
+func optionRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	option := func(path, category, name, value, def, typ string) {
+		records = append(records, withSource(QueryRecord{
+			"path":    path,
+			"section": category,
+			"name":    name,
+			"value":   value,
+			"default": def,
+			"type":    typ,
+			"set":     strconv.FormatBool(cfg.IsSet(path)),
+		}, cfg.OptionFile(path), cfg.OptionLine(path)))
+	}
+	for _, opt := range Options() {
+		value, _ := cfg.GetOption(opt.Path)
+		option(opt.Path, opt.Category(), opt.Name(), value, opt.Default, opt.Type.String())
+	}
+
+	extra := cfg.ExtraOptions()
+	paths := make([]string, 0, len(extra))
+	for path := range extra {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		category, name := "", path
+		if i := strings.LastIndex(path, ":"); i >= 0 {
+			category, name = path[:i], path[i+1:]
+		}
+		option(path, category, name, extra[path], "", "")
+	}
+
+	for _, k := range cfg.Keywords {
+		records = append(records, withSource(QueryRecord{
+			"path":    k.Name,
+			"section": "",
+			"name":    k.Name,
+			"value":   cfg.expandVariables(k.Value),
+			"default": "",
+			"type":    "keyword",
+			"set":     "true",
+		}, k.File, k.Line))
+	}
+	return records
+}
+
+func monitorRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	for _, m := range cfg.Monitors {
+		records = append(records, withSource(QueryRecord{
+			"name":       m.Name,
+			"resolution": m.Resolution,
+			"position":   m.Position,
+			"scale":      m.Scale,
+			"args":       m.Args,
+		}, m.File, m.Line))
+	}
+	return records
+}
+
+func workspaceRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	for _, ws := range cfg.Workspaces {
+		records = append(records, withSource(QueryRecord{"name": ws.Name, "monitor": ws.Monitor}, ws.File, ws.Line))
+	}
+	return records
+}
+
+// variableRecords returns the variables sorted by name, without the "$"
+func variableRecords(cfg *HyprlandConfig) []QueryRecord {
+	names := make([]string, 0, len(cfg.Variables))
+	for name := range cfg.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var records []QueryRecord
+	for _, name := range names {
+		records = append(records, withSource(QueryRecord{"name": name, "value": cfg.Variables[name]}, cfg.VariableFile(name), 0))
+	}
+	return records
+}
+
+func bezierRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	for _, c := range cfg.Animations.Beziers {
+		var points []string
+		for _, p := range c.Points {
+			points = append(points, strconv.FormatFloat(p, 'f', -1, 64))
+		}
+		records = append(records, withSource(QueryRecord{"name": c.Name, "points": strings.Join(points, ", ")}, c.File, c.Line))
+	}
+	return records
+}
+
+func animationRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	for _, a := range cfg.Animations.Animations {
+		records = append(records, withSource(QueryRecord{
+			"target":  a.Target,
+			"enabled": strconv.FormatBool(a.Enabled),
+			"speed":   strconv.FormatFloat(a.Duration, 'f', -1, 64),
+			"bezier":  a.Bezier,
+			"style":   a.Style,
+		}, a.File, a.Line))
+	}
+	return records
+}
+
+func execRecords(cfg *HyprlandConfig) []QueryRecord {
+	var records []QueryRecord
+	for _, x := range cfg.Exec {
+		records = append(records, withSource(QueryRecord{"kind": x.Kind, "command": cfg.expandVariables(x.Command)}, x.File, x.Line))
+	}
+	return records
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hyprland.conf")
+	content := `$mod = SUPER
+$term = kitty
+monitor = DP-1, 2560x1440@144, 0x0, 1
+decoration {
+    rounding = 8
+}
+animations {
+    bezier = snap, 0.05, 0.9, 0.1, 1.05
+    animation = windows, 1, 7, snap
+    animation = fade, 1, 3, default
+}
+bind = $mod, Return, exec, $term
+bind = $mod SHIFT, Q, killactive,
+bind = ALT, F, exec, firefox
+bind = $mod, 1, workspace, 1
+windowrulev2 = float, class:^(firefox)$, title:^(Picture-in-Picture)$
+windowrulev2 = opacity 0.9, class:^(kitty)$
+windowrule = float, title:^(pavucontrol)$
+exec-once = waybar
+env = XCURSOR_SIZE,24
+env = TERMINAL,$term
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tree, err := LoadConfigTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		field string // field listed in want
+		want  []string
+	}{
+		{"binds", "key", []string{"Return", "Q", "F", "1"}},
+		{"binds[]", "key", []string{"Return", "Q", "F", "1"}},
+		{"binds[dispatcher=exec && mods~SUPER]", "params", []string{"kitty"}},
+		{"binds[dispatcher=EXEC]", "key", []string{"Return", "F"}},
+		{"binds[mods='SUPER SHIFT']", "combo", []string{"SUPER+SHIFT+q"}},
+		{"binds[!(dispatcher=exec) && key!=1]", "dispatcher", []string{"killactive"}},
+		{"binds[dispatcher=workspace || params~fire]", "key", []string{"F", "1"}},
+		{"binds[key~^[0-9]$]", "dispatcher", []string{"workspace"}},
+		{"binds[line>=14]", "key", []string{"F", "1"}},
+		{"windowrules[match.class=firefox]", "rule", []string{"float"}},
+		{"windowrules[match.title~picture]", "target", []string{"class:^(firefox)$, title:^(Picture-in-Picture)$"}},
+		{`windowrules[match.title="pavucontrol"]`, "keyword", []string{"windowrule"}},
+		{"windowrules[match.class && rule=opacity]", "value", []string{"0.9"}},
+		{"options[section=decoration && set]", "path", []string{"decoration:rounding"}},
+		{"options[path=decoration:rounding]", "value", []string{"8"}},
+		{"options[path=general:gaps_out]", "value", []string{"20"}},
+		{"options[set && type=keyword]", "value", []string{"XCURSOR_SIZE,24", "TERMINAL,kitty"}},
+		{"options[path=env]", "line", []string{"20", "21"}},
+		{"animations[speed<5]", "target", []string{"fade"}},
+		{"beziers", "points", []string{"0.05, 0.9, 0.1, 1.05"}},
+		{"variables[value=kitty]", "name", []string{"term"}},
+		{"monitors[name=DP-1]", "source", []string{path + ":3"}},
+		{"exec[kind=exec-once]", "command", []string{"waybar"}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, r := range q.Run(tree.Config).Records {
+			got = append(got, r[tt.field])
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		offset int
+		msg    string
+	}{
+		{"", 0, "expected a collection"},
+		{"keybinds", 0, "unknown collection"},
+		{"binds[dispatch=exec]", 6, "unknown field of binds"},
+		{"binds[dispatcher=exec", 21, "expected ]"},
+		{"binds[mods=SUPER SHIFT]", 17, "expected ]"},
+		{"binds[dispatcher=]", 17, "expected a value"},
+		{"binds[dispatcher=exec &&]", 24, "expected a field"},
+		{"binds[(key=q]", 12, "expected )"},
+		{"binds[key~(]", 10, "invalid regular expression"},
+		{"binds[line>ten]", 11, "needs a number"},
+		{`binds[key="q]`, 10, "unterminated string"},
+		{"windowrules[match.clas=kitty]", 12, `did you mean "match.class"`},
+		{"options[match.class=kitty]", 8, "unknown field of options"},
+		{"binds[key=q] x", 13, "unexpected text"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		var te *TokenError
+		if !errors.As(err, &te) {
+			t.Errorf("ParseQuery(%q) = %v, want a TokenError", tt.query, err)
+			continue
+		}
+		if te.Offset != tt.offset || !strings.Contains(te.Message, tt.msg) {
+			t.Errorf("ParseQuery(%q) = %q at %d, want %q at %d", tt.query, te.Message, te.Offset, tt.msg, tt.offset)
+		}
+	}
+}
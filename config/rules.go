@@ -0,0 +1,452 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RuleArg is the grammar of a window rule argument
+type RuleArg int
+
+const (
+	RuleArgNone      RuleArg = iota
+	RuleArgFlag              // optional on/off, e.g. "noblur" or "noblur 0"
+	RuleArgWorkspace         // workspace specifier and optional "silent"
+	RuleArgSize              // "W H" in pixels or percent, optionally prefixed with < or >
+	RuleArgPixels            // "W H" in pixels
+	RuleArgMove              // "[onscreen] [cursor] X Y"
+	RuleArgOpacity           // "a [override] [b [override] [c [override]]]"
+	RuleArgColors            // one or two gradients for active and inactive borders
+	RuleArgInt
+	RuleArgFloat
+	RuleArgEnum   // one of WindowRuleDef.Values
+	RuleArgString // free-form text
+)
+
+// WindowRuleDef describes a window rule and the argument it takes
+type WindowRuleDef struct {
+	Name        string
+	Arg         RuleArg
+	Values      []string // allowed values for RuleArgEnum
+	Dynamic     bool     // re-evaluated whenever the window's properties change
+	Description string
+}
+
+var windowRules = []WindowRuleDef{
+	// Static rules, evaluated once when the window opens
+	{Name: "float", Description: "Float the window"},
+	{Name: "tile", Description: "Tile the window"},
+	{Name: "fullscreen", Description: "Open the window fullscreen"},
+	{Name: "maximize", Description: "Open the window maximized"},
+	{Name: "persistentsize", Description: "Remember the size of floating windows across launches"},
+	{Name: "fullscreenstate", Arg: RuleArgString, Description: "Set the internal and client fullscreen state"},
+	{Name: "move", Arg: RuleArgMove, Description: "Move a floating window, e.g. move cursor -50% -50%"},
+	{Name: "size", Arg: RuleArgSize, Description: "Resize a floating window, e.g. size 50% 50%"},
+	{Name: "center", Arg: RuleArgEnum, Values: []string{"1"}, Description: "Center a floating window, 1 respects reserved areas"},
+	{Name: "pseudo", Description: "Pseudotile the window"},
+	{Name: "monitor", Arg: RuleArgString, Description: "Open the window on a monitor"},
+	{Name: "workspace", Arg: RuleArgWorkspace, Description: "Open the window on a workspace, add silent to not follow it"},
+	{Name: "noinitialfocus", Description: "Do not focus the window when it opens"},
+	{Name: "pin", Description: "Pin the window to all workspaces"},
+	{Name: "unset", Arg: RuleArgString, Description: "Remove rules previously set for the matched windows"},
+	{Name: "nomaxsize", Description: "Remove the max size limit of the window"},
+	{Name: "stayfocused", Description: "Keep focus on the window while it is visible"},
+	{Name: "group", Arg: RuleArgString, Description: "Set the group behaviour of the window"},
+	{Name: "suppressevent", Arg: RuleArgString, Description: "Ignore fullscreen, maximize, activate or activatefocus requests"},
+	{Name: "content", Arg: RuleArgEnum, Values: []string{"none", "photo", "video", "game"}, Description: "Set the content type"},
+	{Name: "noclosefor", Arg: RuleArgInt, Description: "Ignore close requests for the first milliseconds"},
+
+	// Dynamic rules
+	{Name: "animation", Arg: RuleArgString, Dynamic: true, Description: "Force an animation style, e.g. popin 80%"},
+	{Name: "bordercolor", Arg: RuleArgColors, Dynamic: true, Description: "Border colors for the active and inactive window"},
+	{Name: "idleinhibit", Arg: RuleArgEnum, Values: []string{"none", "always", "focus", "fullscreen"}, Dynamic: true,
+		Description: "Inhibit idling while the window is open"},
+	{Name: "opacity", Arg: RuleArgOpacity, Dynamic: true, Description: "Active, inactive and fullscreen opacity, optionally with override"},
+	{Name: "tag", Arg: RuleArgString, Dynamic: true, Description: "Add (+tag), remove (-tag) or toggle a tag"},
+	{Name: "maxsize", Arg: RuleArgPixels, Dynamic: true, Description: "Maximum size of a floating window"},
+	{Name: "minsize", Arg: RuleArgPixels, Dynamic: true, Description: "Minimum size of a floating window"},
+	{Name: "bordersize", Arg: RuleArgInt, Dynamic: true, Description: "Border size in pixels"},
+	{Name: "rounding", Arg: RuleArgInt, Dynamic: true, Description: "Rounded corner radius in pixels"},
+	{Name: "roundingpower", Arg: RuleArgFloat, Dynamic: true, Description: "Curve of the rounded corners"},
+	{Name: "allowsinput", Arg: RuleArgFlag, Dynamic: true, Description: "Let an XWayland window receive input even if it cannot"},
+	{Name: "dimaround", Arg: RuleArgFlag, Dynamic: true, Description: "Dim everything around the window"},
+	{Name: "decorate", Arg: RuleArgFlag, Dynamic: true, Description: "Draw window decorations"},
+	{Name: "focusonactivate", Arg: RuleArgFlag, Dynamic: true, Description: "Focus the window when it requests activation"},
+	{Name: "keepaspectratio", Arg: RuleArgFlag, Dynamic: true, Description: "Keep the aspect ratio when resizing"},
+	{Name: "nearestneighbor", Arg: RuleArgFlag, Dynamic: true, Description: "Use nearest neighbour filtering"},
+	{Name: "noanim", Arg: RuleArgFlag, Dynamic: true, Description: "Disable animations"},
+	{Name: "noblur", Arg: RuleArgFlag, Dynamic: true, Description: "Disable blur"},
+	{Name: "noborder", Arg: RuleArgFlag, Dynamic: true, Description: "Disable borders"},
+	{Name: "nodim", Arg: RuleArgFlag, Dynamic: true, Description: "Disable dimming"},
+	{Name: "nofocus", Arg: RuleArgFlag, Dynamic: true, Description: "Never focus the window"},
+	{Name: "nofollowmouse", Arg: RuleArgFlag, Dynamic: true, Description: "Do not focus the window when hovered"},
+	{Name: "norounding", Arg: RuleArgFlag, Dynamic: true, Description: "Disable rounded corners"},
+	{Name: "noshadow", Arg: RuleArgFlag, Dynamic: true, Description: "Disable shadows"},
+	{Name: "noshortcutsinhibit", Arg: RuleArgFlag, Dynamic: true, Description: "Disallow the window to inhibit shortcuts"},
+	{Name: "noscreenshare", Arg: RuleArgFlag, Dynamic: true, Description: "Hide the window from screen sharing"},
+	{Name: "novrr", Arg: RuleArgFlag, Dynamic: true, Description: "Disable variable refresh rate"},
+	{Name: "opaque", Arg: RuleArgFlag, Dynamic: true, Description: "Force the window to be opaque"},
+	{Name: "forcergbx", Arg: RuleArgFlag, Dynamic: true, Description: "Ignore the alpha channel of the window"},
+	{Name: "syncfullscreen", Arg: RuleArgFlag, Dynamic: true, Description: "Sync the fullscreen state with the client"},
+	{Name: "immediate", Arg: RuleArgFlag, Dynamic: true, Description: "Allow tearing for the window"},
+	{Name: "xray", Arg: RuleArgFlag, Dynamic: true, Description: "Blur only the wallpaper behind the window"},
+	{Name: "renderunfocused", Arg: RuleArgFlag, Dynamic: true, Description: "Keep rendering the window when unfocused"},
+	{Name: "scrollmouse", Arg: RuleArgFloat, Dynamic: true, Description: "Mouse scroll factor"},
+	{Name: "scrolltouchpad", Arg: RuleArgFloat, Dynamic: true, Description: "Touchpad scroll factor"},
+}
+
+var windowRuleIndex = make(map[string]WindowRuleDef)
+
+func init() {
+	for _, r := range windowRules {
+		windowRuleIndex[r.Name] = r
+	}
+}
+
+// LookupWindowRule returns the catalog entry for a rule name
+func LookupWindowRule(name string) (WindowRuleDef, bool) {
+	r, ok := windowRuleIndex[strings.ToLower(strings.TrimSpace(name))]
+	return r, ok
+}
+
+// WindowRules returns all known window rules sorted by name
+func WindowRules() []WindowRuleDef {
+	list := append([]WindowRuleDef(nil), windowRules...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Keyword returns the config keyword the rule is written with
+func (r WindowRule) Keyword() string {
+	if r.Version == 2 {
+		return "windowrulev2"
+	}
+	return "windowrule"
+}
+
+// Text returns the rule as written after the keyword, e.g.
+// "size 50% 50%, class:^(kitty)$"
+func (r WindowRule) Text() string {
+	return strings.TrimSpace(r.Rule+" "+r.Value) + ", " + r.Target
+}
+
+// Validate checks the rule name, its arguments and the window matcher
+func (r WindowRule) Validate() error {
+	return ValidateWindowRule(r.Keyword(), r.Text())
+}
+
+// matcherField is the value type of a windowrulev2 matcher field
+type matcherField int
+
+const (
+	matchRegex matcherField = iota
+	matchBool
+	matchWorkspace
+	matchString
+)
+
+var matcherFields = map[string]matcherField{
+	"class": matchRegex, "title": matchRegex, "initialClass": matchRegex, "initialTitle": matchRegex,
+	"tag": matchString, "xdgTag": matchString,
+	"xwayland": matchBool, "floating": matchBool, "fullscreen": matchBool, "pinned": matchBool,
+	"focus": matchBool, "group": matchBool, "modal": matchBool,
+	"fullscreenstate": matchString, "content": matchString,
+	"workspace": matchWorkspace, "onworkspace": matchString,
+}
+
+// TokenError is a validation error for one token of a longer value
+type TokenError struct {
+	Token   string
+	Offset  int // byte offset of Token in the validated value
+	Message string
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("%s (at %q, column %d)", e.Message, e.Token, e.Offset+1)
+}
+
+// token is a piece of a value together with its byte offset
+type token struct {
+	text   string
+	offset int
+}
+
+// splitTokens splits s on whitespace, keeping offsets relative to base
+func splitTokens(s string, base int) []token {
+	var tokens []token
+	start := -1
+	for i, r := range s + " " {
+		if r == ' ' || r == '\t' {
+			if start >= 0 {
+				tokens = append(tokens, token{s[start:i], base + start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// ValidateWindowRule checks a windowrule or windowrulev2 value such as
+// "size 50% 50%, class:^(kitty)$". Errors are *TokenError values pointing
+// at the offending token.
+func ValidateWindowRule(keyword, value string) error {
+	comma := strings.Index(value, ",")
+	if comma < 0 {
+		return &TokenError{value, 0, "missing the window matcher after a comma"}
+	}
+
+	tokens := splitTokens(value[:comma], 0)
+	if len(tokens) == 0 {
+		return &TokenError{"", 0, "rule is empty"}
+	}
+	name := tokens[0]
+	def, ok := LookupWindowRule(name.text)
+	if !ok {
+		return &TokenError{name.text, name.offset, "unknown window rule"}
+	}
+	if err := def.checkArgs(name, tokens[1:]); err != nil {
+		return err
+	}
+
+	if keyword == "windowrulev2" {
+		return checkMatcherV2(value[comma+1:], comma+1)
+	}
+	return checkMatcherV1(value[comma+1:], comma+1)
+}
+
+func (r WindowRuleDef) checkArgs(name token, args []token) error {
+	argErr := func(t token, msg string) error { return &TokenError{t.text, t.offset, msg} }
+	need := func(n int, msg string) error {
+		if len(args) < n {
+			return argErr(name, msg)
+		}
+		if len(args) > n {
+			return argErr(args[n], "unexpected argument")
+		}
+		return nil
+	}
+
+	switch r.Arg {
+	case RuleArgNone:
+		if len(args) > 0 {
+			return argErr(args[0], r.Name+" takes no argument")
+		}
+	case RuleArgFlag:
+		if len(args) > 1 {
+			return argErr(args[1], "unexpected argument")
+		}
+		if len(args) == 1 {
+			if _, ok := parseHyprBool(args[0].text); !ok {
+				return argErr(args[0], "must be on or off")
+			}
+		}
+	case RuleArgWorkspace:
+		if len(args) == 0 || len(args) > 2 {
+			return need(1, "needs a workspace")
+		}
+		if msg := checkWorkspace(args[0].text); msg != "" {
+			return argErr(args[0], msg)
+		}
+		if len(args) == 2 && args[1].text != "silent" {
+			return argErr(args[1], `only "silent" may follow the workspace`)
+		}
+	case RuleArgSize, RuleArgPixels:
+		if err := need(2, "needs a width and a height"); err != nil {
+			return err
+		}
+		for _, a := range args {
+			if r.Arg == RuleArgSize && !ruleLength.MatchString(a.text) {
+				return argErr(a, "must be a number of pixels or a percentage")
+			}
+			if _, err := strconv.Atoi(a.text); r.Arg == RuleArgPixels && err != nil {
+				return argErr(a, "must be a number of pixels")
+			}
+		}
+	case RuleArgMove:
+		coords := args
+		for len(coords) > 0 && (coords[0].text == "onscreen" || coords[0].text == "cursor") {
+			coords = coords[1:]
+		}
+		if len(coords) != 2 {
+			if len(coords) > 2 {
+				return argErr(coords[2], "unexpected argument")
+			}
+			return argErr(name, "needs an X and a Y position")
+		}
+		for _, c := range coords {
+			if !isMoveExpression(c.text) {
+				return argErr(c, "must be a position in pixels, a percentage or an expression with w and h")
+			}
+		}
+	case RuleArgOpacity:
+		values := 0
+		for i, a := range args {
+			if a.text == "override" && i > 0 && args[i-1].text != "override" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(a.text, 64); err != nil || v < 0 {
+				return argErr(a, "must be an opacity like 0.8, optionally followed by override")
+			}
+			values++
+		}
+		if values == 0 {
+			return argErr(name, "needs at least one opacity value")
+		}
+		if values > 3 {
+			return argErr(args[len(args)-1], "takes at most three opacity values")
+		}
+	case RuleArgColors:
+		if len(args) == 0 {
+			return argErr(name, "needs a border color")
+		}
+		for _, a := range args {
+			if angle := strings.TrimSuffix(a.text, "deg"); angle != a.text {
+				if _, err := strconv.Atoi(angle); err == nil {
+					continue
+				}
+			}
+			if err := ValidateColor("bordercolor", a.text); err != nil {
+				return argErr(a, "not a color or angle")
+			}
+		}
+	case RuleArgInt:
+		if err := need(1, "needs a number"); err != nil {
+			return err
+		}
+		if _, err := strconv.Atoi(args[0].text); err != nil {
+			return argErr(args[0], "must be a whole number")
+		}
+	case RuleArgFloat:
+		if err := need(1, "needs a number"); err != nil {
+			return err
+		}
+		if _, err := strconv.ParseFloat(args[0].text, 64); err != nil {
+			return argErr(args[0], "must be a number")
+		}
+	case RuleArgEnum:
+		if err := need(1, "needs one of: "+strings.Join(r.Values, ", ")); err != nil {
+			return err
+		}
+		if !containsString(r.Values, args[0].text) {
+			return argErr(args[0], "must be one of: "+strings.Join(r.Values, ", "))
+		}
+	case RuleArgString:
+		if len(args) == 0 {
+			return argErr(name, "needs an argument")
+		}
+	}
+	return nil
+}
+
+var (
+	ruleLength     = regexp.MustCompile(`^[<>]?\d+(\.\d+)?%?$`)
+	moveExpression = regexp.MustCompile(`^[-+*/()0-9.%wh]+$`)
+	fieldName      = regexp.MustCompile(`^[A-Za-z]+$`)
+)
+
+func isMoveExpression(s string) bool {
+	return moveExpression.MatchString(s) && strings.ContainsAny(s, "0123456789wh")
+}
+
+// splitMatchers splits comma separated field:value matchers. Commas inside
+// a value are allowed as long as the next piece is not a field.
+func splitMatchers(s string, base int) []token {
+	var parts []token
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && s[i] != ',' {
+			continue
+		}
+		piece := s[start:i]
+		trimmed := strings.TrimLeft(piece, " \t")
+		field, _, hasColon := strings.Cut(trimmed, ":")
+		if _, known := matcherFields[field]; !known && len(parts) > 0 && (!hasColon || !fieldName.MatchString(field)) {
+			// continuation of the previous value, e.g. a regex with a comma
+			parts[len(parts)-1].text += "," + piece
+		} else {
+			parts = append(parts, token{strings.TrimRight(trimmed, " \t"), base + start + len(piece) - len(trimmed)})
+		}
+		start = i + 1
+	}
+	return parts
+}
+
+// checkMatcherV2 validates the matchers of a windowrulev2
+func checkMatcherV2(s string, base int) error {
+	for _, p := range splitMatchers(s, base) {
+		field, value, ok := strings.Cut(p.text, ":")
+		if !ok || field == "" {
+			return &TokenError{p.text, p.offset, "matcher must be field:value"}
+		}
+		kind, known := matcherFields[field]
+		if !known {
+			msg := "unknown matcher field"
+			if s := suggestMatcherField(field); s != "" {
+				msg += fmt.Sprintf(", did you mean %q?", s)
+			}
+			return &TokenError{field, p.offset, msg}
+		}
+		valueToken := token{value, p.offset + len(field) + 1}
+		if msg := checkMatcherValue(kind, value); msg != "" {
+			return &TokenError{valueToken.text, valueToken.offset, msg}
+		}
+	}
+	return nil
+}
+
+func checkMatcherValue(kind matcherField, value string) string {
+	switch kind {
+	case matchRegex:
+		if err := ValidateRegex("", strings.TrimPrefix(value, "negative:")); err != nil {
+			return "invalid regular expression"
+		}
+	case matchBool:
+		if value != "0" && value != "1" {
+			return "must be 0 or 1"
+		}
+	case matchWorkspace:
+		if msg := checkWorkspace(value); msg != "" {
+			return msg
+		}
+	case matchString:
+		if value == "" {
+			return "value is empty"
+		}
+	}
+	return ""
+}
+
+func suggestMatcherField(field string) string {
+	best, bestDist := "", 3
+	for name := range matcherFields {
+		if d := levenshtein(strings.ToLower(field), strings.ToLower(name)); d < bestDist || (d == bestDist && best != "" && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// checkMatcherV1 validates a windowrule matcher: a class regex or title:regex
+func checkMatcherV1(s string, base int) error {
+	trimmed := strings.TrimSpace(s)
+	offset := base + strings.Index(s, trimmed)
+	if trimmed == "" {
+		return &TokenError{"", base, "window matcher is empty"}
+	}
+	pattern := trimmed
+	for _, prefix := range []string{"title:", "class:"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			pattern = trimmed[len(prefix):]
+			offset += len(prefix)
+		}
+	}
+	if err := ValidateRegex("", pattern); err != nil {
+		return &TokenError{pattern, offset, "invalid regular expression"}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import "testing"
+
+func TestValidateWindowRule(t *testing.T) {
+	tests := []struct {
+		keyword   string
+		value     string
+		wantToken string // "" means the rule is valid
+		wantCol   int
+	}{
+		{"windowrulev2", "float, class:^(pavucontrol)$", "", 0},
+		{"windowrulev2", "size 50% 50%, class:^(kitty)$", "", 0},
+		{"windowrulev2", "size <800 >600, floating:1", "", 0},
+		{"windowrulev2", "move cursor -50% -50%, class:^(kitty)$", "", 0},
+		{"windowrulev2", "move onscreen 100%-w-10 10, title:^(pip)$", "", 0},
+		{"windowrulev2", "opacity 0.8 override 0.5, class:^(kitty)$", "", 0},
+		{"windowrulev2", "bordercolor rgb(FF0000) rgb(880808), fullscreen:1", "", 0},
+		{"windowrulev2", "bordercolor rgba(33ccffee) rgba(00ff99ee) 45deg, pinned:1", "", 0},
+		{"windowrulev2", "idleinhibit fullscreen, class:^(firefox)$", "", 0},
+		{"windowrulev2", "workspace 3 silent, class:^(discord)$", "", 0},
+		{"windowrulev2", "workspace special:term, initialClass:^(kitty)$", "", 0},
+		{"windowrulev2", "noblur, title:^(a, b)$", "", 0},
+		{"windowrulev2", "noblur 1, class:negative:^(kitty)$", "", 0},
+		{"windowrule", "float, ^(pavucontrol)$", "", 0},
+		{"windowrule", "float, title:^(Picture-in-Picture)$", "", 0},
+
+		{"windowrulev2", "floaty, class:^(kitty)$", "floaty", 1},
+		{"windowrulev2", "size 50% tall, class:^(kitty)$", "tall", 10},
+		{"windowrulev2", "size 50%, class:^(kitty)$", "size", 1},
+		{"windowrulev2", "opacity 0.8 0.5 0.4 0.3, class:^(kitty)$", "0.3", 21},
+		{"windowrulev2", "opacity high, class:^(kitty)$", "high", 9},
+		{"windowrulev2", "bordercolor red, class:^(kitty)$", "red", 13},
+		{"windowrulev2", "idleinhibit sometimes, class:^(kitty)$", "sometimes", 13},
+		{"windowrulev2", "workspace 3 loud, class:^(kitty)$", "loud", 13},
+		{"windowrulev2", "float 1, class:^(kitty)$", "1", 7},
+		{"windowrulev2", "float, class:^(kitty$", "^(kitty$", 14},
+		{"windowrulev2", "float, clas:^(kitty)$", "clas", 8},
+		{"windowrulev2", "float, floating:yes", "yes", 17},
+		{"windowrulev2", "float", "float", 1},
+		{"windowrule", "float, title:^(kitty", "^(kitty", 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := ValidateWindowRule(tt.keyword, tt.value)
+			if tt.wantToken == "" {
+				if err != nil {
+					t.Fatalf("ValidateWindowRule() = %v, want nil", err)
+				}
+				return
+			}
+			terr, ok := err.(*TokenError)
+			if !ok {
+				t.Fatalf("ValidateWindowRule() = %v, want a TokenError", err)
+			}
+			if terr.Token != tt.wantToken || terr.Offset+1 != tt.wantCol {
+				t.Errorf("error at %q column %d, want %q column %d (%v)",
+					terr.Token, terr.Offset+1, tt.wantToken, tt.wantCol, err)
+			}
+		})
+	}
+}
+
+func TestWindowRuleValidate(t *testing.T) {
+	rule := WindowRule{Rule: "opacity", Value: "0.9 0.8", Target: "class:^(kitty)$", Version: 2}
+	if err := rule.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	rule.Rule = "opacityy"
+	if err := rule.Validate(); err == nil {
+		t.Error("Validate() accepted an unknown rule")
+	}
+}
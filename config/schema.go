@@ -0,0 +1,404 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OptionType is the value type of a Hyprland option
+type OptionType int
+
+const (
+	TypeBool OptionType = iota
+	TypeInt
+	TypeFloat
+	TypeString
+	TypeVec2     // two numbers, e.g. "10 -5"
+	TypeColor    // a single color
+	TypeGradient // one or more colors and an optional angle
+	TypeRegex
+	TypeGaps // one to four numbers, CSS style: top, right, bottom, left
+)
+
+func (t OptionType) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeVec2:
+		return "vec2"
+	case TypeColor:
+		return "color"
+	case TypeGradient:
+		return "gradient"
+	case TypeRegex:
+		return "regex"
+	case TypeGaps:
+		return "gaps"
+	}
+	return "string"
+}
+
+// Option describes a single Hyprland option. The parser, validators, writer,
+// UI and docs all read option knowledge from here, so supporting a new
+// option only needs a registry entry.
+type Option struct {
+	Path         string // full path, e.g. "decoration:blur:size"
+	Type         OptionType
+	Default      string
+	Min, Max     float64  // numeric range, unused when both are zero
+	Values       []string // allowed values for enum-like options
+	Label        string   // name shown in the UI
+	Description  string
+	Restart      bool   // only takes effect after restarting Hyprland
+	AddedIn      string // Hyprland version that introduced the option
+	DeprecatedIn string // Hyprland version that removed or renamed it
+}
+
+// Category returns the path without the option name, e.g. "decoration:blur"
+func (o Option) Category() string {
+	if i := strings.LastIndex(o.Path, ":"); i >= 0 {
+		return o.Path[:i]
+	}
+	return ""
+}
+
+// Name returns the last path component, e.g. "size"
+func (o Option) Name() string {
+	return o.Path[strings.LastIndex(o.Path, ":")+1:]
+}
+
+// HasRange reports whether Min and Max apply
+func (o Option) HasRange() bool {
+	return o.Min != 0 || o.Max != 0
+}
+
+// Validate checks value against the option type, range and allowed values
+func (o Option) Validate(value string) error {
+	value = strings.TrimSpace(value)
+	switch o.Type {
+	case TypeBool:
+		if _, ok := parseHyprBool(value); !ok {
+			return &ValidationError{o.Path, value, "must be true or false"}
+		}
+	case TypeInt:
+		if o.HasRange() {
+			return ValidateInt(o.Path, value, int(o.Min), int(o.Max))
+		}
+		if _, err := strconv.Atoi(value); err != nil {
+			return &ValidationError{o.Path, value, "must be a number"}
+		}
+	case TypeFloat:
+		if o.HasRange() {
+			return ValidateFloat(o.Path, value, o.Min, o.Max)
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return &ValidationError{o.Path, value, "must be a number"}
+		}
+	case TypeString:
+		// An empty value keeps the libinput/xkb default where there is one
+		if len(o.Values) > 0 && !(value == "" && o.Default == "") {
+			return ValidateEnum(o.Path, value, o.Values)
+		}
+	case TypeVec2:
+		return ValidateVec2(o.Path, value)
+	case TypeColor:
+		return ValidateColor(o.Path, value)
+	case TypeGradient:
+		return ValidateGradient(o.Path, value)
+	case TypeRegex:
+		return ValidateRegex(o.Path, value)
+	case TypeGaps:
+		return ValidateGaps(o.Path, value, int(o.Min), int(o.Max))
+	}
+	return nil
+}
+
+// Constraint describes the accepted values for humans, e.g. "0–1"
+func (o Option) Constraint() string {
+	switch {
+	case len(o.Values) > 0:
+		return strings.Join(o.Values, ", ")
+	case o.HasRange():
+		return fmt.Sprintf("%g–%g", o.Min, o.Max)
+	}
+	return ""
+}
+
+var registry = []Option{
+	// general
+	{Path: "general:border_size", Type: TypeInt, Default: "1", Min: 0, Max: 100,
+		Label: "Border Size", Description: "Size of the border around windows in pixels"},
+	{Path: "general:gaps_in", Type: TypeGaps, Default: "5", Min: 0, Max: 500,
+		Label: "Gaps In", Description: "Gaps between windows in pixels"},
+	{Path: "general:gaps_out", Type: TypeGaps, Default: "20", Min: 0, Max: 500,
+		Label: "Gaps Out", Description: "Gaps between windows and monitor edges in pixels"},
+	{Path: "general:cursor_inactive_timeout", Type: TypeInt, Default: "0", Min: 0, Max: 3600,
+		Label: "Cursor Inactive Timeout", Description: "Seconds of inactivity before the cursor is hidden, 0 disables",
+		DeprecatedIn: "0.39.0"},
+	{Path: "general:layout", Type: TypeString, Default: "dwindle", Values: []string{"dwindle", "master"},
+		Label: "Layout", Description: "Tiling layout to use"},
+	{Path: "general:no_cursor_warps", Type: TypeBool, Default: "false",
+		Label: "No Cursor Warps", Description: "Do not warp the cursor when focus changes", DeprecatedIn: "0.39.0"},
+	{Path: "general:no_focus_fallback", Type: TypeBool, Default: "false",
+		Label: "No Focus Fallback", Description: "Do not fall back to the next window when moving focus in a direction without one"},
+	{Path: "general:sensitivity", Type: TypeFloat, Default: "1.0", Min: -1, Max: 1,
+		Label: "Sensitivity", Description: "Mouse sensitivity multiplier", DeprecatedIn: "0.32.0"},
+	{Path: "general:apply_sens_to_raw", Type: TypeBool, Default: "false",
+		Label: "Apply Sensitivity to Raw", Description: "Apply the sensitivity to raw mouse output as well",
+		DeprecatedIn: "0.32.0"},
+	{Path: "general:cursor_zoom_factor", Type: TypeFloat, Default: "1.0", Min: 1, Max: 10,
+		Label: "Cursor Zoom Factor", Description: "Factor to zoom the cursor by"},
+	{Path: "general:resize_on_border", Type: TypeBool, Default: "false",
+		Label: "Resize on Border", Description: "Resize windows by dragging their borders and gaps"},
+	{Path: "general:extend_border_grab_area", Type: TypeInt, Default: "15", Min: 0, Max: 100,
+		Label: "Extend Border Grab Area", Description: "Extra area around the border that can be grabbed for resizing"},
+	{Path: "general:hover_icon_on_border", Type: TypeBool, Default: "true",
+		Label: "Hover Icon on Border", Description: "Show a resize cursor when hovering over borders"},
+	{Path: "general:allow_tearing", Type: TypeBool, Default: "false",
+		Label: "Allow Tearing", Description: "Master switch for allowing tearing to occur"},
+	{Path: "general:col.active_border", Type: TypeGradient, Default: "0xffffffff",
+		Label: "Active Border Color", Description: "Border color of the focused window"},
+	{Path: "general:col.inactive_border", Type: TypeGradient, Default: "0xff444444",
+		Label: "Inactive Border Color", Description: "Border color of unfocused windows"},
+
+	// decoration
+	{Path: "decoration:rounding", Type: TypeInt, Default: "0", Min: 0, Max: 1000,
+		Label: "Rounding", Description: "Rounded corner radius in pixels"},
+	{Path: "decoration:blur", Type: TypeBool, Default: "true",
+		Label: "Blur Enabled", Description: "Enable background blur", DeprecatedIn: "0.28.0"},
+	{Path: "decoration:blur_size", Type: TypeInt, Default: "8", Min: 1, Max: 1000,
+		Label: "Blur Size", Description: "Blur radius", DeprecatedIn: "0.28.0"},
+	{Path: "decoration:blur_passes", Type: TypeInt, Default: "1", Min: 1, Max: 10,
+		Label: "Blur Passes", Description: "Number of blur passes", DeprecatedIn: "0.28.0"},
+	{Path: "decoration:active_opacity", Type: TypeFloat, Default: "1.0", Min: 0, Max: 1,
+		Label: "Active Opacity", Description: "Opacity of the focused window"},
+	{Path: "decoration:inactive_opacity", Type: TypeFloat, Default: "1.0", Min: 0, Max: 1,
+		Label: "Inactive Opacity", Description: "Opacity of unfocused windows"},
+	{Path: "decoration:dim_inactive", Type: TypeBool, Default: "false",
+		Label: "Dim Inactive", Description: "Dim unfocused windows"},
+	{Path: "decoration:drop_shadow", Type: TypeBool, Default: "true",
+		Label: "Drop Shadow", Description: "Draw shadows behind windows", DeprecatedIn: "0.42.0"},
+	{Path: "decoration:shadow_range", Type: TypeInt, Default: "4", Min: 0, Max: 100,
+		Label: "Shadow Range", Description: "Shadow range in pixels", DeprecatedIn: "0.42.0"},
+	{Path: "decoration:shadow_offset", Type: TypeVec2, Default: "0 0",
+		Label: "Shadow Offset", Description: "Shadow offset in pixels", DeprecatedIn: "0.42.0"},
+	{Path: "decoration:col.shadow", Type: TypeColor, Default: "0xee1a1a1a",
+		Label: "Shadow Color", Description: "Color of the shadow", DeprecatedIn: "0.42.0"},
+	{Path: "decoration:blur:enabled", Type: TypeBool, Default: "true", AddedIn: "0.28.0",
+		Label: "Blur Enabled", Description: "Enable background blur"},
+	{Path: "decoration:blur:size", Type: TypeInt, Default: "8", Min: 1, Max: 1000, AddedIn: "0.28.0",
+		Label: "Blur Size", Description: "Blur radius"},
+	{Path: "decoration:blur:passes", Type: TypeInt, Default: "1", Min: 1, Max: 10, AddedIn: "0.28.0",
+		Label: "Blur Passes", Description: "Number of blur passes"},
+	{Path: "decoration:shadow:enabled", Type: TypeBool, Default: "true", AddedIn: "0.42.0",
+		Label: "Shadow Enabled", Description: "Draw shadows behind windows"},
+	{Path: "decoration:shadow:range", Type: TypeInt, Default: "4", Min: 0, Max: 100, AddedIn: "0.42.0",
+		Label: "Shadow Range", Description: "Shadow range in pixels"},
+	{Path: "decoration:shadow:color", Type: TypeColor, Default: "0xee1a1a1a", AddedIn: "0.42.0",
+		Label: "Shadow Color", Description: "Color of the shadow"},
+	{Path: "decoration:shadow:offset", Type: TypeVec2, Default: "0 0", AddedIn: "0.42.0",
+		Label: "Shadow Offset", Description: "Shadow offset in pixels"},
+	{Path: "decoration:dim_strength", Type: TypeFloat, Default: "0.5", Min: 0, Max: 1,
+		Label: "Dim Strength", Description: "How much to dim unfocused windows"},
+
+	// animations
+	{Path: "animations:enabled", Type: TypeBool, Default: "true",
+		Label: "Enabled", Description: "Enable animations"},
+
+	// input
+	{Path: "input:kb_model", Type: TypeString, Label: "Keyboard Model", Description: "XKB keyboard model"},
+	{Path: "input:kb_layout", Type: TypeString, Default: "us", Label: "Keyboard Layout", Description: "XKB keyboard layout"},
+	{Path: "input:kb_variant", Type: TypeString, Label: "Keyboard Variant", Description: "XKB layout variant"},
+	{Path: "input:kb_options", Type: TypeString, Label: "Keyboard Options", Description: "XKB options, e.g. caps:escape"},
+	{Path: "input:numlock_by_default", Type: TypeBool, Default: "false",
+		Label: "NumLock by Default", Description: "Engage numlock on startup"},
+	{Path: "input:sensitivity", Type: TypeFloat, Default: "0", Min: -1, Max: 1,
+		Label: "Sensitivity", Description: "Pointer speed adjustment, negative values slow it down"},
+	{Path: "input:scroll_method", Type: TypeString, Values: []string{"2fg", "edge", "on_button_down", "no_scroll"},
+		Label: "Scroll Method", Description: "Scroll method of pointer devices"},
+	{Path: "input:scroll_button", Type: TypeInt, Default: "0", Min: 0, Max: 1000,
+		Label: "Scroll Button", Description: "Button used for on_button_down scrolling, 0 uses the default"},
+	{Path: "input:scroll_button_lock", Type: TypeBool, Default: "false",
+		Label: "Scroll Button Lock", Description: "Toggle on_button_down scrolling instead of holding the button"},
+	{Path: "input:scroll_factor", Type: TypeFloat, Default: "1.0", Min: 0, Max: 100,
+		Label: "Scroll Factor", Description: "Multiplier applied to scroll movement"},
+	{Path: "input:follow_mouse", Type: TypeInt, Default: "1", Min: 0, Max: 3,
+		Label: "Follow Mouse", Description: "How window focus follows the cursor"},
+	{Path: "input:mouse_refocus", Type: TypeBool, Default: "true",
+		Label: "Mouse Refocus", Description: "Refocus the window under the cursor when it crosses window borders"},
+
+	// input:touchpad
+	{Path: "input:touchpad:disable_while_typing", Type: TypeBool, Default: "true",
+		Label: "Disable While Typing", Description: "Disable the touchpad while typing"},
+	{Path: "input:touchpad:natural_scroll", Type: TypeBool, Default: "false",
+		Label: "Natural Scroll", Description: "Invert touchpad scrolling"},
+	{Path: "input:touchpad:scroll_factor", Type: TypeFloat, Default: "1.0", Min: 0, Max: 100,
+		Label: "Touchpad Scroll Factor", Description: "Multiplier applied to touchpad scrolling"},
+	{Path: "input:touchpad:tap-to-click", Type: TypeBool, Default: "true",
+		Label: "Tap to Click", Description: "Tapping the touchpad clicks"},
+	{Path: "input:touchpad:drag_lock", Type: TypeBool, Default: "false",
+		Label: "Drag Lock", Description: "Keep dragging for a moment after lifting the finger"},
+
+	// misc
+	{Path: "misc:disable_hyprland_logo", Type: TypeBool, Default: "false",
+		Label: "Disable Hyprland Logo", Description: "Hide the default wallpaper logo"},
+	{Path: "misc:disable_autoreload", Type: TypeBool, Default: "false",
+		Label: "Disable Autoreload", Description: "Do not reload the config when the file changes"},
+	{Path: "misc:disable_startup_drop", Type: TypeBool, Default: "false",
+		Label: "Disable Startup Drop", Description: "Skip the startup drop animation"},
+	{Path: "misc:vfr", Type: TypeBool, Default: "true",
+		Label: "Variable Frame Rate", Description: "Lower the frame rate when nothing changes on screen"},
+	{Path: "misc:vrr", Type: TypeInt, Default: "0", Min: 0, Max: 2,
+		Label: "Variable Refresh Rate", Description: "Adaptive sync: 0 off, 1 on, 2 fullscreen only"},
+	{Path: "misc:mouse_move_enables_dpms", Type: TypeBool, Default: "false",
+		Label: "Mouse Move Enables DPMS", Description: "Wake monitors on mouse movement"},
+	{Path: "misc:always_follow_on_dnd", Type: TypeBool, Default: "true",
+		Label: "Always Follow on DnD", Description: "Focus follows the cursor while dragging and dropping"},
+	{Path: "misc:layers_hog_keyboard_focus", Type: TypeBool, Default: "true",
+		Label: "Layers Hog Keyboard Focus", Description: "Layers with keyboard interactivity keep focus when the cursor leaves"},
+	{Path: "misc:animate_manual_resizes", Type: TypeBool, Default: "false",
+		Label: "Animate Manual Resizes", Description: "Animate resizes done with the mouse"},
+	{Path: "misc:enable_swallow", Type: TypeBool, Default: "false",
+		Label: "Enable Swallow", Description: "Let terminals swallow the windows they spawn"},
+	{Path: "misc:swallow_regex", Type: TypeRegex,
+		Label: "Swallow Regex", Description: "Class regex of windows that may swallow"},
+	{Path: "misc:focus_on_activate", Type: TypeBool, Default: "false",
+		Label: "Focus on Activate", Description: "Focus windows that request activation"},
+
+	// debug
+	{Path: "debug:disable_logs_in_disk", Type: TypeBool, Default: "true",
+		Label: "Disable Logs on Disk", Description: "Do not write the Hyprland log to disk"},
+	{Path: "debug:log_level", Type: TypeString,
+		Label: "Log Level", Description: "Verbosity of the Hyprland log"},
+
+	// xwayland
+	{Path: "xwayland:use_nearest_neighbor", Type: TypeBool, Default: "true",
+		Label: "Use Nearest Neighbor", Description: "Use nearest neighbor filtering for scaled XWayland apps"},
+	{Path: "xwayland:force_scale", Type: TypeFloat, Default: "0", Min: 0, Max: 10,
+		Label: "Force Scale", Description: "Scale to force on XWayland apps", Restart: true},
+
+	// opengl
+	{Path: "opengl:nvidia_patches", Type: TypeBool, Default: "false",
+		Label: "NVIDIA Patches", Description: "Work around NVIDIA driver issues", Restart: true},
+
+	// cursor
+	{Path: "cursor:hide_when_inactive", Type: TypeBool, Default: "false",
+		Label: "Hide When Inactive", Description: "Hide the cursor after a period of inactivity"},
+	{Path: "cursor:hide_timeout", Type: TypeInt, Default: "0", Min: 0, Max: 3600,
+		Label: "Hide Timeout", Description: "Seconds of inactivity before the cursor is hidden"},
+	{Path: "cursor:inactive_timeout", Type: TypeInt, Default: "0", Min: 0, Max: 3600, AddedIn: "0.39.0",
+		Label: "Inactive Timeout", Description: "Seconds of inactivity before the cursor is hidden, 0 disables"},
+	{Path: "cursor:no_warps", Type: TypeBool, Default: "false", AddedIn: "0.39.0",
+		Label: "No Warps", Description: "Do not warp the cursor when focus changes"},
+}
+
+var registryIndex = make(map[string]int)
+
+func init() {
+	for i, opt := range registry {
+		if _, dup := registryIndex[opt.Path]; dup {
+			panic(fmt.Sprintf("config: option %s registered twice", opt.Path))
+		}
+		registryIndex[opt.Path] = i
+	}
+}
+
+// Lookup returns the schema entry for a full option path
+func Lookup(path string) (Option, bool) {
+	i, ok := registryIndex[path]
+	if !ok {
+		return Option{}, false
+	}
+	return registry[i], true
+}
+
+// Options returns every registered option in registry order
+func Options() []Option {
+	return append([]Option(nil), registry...)
+}
+
+// SchemaVersion returns the newest Hyprland release the schema and the
+// migrations know about
+func SchemaVersion() string {
+	newest := ""
+	note := func(v string) {
+		if v != "" && compareVersions(v, newest) > 0 {
+			newest = v
+		}
+	}
+	for _, opt := range registry {
+		note(opt.AddedIn)
+		note(opt.DeprecatedIn)
+	}
+	for _, m := range migrations {
+		note(m.Since)
+	}
+	return newest
+}
+
+// OptionsIn returns the options of a category and its subcategories
+func OptionsIn(category string) []Option {
+	var opts []Option
+	for _, opt := range registry {
+		if opt.Category() == category || strings.HasPrefix(opt.Category(), category+":") {
+			opts = append(opts, opt)
+		}
+	}
+	return opts
+}
+
+// CompleteOption returns the option paths starting with prefix
+func CompleteOption(prefix string) []string {
+	var paths []string
+	for _, opt := range registry {
+		if strings.HasPrefix(opt.Path, prefix) {
+			paths = append(paths, opt.Path)
+		}
+	}
+	return paths
+}
+
+// CompleteSection returns the option categories starting with prefix,
+// including parents of nested ones such as "decoration"
+func CompleteSection(prefix string) []string {
+	seen := make(map[string]bool)
+	var sections []string
+	for _, opt := range registry {
+		parts := strings.Split(opt.Category(), ":")
+		for n := 1; n <= len(parts) && parts[0] != ""; n++ {
+			section := strings.Join(parts[:n], ":")
+			if !seen[section] && strings.HasPrefix(section, prefix) {
+				seen[section] = true
+				sections = append(sections, section)
+			}
+		}
+	}
+	sort.Strings(sections)
+	return sections
+}
+
+// CompleteValue returns the values of an option starting with prefix, for
+// booleans and options with a fixed set of values
+func CompleteValue(path, prefix string) []string {
+	opt, ok := Lookup(path)
+	if !ok {
+		return nil
+	}
+	values := opt.Values
+	if opt.Type == TypeBool {
+		values = []string{"true", "false"}
+	}
+	var matches []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			matches = append(matches, v)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypedFieldsAreRegistered(t *testing.T) {
+	for path := range optionFields {
+		if _, ok := Lookup(path); !ok {
+			t.Errorf("typed field %s has no schema entry", path)
+		}
+	}
+}
+
+func TestOptionValidate(t *testing.T) {
+	tests := []struct {
+		path    string
+		value   string
+		wantErr bool
+	}{
+		{"general:border_size", "2", false},
+		{"general:border_size", "-1", true},
+		{"general:border_size", "abc", true},
+		{"decoration:active_opacity", "0.9", false},
+		{"decoration:active_opacity", "7.5", true},
+		{"general:layout", "master", false},
+		{"general:layout", "spiral", true},
+		{"misc:vfr", "yes", false},
+		{"misc:vfr", "maybe", true},
+		{"input:sensitivity", "-0.5", false},
+		{"input:sensitivity", "1.5", true},
+		{"input:scroll_method", "on_button_down", false},
+		{"input:scroll_method", "", false},
+		{"input:scroll_method", "wheel", true},
+		{"general:col.active_border", "rgba(33ccffee) rgba(00ff99ee) 45deg", false},
+		{"general:gaps_out", "5", false},
+		{"general:gaps_out", "5,10,5,10", false},
+		{"general:gaps_out", "5 10", false},
+		{"general:gaps_out", "5,10,5,10,5", true},
+		{"general:gaps_out", "5,-1", true},
+		{"decoration:col.shadow", "rgba(1a1a1aee)", false},
+		{"decoration:col.shadow", "dark", true},
+		{"decoration:shadow_offset", "2 -2", false},
+		{"misc:swallow_regex", "^(kitty)$", false},
+		{"misc:swallow_regex", "^(kitty", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+"="+tt.value, func(t *testing.T) {
+			opt, ok := Lookup(tt.path)
+			if !ok {
+				t.Fatalf("Lookup(%q) failed", tt.path)
+			}
+			if err := opt.Validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetSetOption(t *testing.T) {
+	cfg := &HyprlandConfig{}
+
+	// Typed field
+	if err := cfg.SetOption("decoration:rounding", "8"); err != nil {
+		t.Fatalf("SetOption() error = %v", err)
+	}
+	if cfg.Decoration.Rounding != 8 || !cfg.IsSet("decoration:rounding") {
+		t.Errorf("Rounding = %d, set = %v", cfg.Decoration.Rounding, cfg.IsSet("decoration:rounding"))
+	}
+
+	// An unset typed field reports its default, instead of its zero value
+	if got, _ := cfg.GetOption("general:gaps_out"); got != "20" {
+		t.Errorf("GetOption() default = %q, want 20", got)
+	}
+
+	// Schema option without a typed field falls back to its default
+	if got, _ := cfg.GetOption("general:col.active_border"); got != "0xffffffff" {
+		t.Errorf("GetOption() default = %q", got)
+	}
+	cfg.SetOption("general:col.active_border", "rgba(33ccffee)")
+	if got, _ := cfg.GetOption("general:col.active_border"); got != "rgba(33ccffee)" {
+		t.Errorf("GetOption() = %q after SetOption", got)
+	}
+
+	if err := cfg.SetOption("general:nope", "1"); err == nil {
+		t.Error("SetOption() accepted an unknown option")
+	}
+}
+
+func TestCheckOption(t *testing.T) {
+	cfg := &HyprlandConfig{Variables: map[string]string{"gaps": "8"}}
+
+	tests := []struct {
+		path, value string
+		wantErr     string
+	}{
+		{"general:gaps_in", "8", ""},
+		{"general:gaps_in", "$gaps", ""},
+		{"decoration:rounding", "round", "must be"},
+		{"general:gap_in", "8", "did you mean general:gaps_in?"},
+		{"rounding", "8", "unknown option: rounding"},
+	}
+	for _, tt := range tests {
+		err := cfg.CheckOption(tt.path, tt.value)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("CheckOption(%q, %q) = %v", tt.path, tt.value, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("CheckOption(%q, %q) = %v, want %q", tt.path, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []string
+		want string
+	}{
+		{"option", CompleteOption("general:gaps"), "general:gaps_in general:gaps_out"},
+		{"section", CompleteSection("deco"), "decoration decoration:blur decoration:shadow"},
+		{"enum value", CompleteValue("general:layout", "d"), "dwindle"},
+		{"bool value", CompleteValue("misc:vfr", ""), "true false"},
+		{"free value", CompleteValue("general:gaps_in", ""), ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.got, " "); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// UnifiedDiff returns a unified diff between two texts, or "" when they
+// are equal. It is meant for previews, so it favours simplicity over
+// speed and compares whole lines.
+func UnifiedDiff(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	x, y := splitLines(a), splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:], y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Walk the table into a list of edits
+	type edit struct {
+		op   byte // ' ', '-' or '+'
+		text string
+		i, j int // line indexes in x and y before this edit
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			edits = append(edits, edit{' ', x[i], i, j})
+			i, j = i+1, j+1
+		case j < len(y) && (i == len(x) || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, edit{'+', y[j], i, j})
+			j++
+		default:
+			edits = append(edits, edit{'-', x[i], i, j})
+			i++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			start++
+			continue
+		}
+		// Grow the hunk until diffContext*2 unchanged lines separate changes
+		from := max(start-diffContext, 0)
+		end, quiet := start, 0
+		for end < len(edits) && quiet <= diffContext*2 {
+			if edits[end].op == ' ' {
+				quiet++
+			} else {
+				quiet = 0
+			}
+			end++
+		}
+		end -= max(quiet-diffContext, 0)
+
+		oldCount, newCount := 0, 0
+		for _, e := range edits[from:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(edits[from].i, oldCount), hunkRange(edits[from].j, newCount))
+		for _, e := range edits[from:end] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.text)
+			sb.WriteByte('\n')
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	want := `--- a
++++ b
+@@ -1,5 +1,5 @@
+ one
+-two
++2
+ three
+ four
+ five
+@@ -8,3 +8,4 @@
+ eight
+ nine
+ ten
++eleven
+`
+	if got := UnifiedDiff("a", "b", a, b); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant:\n%s", got, want)
+	}
+	if got := UnifiedDiff("a", "b", a, a); got != "" {
+		t.Errorf("UnifiedDiff() of equal texts = %q", got)
+	}
+}
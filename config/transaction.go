@@ -0,0 +1,206 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotConfirmed is returned when a change was rolled back because the user
+// did not confirm it in time
+var ErrNotConfirmed = errors.New("change not confirmed, rolled back")
+
+// Reloader is the part of the compositor IPC needed to apply a config
+type Reloader interface {
+	Reload() error
+	ConfigErrors() ([]string, error)
+}
+
+// ConfigError is a single error reported by Hyprland after a reload
+type ConfigError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e ConfigError) String() string {
+	switch {
+	case e.Line == 0:
+		return e.Message
+	case e.File == "":
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+var configErrorPattern = regexp.MustCompile(`^Config error in file (.+) at line (\d+): (.*)$`)
+
+// ParseConfigError splits a Hyprland error string into file, line and message
+func ParseConfigError(raw string) ConfigError {
+	m := configErrorPattern.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return ConfigError{Message: strings.TrimSpace(raw)}
+	}
+	line, _ := strconv.Atoi(m[2])
+	return ConfigError{File: m[1], Line: line, Message: m[3]}
+}
+
+// ApplyError is returned when Hyprland rejected the new config and the
+// previous file has been restored
+type ApplyError struct {
+	Errors []ConfigError
+}
+
+func (e *ApplyError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, ce := range e.Errors {
+		lines[i] = ce.String()
+	}
+	return "hyprland rejected the config, rolled back: " + strings.Join(lines, "; ")
+}
+
+// Transaction is an applied config change that can still be rolled back
+type Transaction struct {
+	files    []snapshot
+	reloader Reloader
+	done     bool
+}
+
+// snapshot is the content of a file before a transaction wrote it
+type snapshot struct {
+	path    string
+	content []byte
+	existed bool
+}
+
+// ApplyConfig saves the config like SaveConfig, reloads Hyprland and checks
+// for new config errors. If any appear the previous files are restored and
+// an *ApplyError is returned. On success the returned transaction must be
+// committed or rolled back by the caller. When no file changes nothing is
+// written or reloaded and the transaction is nil.
+func ApplyConfig(config *HyprlandConfig, path string, r Reloader) (*Transaction, error) {
+	docs, err := editConfig(config, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	tx := &Transaction{reloader: r}
+	for _, doc := range docs {
+		s := snapshot{path: doc.Path}
+		content, err := os.ReadFile(doc.Path)
+		switch {
+		case err == nil:
+			s.content, s.existed = content, true
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("failed to snapshot config: %w", err)
+		}
+		tx.files = append(tx.files, s)
+	}
+
+	// Errors already present are not caused by this change
+	before, err := r.ConfigErrors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config errors: %w", err)
+	}
+
+	for _, doc := range docs {
+		if err := WriteDocument(doc); err != nil {
+			return nil, tx.fail(err)
+		}
+	}
+	if err := r.Reload(); err != nil {
+		return nil, tx.fail(fmt.Errorf("failed to reload hyprland: %w", err))
+	}
+
+	after, err := r.ConfigErrors()
+	if err != nil {
+		return nil, tx.fail(fmt.Errorf("failed to query config errors: %w", err))
+	}
+	if introduced := newErrors(before, after); len(introduced) > 0 {
+		applyErr := &ApplyError{}
+		for _, raw := range introduced {
+			applyErr.Errors = append(applyErr.Errors, ParseConfigError(raw))
+		}
+		return nil, tx.fail(applyErr)
+	}
+
+	return tx, nil
+}
+
+// Commit keeps the applied change
+func (tx *Transaction) Commit() {
+	tx.done = true
+}
+
+// Rollback restores the previous config files and reloads Hyprland
+func (tx *Transaction) Rollback() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+
+	for _, s := range tx.files {
+		if s.existed {
+			if err := os.WriteFile(s.path, s.content, 0644); err != nil {
+				return fmt.Errorf("failed to restore config: %w", err)
+			}
+		} else if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to restore config: %w", err)
+		}
+	}
+	return tx.reloader.Reload()
+}
+
+// AwaitConfirmation commits the change if confirm delivers true before the
+// timeout expires, and rolls it back otherwise
+func (tx *Transaction) AwaitConfirmation(timeout time.Duration, confirm <-chan bool) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case ok := <-confirm:
+		if ok {
+			tx.Commit()
+			return nil
+		}
+	case <-timer.C:
+	}
+
+	if err := tx.Rollback(); err != nil {
+		return err
+	}
+	return ErrNotConfirmed
+}
+
+// fail rolls back and returns cause, or the rollback error if that failed too
+func (tx *Transaction) fail(cause error) error {
+	if err := tx.Rollback(); err != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", cause, err)
+	}
+	return cause
+}
+
+// newErrors returns the entries of after that were not already in before
+func newErrors(before, after []string) []string {
+	seen := make(map[string]int)
+	for _, e := range before {
+		seen[e]++
+	}
+
+	var introduced []string
+	for _, e := range after {
+		if seen[e] > 0 {
+			seen[e]--
+			continue
+		}
+		introduced = append(introduced, e)
+	}
+	return introduced
+}
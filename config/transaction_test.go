@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+// fakeHyprland reports an error for every line of the config containing
+// "bogus" whenever it is reloaded
+type fakeHyprland struct {
+	configPath string
+
+	mu      sync.Mutex
+	errors  []string
+	reloads int
+}
+
+func startFakeHyprland(t *testing.T, configPath string) (*fakeHyprland, *ipc.Client) {
+	t.Helper()
+
+	server, err := ipc.NewFakeServer(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start fake hyprland: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	f := &fakeHyprland{configPath: configPath}
+	server.Handle("reload", f.reload)
+	server.Handle("configerrors", func(string) string {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return strings.Join(f.errors, "\n")
+	})
+	return f, server.Client()
+}
+
+func (f *fakeHyprland) reload(string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reloads++
+	f.errors = nil
+	file, err := os.Open(f.configPath)
+	if err != nil {
+		return "ok"
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.Contains(scanner.Text(), "bogus") {
+			f.errors = append(f.errors, fmt.Sprintf(
+				"Config error in file %s at line %d: invalid value", f.configPath, line))
+		}
+	}
+	return "ok"
+}
+
+func TestApplyConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hyprland.conf")
+	original := "# original\ngeneral {\n    border_size = 1\n}\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake, client := startFakeHyprland(t, path)
+
+	tests := []struct {
+		name     string
+		variable string
+		wantErr  bool
+	}{
+		{name: "accepted change", variable: "terminal"},
+		{name: "rejected change is rolled back", variable: "bogus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			tree, err := LoadConfigTree(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := tree.Config
+			if err := cfg.SetOption("general:border_size", "3"); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Variables == nil {
+				cfg.Variables = make(map[string]string)
+			}
+			cfg.Variables[tt.variable] = "kitty"
+			tx, err := ApplyConfig(cfg, path, client)
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("ApplyConfig() error = %v", err)
+				}
+				tx.Commit()
+				content, _ := os.ReadFile(path)
+				if !strings.Contains(string(content), "border_size = 3") {
+					t.Error("new config was not written")
+				}
+				if !strings.Contains(string(content), "# original") {
+					t.Errorf("comments were not kept, got:\n%s", content)
+				}
+				return
+			}
+
+			var applyErr *ApplyError
+			if !errors.As(err, &applyErr) {
+				t.Fatalf("ApplyConfig() error = %v, want *ApplyError", err)
+			}
+			if len(applyErr.Errors) != 1 || applyErr.Errors[0].Line == 0 {
+				t.Errorf("reported errors = %+v, want one with a line", applyErr.Errors)
+			}
+			content, _ := os.ReadFile(path)
+			if string(content) != original {
+				t.Errorf("config not restored, got:\n%s", content)
+			}
+			if len(fake.errors) != 0 {
+				t.Error("hyprland was not reloaded after rollback")
+			}
+		})
+	}
+}
+
+func TestApplyConfigUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hyprland.conf")
+	if err := os.WriteFile(path, []byte("general {\n    border_size = 1\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake, client := startFakeHyprland(t, path)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := ApplyConfig(cfg, path, client)
+	if tx != nil || err != nil {
+		t.Fatalf("ApplyConfig() = %v, %v, want nil, nil", tx, err)
+	}
+	if fake.reloads != 0 {
+		t.Errorf("reloads = %d, want 0", fake.reloads)
+	}
+}
+
+func TestTransactionConfirmationTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hyprland.conf")
+	original := "general {\n    border_size = 1\n}\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake, client := startFakeHyprland(t, path)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetOption("general:border_size", "4"); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := ApplyConfig(cfg, path, client)
+	if err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+
+	err = tx.AwaitConfirmation(10*time.Millisecond, make(chan bool))
+	if !errors.Is(err, ErrNotConfirmed) {
+		t.Fatalf("AwaitConfirmation() error = %v, want ErrNotConfirmed", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != original {
+		t.Errorf("config not restored after timeout, got:\n%s", content)
+	}
+	if fake.reloads != 2 {
+		t.Errorf("reloads = %d, want 2", fake.reloads)
+	}
+}
+
+func TestParseConfigError(t *testing.T) {
+	got := ParseConfigError("Config error in file /home/u/.config/hypr/hyprland.conf at line 12: invalid dispatcher")
+	want := ConfigError{File: "/home/u/.config/hypr/hyprland.conf", Line: 12, Message: "invalid dispatcher"}
+	if got != want {
+		t.Errorf("ParseConfigError() = %+v, want %+v", got, want)
+	}
+
+	if s := got.String(); s != "/home/u/.config/hypr/hyprland.conf:12: invalid dispatcher" {
+		t.Errorf("String() = %q, want file:line: message", s)
+	}
+	if got := ParseConfigError("something else"); got.Line != 0 || got.Message != "something else" {
+		t.Errorf("ParseConfigError() = %+v for unstructured error", got)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigTree is a config file together with the files it sources
+type ConfigTree struct {
+	Config    *HyprlandConfig
+	Documents []*Document // the main file first, then sourced files in the order they are read
+}
+
+// LoadConfigTree reads the config file at path and follows its source
+// lines the way Hyprland does, so entries from every file end up in one
+// model. Each entry records the file it came from.
+//
+// The tree is meant for reading; WriteConfig on its Config would inline
+// every sourced file into the main one.
+func LoadConfigTree(path string) (*ConfigTree, error) {
+	file, err := ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &ConfigTree{Config: &HyprlandConfig{}}
+	seen := make(map[string]bool)
+	d := &decoder{config: tree.Config}
+
+	var load func(file string) error
+	load = func(file string) error {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		if seen[abs] {
+			return fmt.Errorf("%s is sourced more than once", file)
+		}
+		seen[abs] = true
+
+		doc, err := LoadDocument(file)
+		if err != nil {
+			return err
+		}
+		tree.Documents = append(tree.Documents, doc)
+		return d.decode(doc)
+	}
+
+	// Relative source paths are resolved against the main config directory
+	dir := filepath.Dir(file)
+	d.source = func(value string) error {
+		files, err := sourceFiles(dir, tree.Config.expandVariables(value))
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err := load(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := load(file); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// LoadDocuments reads the config file at path and the files its source
+// lines name, in the order Hyprland reads them, without decoding them. It
+// serves edits that must work on configs LoadConfigTree rejects. Variables
+// defined before a source line are expanded in its path.
+func LoadDocuments(path string) ([]*Document, error) {
+	file, err := ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := loadDocuments(file, nil)
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// loadDocuments reads file and the files it sources. source, when set, is
+// called with every source line and its value with variables expanded. On
+// error the documents read so far are returned with it.
+func loadDocuments(file string, source func(doc *Document, line *Line, value string)) ([]*Document, error) {
+	vars := &HyprlandConfig{Variables: make(map[string]string)}
+	seen := make(map[string]bool)
+	dir := filepath.Dir(file)
+	var docs []*Document
+
+	var load func(file string) error
+	load = func(file string) error {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		if seen[abs] {
+			return fmt.Errorf("%s is sourced more than once", file)
+		}
+		seen[abs] = true
+
+		doc, err := LoadDocument(file)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+		for _, line := range doc.Lines {
+			if line.Kind != LineAssign || line.Section != "" {
+				continue
+			}
+			switch {
+			case strings.HasPrefix(line.Key, "$"):
+				vars.Variables[line.Key[1:]] = line.Value
+			case line.Key == "source":
+				value := vars.expandVariables(line.Value)
+				if source != nil {
+					source(doc, line, value)
+				}
+				files, err := sourceFiles(dir, value)
+				if err != nil {
+					return fmt.Errorf("%s:%d: %w", doc.Path, line.Num, err)
+				}
+				for _, f := range files {
+					if err := load(f); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	err := load(file)
+	return docs, err
+}
+
+// Document returns the document of the tree read from file, or nil
+func (t *ConfigTree) Document(file string) *Document {
+	for _, doc := range t.Documents {
+		if doc.Path == file {
+			return doc
+		}
+	}
+	return nil
+}
+
+// sourceFiles resolves the value of a source line to the files it names.
+// Globs may match nothing, a plain path must exist.
+func sourceFiles(dir, value string) ([]string, error) {
+	path, err := ExpandPath(strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	if !strings.ContainsAny(path, "*?[") {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("source %s: %w", value, err)
+		}
+		return []string{path}, nil
+	}
+	files, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", value, err)
+	}
+	return files, nil
+}
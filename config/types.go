@@ -1,29 +1,45 @@
 package config
 
-// HyprlandConfig represents the main configuration structure
+// HyprlandConfig represents the main configuration structure. Section
+// fields are tagged with their Hyprland category.
 type HyprlandConfig struct {
-	General     GeneralSection
-	Decoration  DecorationSection
-	Animations  AnimationsSection
-	Input       InputSection
-	Touchpad    TouchpadSection
-	Gestures    GesturesSection
-	Misc        MiscSection
+	General     GeneralSection    `hypr:"general"`
+	Decoration  DecorationSection `hypr:"decoration"`
+	Animations  AnimationsSection `hypr:"animations"`
+	Input       InputSection      `hypr:"input"`
+	Touchpad    TouchpadSection   `hypr:"input:touchpad"`
+	Gestures    GesturesSection   `hypr:"gestures"`
+	Misc        MiscSection       `hypr:"misc"`
 	WindowRules []WindowRule
 	LayerRules  []LayerRule
 	Binds       []Bind
 	Monitors    []Monitor
 	Workspaces  []Workspace
-	Debug       DebugSection
-	XWayland    XWaylandSection
-	OpenGL      OpenGLSection
-	Cursor      CursorSection
+	Devices     []Device
+	Debug       DebugSection    `hypr:"debug"`
+	XWayland    XWaylandSection `hypr:"xwayland"`
+	OpenGL      OpenGLSection   `hypr:"opengl"`
+	Cursor      CursorSection   `hypr:"cursor"`
+	Variables   map[string]string
+	Exec        []ExecCommand
+	Keywords    []Keyword
+	Sources     []string
+
+	// set records the option paths explicitly assigned in the file
+	set map[string]bool
+	// extra holds option values without a typed field, by full path
+	extra map[string]string
+	// lines records where each option was assigned, by full path
+	lines map[string]int
+	// files records the file each option was assigned in, by full path,
+	// and each variable, by "$name"
+	files map[string]string
 }
 
 type GeneralSection struct {
 	BorderSize            int     `hypr:"border_size"`
-	GapIn                 int     `hypr:"gaps_in"`
-	GapOut                int     `hypr:"gaps_out"`
+	GapIn                 string  `hypr:"gaps_in"`
+	GapOut                string  `hypr:"gaps_out"`
 	Cursor                string  `hypr:"cursor_inactive_timeout"`
 	Layout                string  `hypr:"layout"`
 	NoFocusFollowMouse    bool    `hypr:"no_focus_fallback"`
@@ -46,13 +62,16 @@ type DecorationSection struct {
 	InactiveOpacity float64 `hypr:"inactive_opacity"`
 	DropShadow      bool    `hypr:"drop_shadow"`
 	ShadowRange     int     `hypr:"shadow_range"`
-	ShadowColor     string  `hypr:"shadow_color"`
+	ShadowColor     string  `hypr:"col.shadow"`
 }
 
 type WindowRule struct {
-	Rule   string
-	Value  string
-	Target string
+	Rule    string
+	Value   string
+	Target  string
+	Version int    // 1 for windowrule, 2 for windowrulev2
+	Line    int    // line in the config file, 0 if not loaded from one
+	File    string // file the rule was read from
 }
 
 // Add these missing types that are referenced in HyprlandConfig
@@ -117,17 +136,73 @@ type Bind struct {
 	Params      string `hypr:"params"`
 	Flags       string `hypr:"flags"`
 	Description string `hypr:"description"`
+	Submap      string // empty for the global keymap
+	Line        int    // line in the config file, 0 if not loaded from one
+	File        string // file the bind was read from
 }
 
+// Monitor is a monitor line. Short rules such as "NAME, disable" or
+// "NAME, addreserved, 30, 0, 0, 0" keep their keyword in Resolution and
+// leave Position and Scale empty.
 type Monitor struct {
 	Name       string
 	Resolution string
 	Position   string
+	Scale      string
+	Args       string // further arguments as written, e.g. "transform, 1"
+	Line       int
+	File       string
+}
+
+// Fields returns the comma separated fields of the monitor line
+func (m Monitor) Fields() []string {
+	fields := []string{m.Name, m.Resolution}
+	if m.Position != "" || m.Scale != "" {
+		fields = append(fields, m.Position, m.Scale)
+	}
+	if m.Args != "" {
+		fields = append(fields, m.Args)
+	}
+	return fields
 }
 
 type Workspace struct {
 	Name    string
 	Monitor string
+	Line    int
+	File    string
+}
+
+// ExecCommand is an exec, exec-once or exec-shutdown entry
+type ExecCommand struct {
+	Kind    string
+	Command string
+	Line    int
+	File    string
+}
+
+// Keyword is a top-level keyword the model has no type for, such as env
+// or layerrule. They may repeat, so each assignment is kept in file order.
+type Keyword struct {
+	Name  string
+	Value string
+	Line  int
+	File  string
+}
+
+// Device is a per-device input block. Hyprland keeps the settings of each
+// device apart, so they are not merged into the option paths.
+type Device struct {
+	Name    string
+	Options []DeviceOption // in file order, without name
+	Line    int            // line the block opens on
+	File    string
+}
+
+// DeviceOption is an assignment inside a device block, value as written
+type DeviceOption struct {
+	Name  string
+	Value string
 }
 
 // Add other necessary types...
@@ -158,11 +233,16 @@ type CursorSection struct {
 type BezierCurve struct {
 	Name   string
 	Points [4]float64
+	Line   int
+	File   string
 }
 
 type Animation struct {
 	Target   string
+	Enabled  bool
 	Bezier   string
-	Duration int
+	Duration float64 // in deciseconds
 	Style    string
+	Line     int
+	File     string
 }
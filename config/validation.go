@@ -2,15 +2,12 @@ package config
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
 // Add at the top of the file, after imports
-var validKeys = map[string]bool{
-	"Return": true, "Space": true, "Tab": true, "Backspace": true,
-}
-
 // ValidationError represents a configuration validation error
 type ValidationError struct {
 	Field   string
@@ -45,32 +42,171 @@ func ValidateFloat(field, value string, min, max float64) error {
 	return nil
 }
 
+// ValidateBool accepts the spellings Hyprland does: true/false, yes/no,
+// on/off and 1/0
 func ValidateBool(field, value string) error {
-	value = strings.ToLower(value)
-	if value != "true" && value != "false" {
+	if _, ok := parseHyprBool(value); !ok {
 		return &ValidationError{field, value, "must be true or false"}
 	}
 	return nil
 }
 
-func ValidateKey(field, value string) error {
-	if !validKeys[value] {
-		return &ValidationError{field, value, "invalid key name"}
+func ValidateEnum(field, value string, allowed []string) error {
+	for _, v := range allowed {
+		if value == v {
+			return nil
+		}
+	}
+	return &ValidationError{field, value, "must be one of " + strings.Join(allowed, ", ")}
+}
+
+// parseGaps reads CSS style gaps, one to four numbers separated by commas
+// or spaces, and returns them for the top, right, bottom and left side
+func parseGaps(value string) ([4]int, bool) {
+	var sides [4]int
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(fields) < 1 || len(fields) > 4 {
+		return sides, false
+	}
+	n := make([]int, len(fields))
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return sides, false
+		}
+		n[i] = v
+	}
+	switch len(n) {
+	case 1:
+		sides = [4]int{n[0], n[0], n[0], n[0]}
+	case 2:
+		sides = [4]int{n[0], n[1], n[0], n[1]}
+	case 3:
+		sides = [4]int{n[0], n[1], n[2], n[1]}
+	default:
+		sides = [4]int{n[0], n[1], n[2], n[3]}
+	}
+	return sides, true
+}
+
+// ValidateGaps checks CSS style gaps such as "5" or "5,10,5,10"
+func ValidateGaps(field, value string, min, max int) error {
+	sides, ok := parseGaps(value)
+	if !ok {
+		return &ValidationError{field, value, "must be one to four numbers, e.g. 5 or 5,10,5,10"}
+	}
+	for _, v := range sides {
+		if v < min || v > max {
+			return &ValidationError{field, value, fmt.Sprintf("must be between %d and %d", min, max)}
+		}
 	}
 	return nil
 }
 
-// Add more validation functions
+// ValidateVec2 checks a pair of numbers such as "10 -5"
+func ValidateVec2(field, value string) error {
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return &ValidationError{field, value, "must be two numbers separated by a space"}
+	}
+	for _, p := range parts {
+		if _, err := strconv.ParseFloat(p, 64); err != nil {
+			return &ValidationError{field, value, "must be two numbers separated by a space"}
+		}
+	}
+	return nil
+}
 
-func ValidateWindowRule(field, value string) error {
-	parts := strings.Split(value, ",")
-	if len(parts) != 3 {
-		return &ValidationError{field, value, "must be in format: rule,value,target"}
+var (
+	hexColorPattern  = regexp.MustCompile(`^0x[0-9a-fA-F]{8}$`)
+	rgbaColorPattern = regexp.MustCompile(`^rgba\(([0-9a-fA-F]{8}|\s*\d{1,3}\s*,\s*\d{1,3}\s*,\s*\d{1,3}\s*,\s*[0-9.]+\s*)\)$`)
+	rgbColorPattern  = regexp.MustCompile(`^rgb\(([0-9a-fA-F]{6}|\s*\d{1,3}\s*,\s*\d{1,3}\s*,\s*\d{1,3}\s*)\)$`)
+	anglePattern     = regexp.MustCompile(`^-?\d+(\.\d+)?deg$`)
+)
+
+// ValidateColor checks a single color: 0xAARRGGBB, rgba(RRGGBBAA),
+// rgb(RRGGBB) or the decimal rgba(r, g, b, a) / rgb(r, g, b) forms
+func ValidateColor(field, value string) error {
+	value = strings.TrimSpace(value)
+	if hexColorPattern.MatchString(value) || rgbaColorPattern.MatchString(value) || rgbColorPattern.MatchString(value) {
+		return nil
+	}
+	return &ValidationError{field, value, "must be a color like rgba(33ccffee) or 0xff33ccff"}
+}
+
+// ValidateGradient checks one or more space separated colors optionally
+// followed by an angle, e.g. "rgba(33ccffee) rgba(00ff99ee) 45deg"
+func ValidateGradient(field, value string) error {
+	colors := splitColors(value)
+	if len(colors) > 0 && anglePattern.MatchString(colors[len(colors)-1]) {
+		colors = colors[:len(colors)-1]
+	}
+	if len(colors) == 0 {
+		return &ValidationError{field, value, "must contain at least one color"}
+	}
+	for _, c := range colors {
+		if err := ValidateColor(field, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitColors splits on spaces outside of parentheses
+func splitColors(value string) []string {
+	var parts []string
+	var current strings.Builder
+	depth := 0
+	for _, r := range value {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ' ' && depth == 0:
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// ValidateRegex checks that value compiles. Hyprland uses RE2 like Go does.
+func ValidateRegex(field, value string) error {
+	if _, err := regexp.Compile(value); err != nil {
+		return &ValidationError{field, value, "invalid regex: " + err.Error()}
 	}
-	// Add specific rule validation
 	return nil
 }
 
+// ValidateKey accepts xkb keysym names (case-insensitive), keycodes
+// (code:N), mouse buttons (mouse:N), scroll pseudo keys (mouse_down) and
+// switches (switch:on:NAME)
+func ValidateKey(field, value string) error {
+	if _, ok := keysyms[strings.ToLower(value)]; ok {
+		return nil
+	}
+	if msg := checkSpecialKey(value); msg != "" {
+		return &ValidationError{field, value, msg}
+	}
+	if strings.Contains(value, ":") {
+		return nil
+	}
+	if suggestion := suggestKey(value); suggestion != "" {
+		return &ValidationError{field, value, fmt.Sprintf("unknown key name, did you mean %q?", suggestion)}
+	}
+	return &ValidationError{field, value, "unknown key name"}
+}
+
+// Add more validation functions
+
 func ValidateKeybind(field, value string) error {
 	parts := strings.Split(value, ",")
 	if len(parts) < 3 {
@@ -97,63 +233,8 @@ func ValidateKeybind(field, value string) error {
 // Add more specific validators as needed
 
 func ValidateRuleType(field, value string) error {
-	validRules := map[string]bool{
-		"workspace": true,
-		"float":     true,
-		"tile":      true,
-		"pseudo":    true,
-		"size":      true,
-		"minsize":   true,
-		"maxsize":   true,
-		"opacity":   true,
-		// Add other valid rule types
-	}
-
-	if !validRules[strings.ToLower(value)] {
+	if _, ok := LookupWindowRule(value); !ok {
 		return &ValidationError{field, value, "invalid rule type"}
 	}
 	return nil
 }
-
-func ValidateDispatcher(field, value string) error {
-	validDispatchers := map[string]bool{
-		"exec":           true,
-		"killactive":     true,
-		"workspace":      true,
-		"togglefloating": true,
-		"fullscreen":     true,
-		"pseudo":         true,
-		"movefocus":      true,
-		"movewindow":     true,
-		// Add other valid dispatchers
-	}
-
-	if !validDispatchers[strings.ToLower(value)] {
-		return &ValidationError{field, value, "invalid dispatcher"}
-	}
-	return nil
-}
-
-// Enhance ValidateKey with more keys
-func init() {
-	// Add to validKeys map
-	for i := 0; i < 10; i++ {
-		validKeys[fmt.Sprintf("%d", i)] = true
-	}
-	for c := 'A'; c <= 'Z'; c++ {
-		validKeys[string(c)] = true
-	}
-	// Add function keys
-	for i := 1; i <= 12; i++ {
-		validKeys[fmt.Sprintf("F%d", i)] = true
-	}
-	// Add more special keys
-	specialKeys := []string{
-		"Escape", "Print", "Insert", "Delete",
-		"Home", "End", "PageUp", "PageDown",
-		"Left", "Right", "Up", "Down",
-	}
-	for _, key := range specialKeys {
-		validKeys[key] = true
-	}
-}
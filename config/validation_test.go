@@ -0,0 +1,100 @@
+package config
+
+import "testing"
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(field, value string) error
+		value    string
+		wantErr  bool
+	}{
+		{"bool yes", ValidateBool, "yes", false},
+		{"bool numeric", ValidateBool, "1", false},
+		{"bool garbage", ValidateBool, "maybe", true},
+		{"vec2", ValidateVec2, "10 -5.5", false},
+		{"vec2 one number", ValidateVec2, "10", true},
+		{"vec2 not numbers", ValidateVec2, "a b", true},
+		{"color hex", ValidateColor, "0xff33ccff", false},
+		{"color rgba hex", ValidateColor, "rgba(33ccffee)", false},
+		{"color rgb hex", ValidateColor, "rgb(33ccff)", false},
+		{"color rgba decimal", ValidateColor, "rgba(51, 204, 255, 0.9)", false},
+		{"color short hex", ValidateColor, "0xfff", true},
+		{"color name", ValidateColor, "red", true},
+		{"gradient single", ValidateGradient, "rgba(33ccffee)", false},
+		{"gradient with angle", ValidateGradient, "rgba(33ccffee) rgba(00ff99ee) 45deg", false},
+		{"gradient decimal colors", ValidateGradient, "rgba(51, 204, 255, 0.9) rgb(0, 0, 0)", false},
+		{"gradient only angle", ValidateGradient, "45deg", true},
+		{"gradient bad color", ValidateGradient, "rgba(33ccffee) blue", true},
+		{"regex", ValidateRegex, "^(kitty|Alacritty)$", false},
+		{"regex unbalanced", ValidateRegex, "^(kitty", true},
+		{"key letter", ValidateKey, "Q", false},
+		{"key any case", ValidateKey, "return", false},
+		{"key punctuation", ValidateKey, "grave", false},
+		{"key media", ValidateKey, "XF86AudioRaiseVolume", false},
+		{"key F13", ValidateKey, "F13", false},
+		{"key F36", ValidateKey, "F36", true},
+		{"key keycode", ValidateKey, "code:10", false},
+		{"key keycode out of range", ValidateKey, "code:3", true},
+		{"key mouse button", ValidateKey, "mouse:272", false},
+		{"key mouse not a number", ValidateKey, "mouse:left", true},
+		{"key scroll", ValidateKey, "mouse_down", false},
+		{"key switch", ValidateKey, "switch:on:Lid Switch", false},
+		{"key switch without name", ValidateKey, "switch:off:", true},
+		{"key unknown prefix", ValidateKey, "button:1", true},
+		{"key typo", ValidateKey, "Retrun", true},
+		{"key latin-1", ValidateKey, "odiaeresis", false},
+		{"key dead key", ValidateKey, "dead_acute", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.validate("test", tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateKeySuggestion(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"Retrun", `unknown key name, did you mean "Return"?`},
+		{"XF86AudioRaiseVolum", `unknown key name, did you mean "XF86AudioRaiseVolume"?`},
+		{"PageUp", `unknown key name, did you mean "Page_Up"?`},
+		{"completelywrong", "unknown key name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := ValidateKey("key", tt.key)
+			verr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("ValidateKey(%q) = %v, want a ValidationError", tt.key, err)
+			}
+			if verr.Message != tt.want {
+				t.Errorf("ValidateKey(%q) message = %q, want %q", tt.key, verr.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalKey(t *testing.T) {
+	for in, want := range map[string]string{
+		"RETURN":    "Return",
+		"backspace": "BackSpace",
+		"q":         "q",
+		"eacute":    "eacute",
+		"EACUTE":    "eacute",
+		"code:10":   "code:10",
+	} {
+		if got, ok := CanonicalKey(in); !ok || got != want {
+			t.Errorf("CanonicalKey(%q) = %q, %v, want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := CanonicalKey("code:x"); ok {
+		t.Error("CanonicalKey accepted an invalid keycode")
+	}
+}
@@ -3,15 +3,17 @@ package config
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // WriteConfig writes the configuration to the specified file
 func WriteConfig(config *HyprlandConfig, path string) error {
-	if path == "" {
-		path = DefaultConfigPath
+	path, err := ExpandPath(path)
+	if err != nil {
+		return err
 	}
 
 	// Create backup before writing
@@ -19,15 +21,6 @@ func WriteConfig(config *HyprlandConfig, path string) error {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	// Expand home directory
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return err
-		}
-		path = filepath.Join(home, path[2:])
-	}
-
 	// Generate config content
 	content := generateConfig(config)
 
@@ -35,47 +28,243 @@ func WriteConfig(config *HyprlandConfig, path string) error {
 	return os.WriteFile(path, []byte(content), 0644)
 }
 
+// WriteDocument writes an edited document back to its file. Unlike
+// WriteConfig it keeps the layout and comments of the original.
+func WriteDocument(doc *Document) error {
+	path, err := ExpandPath(doc.Path)
+	if err != nil {
+		return err
+	}
+	if err := BackupConfig(path); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	return os.WriteFile(path, []byte(doc.String()), 0644)
+}
+
+// SaveConfig writes config back to the file at path and the files it
+// sources. The files are read again and only entries that differ from
+// config are edited, the way an import is applied, so comments, layout and
+// $variable references of unchanged entries are kept. config must cover
+// the whole tree, as LoadConfigTree reads it. It returns the documents
+// that were written.
+func SaveConfig(config *HyprlandConfig, path string) ([]*Document, error) {
+	docs, err := editConfig(config, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if err := WriteDocument(doc); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// editConfig returns the documents of the tree at path edited to match
+// config, without writing them
+func editConfig(config *HyprlandConfig, path string) ([]*Document, error) {
+	tree, err := LoadConfigTree(path)
+	if err != nil {
+		return nil, err
+	}
+	return ImportConfig(tree, ExportConfig(&ConfigTree{Config: config, Documents: tree.Documents}))
+}
+
 func generateConfig(config *HyprlandConfig) string {
 	var sb strings.Builder
 
 	// Add header
 	sb.WriteString("# Generated by hyprmax on " + time.Now().Format("2006-01-02 15:04:05") + "\n\n")
 
+	// Write variables first so everything below can use them
+	if len(config.Variables) > 0 {
+		names := make([]string, 0, len(config.Variables))
+		for name := range config.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("$%s = %s\n", name, config.Variables[name]))
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, source := range config.Sources {
+		sb.WriteString(fmt.Sprintf("source = %s\n", source))
+	}
+
 	// Write monitors
 	for _, monitor := range config.Monitors {
-		sb.WriteString(fmt.Sprintf("monitor=%s,%s,%s\n",
-			monitor.Name, monitor.Resolution, monitor.Position))
+		sb.WriteString("monitor=" + strings.Join(monitor.Fields(), ",") + "\n")
 	}
 	sb.WriteString("\n")
 
-	// Write general section
-	sb.WriteString("general {\n")
-	sb.WriteString(fmt.Sprintf("    border_size = %d\n", config.General.BorderSize))
-	sb.WriteString(fmt.Sprintf("    gaps_in = %d\n", config.General.GapIn))
-	sb.WriteString(fmt.Sprintf("    gaps_out = %d\n", config.General.GapOut))
-	sb.WriteString(fmt.Sprintf("    cursor_inactive_timeout = %s\n", config.General.Cursor))
-	sb.WriteString(fmt.Sprintf("    layout = %s\n", config.General.Layout))
-	sb.WriteString(fmt.Sprintf("    no_focus_fallback = %v\n", config.General.NoFocusFollowMouse))
-	sb.WriteString("}\n\n")
-
-	// Write decoration section
-	sb.WriteString("decoration {\n")
-	sb.WriteString(fmt.Sprintf("    rounding = %d\n", config.Decoration.Rounding))
-	sb.WriteString(fmt.Sprintf("    blur = %v\n", config.Decoration.BlurEnabled))
-	sb.WriteString(fmt.Sprintf("    blur_size = %d\n", config.Decoration.BlurSize))
-	sb.WriteString(fmt.Sprintf("    blur_passes = %d\n", config.Decoration.BlurPasses))
-	sb.WriteString(fmt.Sprintf("    active_opacity = %.2f\n", config.Decoration.Opacity))
-	sb.WriteString(fmt.Sprintf("    inactive_opacity = %.2f\n", config.Decoration.InactiveOpacity))
-	sb.WriteString("}\n\n")
-
-	// Write binds
+	// Write option categories
+	writeBlocks(&sb, optionTree(config), 0)
+
+	for _, exec := range config.Exec {
+		sb.WriteString(fmt.Sprintf("%s = %s\n", exec.Kind, exec.Command))
+	}
+	for _, k := range config.Keywords {
+		sb.WriteString(fmt.Sprintf("%s = %s\n", k.Name, k.Value))
+	}
+	for _, ws := range config.Workspaces {
+		sb.WriteString(fmt.Sprintf("workspace = %s, monitor:%s\n", ws.Name, ws.Monitor))
+	}
+	for _, dev := range config.Devices {
+		sb.WriteString(strings.Join(deviceKeyword(dev).raw, "\n") + "\n")
+	}
+	for _, rule := range config.WindowRules {
+		sb.WriteString(fmt.Sprintf("%s = %s\n", rule.Keyword(), rule.Text()))
+	}
+
+	// Write binds, global ones first and then one block per submap
+	var submaps []string
 	for _, bind := range config.Binds {
-		if bind.Description != "" {
-			sb.WriteString(fmt.Sprintf("# %s\n", bind.Description))
+		if bind.Submap == "" {
+			writeBind(&sb, bind)
+		} else if !containsString(submaps, bind.Submap) {
+			submaps = append(submaps, bind.Submap)
 		}
-		sb.WriteString(fmt.Sprintf("bind%s = %s, %s, %s, %s\n",
-			bind.Flags, bind.Mods, bind.Key, bind.Dispatcher, bind.Params))
+	}
+	for _, submap := range submaps {
+		sb.WriteString(fmt.Sprintf("\nsubmap = %s\n", submap))
+		for _, bind := range config.Binds {
+			if bind.Submap == submap {
+				writeBind(&sb, bind)
+			}
+		}
+		sb.WriteString("submap = reset\n")
 	}
 
 	return sb.String()
 }
+
+func writeBind(sb *strings.Builder, bind Bind) {
+	if bind.Description != "" && !strings.Contains(bind.Flags, "d") {
+		sb.WriteString(fmt.Sprintf("# %s\n", bind.Description))
+	}
+	fields := []string{bind.Mods, bind.Key}
+	if strings.Contains(bind.Flags, "d") {
+		fields = append(fields, bind.Description)
+	}
+	fields = append(fields, bind.Dispatcher, bind.Params)
+	sb.WriteString(fmt.Sprintf("bind%s = %s\n", bind.Flags, strings.Join(fields, ", ")))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// block is a category with its option lines and nested categories
+type block struct {
+	name     string
+	lines    [][2]string
+	children []*block
+}
+
+func (b *block) child(name string) *block {
+	for _, c := range b.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &block{name: name}
+	b.children = append(b.children, c)
+	return c
+}
+
+func (b *block) add(path, value string) {
+	parts := strings.Split(path, ":")
+	node := b
+	for _, category := range parts[:len(parts)-1] {
+		node = node.child(category)
+	}
+	node.lines = append(node.lines, [2]string{parts[len(parts)-1], value})
+}
+
+// optionTree collects the options worth writing: everything set explicitly
+// plus typed values that differ from both their zero value and the default
+func optionTree(config *HyprlandConfig) *block {
+	root := &block{}
+	for _, opt := range registry {
+		value, err := config.GetOption(opt.Path)
+		if err != nil {
+			continue
+		}
+		if !config.IsSet(opt.Path) && (isZeroValue(value) || sameValue(opt.Type, value, opt.Default)) {
+			continue
+		}
+		root.add(opt.Path, value)
+	}
+
+	extra := config.ExtraOptions()
+	paths := make([]string, 0, len(extra))
+	for path := range extra {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		root.add(path, extra[path])
+	}
+
+	if len(config.Animations.Beziers) > 0 || len(config.Animations.Animations) > 0 {
+		animations := root.child("animations")
+		for _, curve := range config.Animations.Beziers {
+			points := make([]string, len(curve.Points))
+			for i, p := range curve.Points {
+				points[i] = strconv.FormatFloat(p, 'f', -1, 64)
+			}
+			animations.lines = append(animations.lines, [2]string{"bezier",
+				curve.Name + ", " + strings.Join(points, ", ")})
+		}
+		for _, anim := range config.Animations.Animations {
+			animations.lines = append(animations.lines, [2]string{"animation", formatAnimation(anim)})
+		}
+	}
+	return root
+}
+
+func writeBlocks(sb *strings.Builder, b *block, depth int) {
+	indent := strings.Repeat("    ", depth)
+	for _, line := range b.lines {
+		sb.WriteString(fmt.Sprintf("%s%s = %s\n", indent, line[0], line[1]))
+	}
+	for _, c := range b.children {
+		sb.WriteString(indent + c.name + " {\n")
+		writeBlocks(sb, c, depth+1)
+		sb.WriteString(indent + "}\n")
+		if depth == 0 {
+			sb.WriteString("\n")
+		}
+	}
+}
+
+func formatAnimation(anim Animation) string {
+	onOff := "0"
+	if anim.Enabled {
+		onOff = "1"
+	}
+	fields := []string{anim.Target, onOff}
+	if anim.Bezier != "" || anim.Duration != 0 {
+		fields = append(fields, strconv.FormatFloat(anim.Duration, 'f', -1, 64), anim.Bezier)
+	}
+	if anim.Style != "" {
+		fields = append(fields, anim.Style)
+	}
+	return strings.Join(fields, ", ")
+}
+
+func isZeroValue(value string) bool {
+	switch value {
+	case "", "0", "false":
+		return true
+	}
+	return false
+}
@@ -10,18 +10,6 @@ import (
 func TestWriteConfig(t *testing.T) {
 	// Create a test config
 	cfg := &HyprlandConfig{
-		General: GeneralSection{
-			BorderSize: 2,
-			GapIn:      5,
-			GapOut:     10,
-			Layout:     "dwindle",
-		},
-		Decoration: DecorationSection{
-			Rounding:    10,
-			BlurEnabled: true,
-			BlurSize:    3,
-			Opacity:     0.95,
-		},
 		Binds: []Bind{
 			{
 				Mods:        "SUPER",
@@ -32,6 +20,20 @@ func TestWriteConfig(t *testing.T) {
 			},
 		},
 	}
+	for path, value := range map[string]string{
+		"general:border_size":       "2",
+		"general:gaps_in":           "5",
+		"general:gaps_out":          "10",
+		"general:layout":            "dwindle",
+		"decoration:rounding":       "10",
+		"decoration:blur":           "true",
+		"decoration:blur_size":      "3",
+		"decoration:active_opacity": "0.95",
+	} {
+		if err := cfg.SetOption(path, value); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	// Create temp file for testing
 	tmpDir := t.TempDir()
@@ -58,3 +60,31 @@ func TestWriteConfig(t *testing.T) {
 		t.Error("Config missing terminal bind")
 	}
 }
+
+func TestWriteConfigRoundTrip(t *testing.T) {
+	cfg, err := LoadConfig("testdata/hyprland.conf")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.SetOption("general:col.active_border", "rgba(33ccffee)")
+
+	path := filepath.Join(t.TempDir(), "hyprland.conf")
+	if err := WriteConfig(cfg, path); err != nil {
+		t.Fatalf("WriteConfig() error = %v", err)
+	}
+
+	reloaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() of written config error = %v", err)
+	}
+	for _, opt := range Options() {
+		want, _ := cfg.GetOption(opt.Path)
+		got, _ := reloaded.GetOption(opt.Path)
+		if cfg.IsSet(opt.Path) && got != want {
+			t.Errorf("%s = %q after round trip, want %q", opt.Path, got, want)
+		}
+	}
+	if len(reloaded.Monitors) != len(cfg.Monitors) {
+		t.Errorf("Monitors = %+v after round trip", reloaded.Monitors)
+	}
+}
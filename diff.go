@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+var diffFlags = []flagSpec{
+	{"format", "format", "text", "output `format`: text or json", completeChoices},
+	{"exit-code", "", "", "exit with status 1 when the configs differ", completeNone},
+}
+
+func runDiff(args []string) error {
+	fs := newFlagSet("diff")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax diff [--format text|json] [--exit-code] OLD [NEW]")
+		fmt.Fprintln(os.Stderr, "\nWithout NEW, OLD is compared with the current config.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, exitCode := flagString(fs, "format"), flagBool(fs, "exit-code")
+
+	var oldPath, newPath string
+	switch fs.NArg() {
+	case 1:
+		oldPath, newPath = fs.Arg(0), configPath
+	case 2:
+		oldPath, newPath = fs.Arg(0), fs.Arg(1)
+	default:
+		fs.Usage()
+		return fmt.Errorf("need one or two config files")
+	}
+
+	oldTree, err := config.LoadConfigTree(oldPath)
+	if err != nil {
+		return err
+	}
+	newTree, err := config.LoadConfigTree(newPath)
+	if err != nil {
+		return err
+	}
+	changes := config.DiffConfigs(oldTree, newTree)
+
+	switch format {
+	case "text":
+		if len(changes) == 0 {
+			fmt.Println("No differences")
+		}
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	case "json":
+		if changes == nil {
+			changes = []config.ConfigChange{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(changes); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	if exitCode && len(changes) > 0 {
+		return fmt.Errorf("%d difference(s)", len(changes))
+	}
+	return nil
+}
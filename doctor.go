@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+var doctorFlags = []flagSpec{
+	{"format", "format", "text", "output `format`: text or json", completeChoices},
+}
+
+func runDoctor(args []string) error {
+	fs := newFlagSet("doctor")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format := flagString(fs, "format")
+
+	checks := config.NewDoctor(configPath, configGiven).Run()
+	switch format {
+	case "text":
+		for _, c := range checks {
+			fmt.Printf("[%-4s] %-18s %s\n", c.Status, c.Name, c.Message)
+			if c.Hint != "" {
+				fmt.Printf("       %-18s hint: %s\n", "", c.Hint)
+			}
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(checks); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == config.DoctorFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
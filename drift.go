@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/max-geller/hyprmax/config"
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+var driftFlags = []flagSpec{
+	{"persist", "", "", "write the runtime values to the config file", completeNone},
+	{"reset", "", "", "set the runtime values back to the file values", completeNone},
+}
+
+func runDrift(args []string) error {
+	fs := newFlagSet("drift")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	persist, reset := flagBool(fs, "persist"), flagBool(fs, "reset")
+
+	if persist && reset {
+		return fmt.Errorf("--persist and --reset are mutually exclusive")
+	}
+
+	tree, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg := tree.Config
+	client, err := ipc.NewClient()
+	if err != nil {
+		return err
+	}
+
+	drifts, err := config.DetectDrift(cfg, client)
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		fmt.Println("Runtime matches the config file")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OPTION\tFILE\tRUNTIME")
+	for _, d := range drifts {
+		file := d.File
+		if d.Default {
+			file += " (default)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", d.Option, file, d.Runtime)
+	}
+	w.Flush()
+
+	switch {
+	case persist:
+		err := editTree(tree, false, func() error {
+			for _, d := range drifts {
+				if err := config.PersistDrift(cfg, optionDocument(tree, d.Option), d); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved %d runtime value(s) to the config\n", len(drifts))
+	case reset:
+		for _, d := range drifts {
+			if err := config.ResetDrift(client, d); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Reset %d runtime value(s) to the file values\n", len(drifts))
+	}
+	return nil
+}
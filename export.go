@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+var exportFlags = []flagSpec{
+	{"format", "format", "json", "output `format`: json or nix", completeChoices},
+	{"o", "file", "", "write to this `file` instead of stdout", completeFiles},
+	{"profile", "name", "", "save the export as the profile `name`", completeProfiles},
+}
+
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, output := flagString(fs, "format"), flagString(fs, "o")
+	if profile := flagString(fs, "profile"); profile != "" {
+		if output != "" || format != "json" {
+			return fmt.Errorf("--profile saves JSON and cannot be combined with -o or --format")
+		}
+		path, err := config.ProfilePath(profile)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		output = path
+	}
+
+	tree, err := config.LoadConfigTree(configPath)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case "json":
+		return config.ExportConfig(tree).WriteJSON(w)
+	case "nix":
+		return config.WriteNix(w, tree.Config)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+var importFlags = []flagSpec{
+	{"dry-run", "", "", "print the resulting diff instead of writing the files", completeNone},
+	{"format", "format", "", "input `format`: json, nix or nix-json (default: nix for .nix files, else json)", completeChoices},
+	{"profile", "name", "", "apply the saved profile `name` instead of a file", completeProfiles},
+}
+
+func runImport(args []string) error {
+	fs := newFlagSet("import")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax import [--dry-run] [--format FORMAT] FILE|-")
+		fmt.Fprintln(os.Stderr, "       hyprmax import [--dry-run] --profile NAME")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dryRun, format := flagBool(fs, "dry-run"), flagString(fs, "format")
+
+	var name string
+	if profile := flagString(fs, "profile"); profile != "" {
+		if fs.NArg() != 0 {
+			fs.Usage()
+			return fmt.Errorf("--profile takes the place of the export file")
+		}
+		path, err := config.ProfilePath(profile)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("no profile %q: %w", profile, err)
+		}
+		name = path
+	} else if fs.NArg() == 1 {
+		name = fs.Arg(0)
+	} else {
+		fs.Usage()
+		return fmt.Errorf("need one export file")
+	}
+
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return err
+	}
+	if format == "" {
+		format = "json"
+		if filepath.Ext(name) == ".nix" {
+			format = "nix"
+		}
+	}
+
+	var e *config.Export
+	switch format {
+	case "json":
+		e, err = config.ReadExport(bytes.NewReader(data))
+	case "nix":
+		e, err = config.ParseNix(name, data)
+	case "nix-json":
+		e, err = config.ParseNixJSON(name, data)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	tree, err := config.LoadConfigTree(configPath)
+	if err != nil {
+		return err
+	}
+	before := make(map[*config.Document]string)
+	for _, doc := range tree.Documents {
+		before[doc] = doc.String()
+	}
+
+	changed, err := config.ImportConfig(tree, e)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	for _, doc := range changed {
+		if dryRun {
+			fmt.Print(config.UnifiedDiff(doc.Path, doc.Path+" (imported)", before[doc], doc.String()))
+			continue
+		}
+		if err := config.WriteDocument(doc); err != nil {
+			return err
+		}
+		fmt.Printf("Updated %s\n", doc.Path)
+	}
+	return nil
+}
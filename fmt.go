@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+var fmtFlags = []flagSpec{
+	{"check", "", "", "only list files that are not formatted and fail if there are any", completeNone},
+	{"dry-run", "", "", "print the resulting diff instead of writing the files", completeNone},
+	{"sort-binds", "", "", "order each run of binds by modifiers and key", completeNone},
+}
+
+func runFmt(args []string) error {
+	fs := newFlagSet("fmt")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax fmt [--check] [--dry-run] [--sort-binds] [FILE...]")
+		fmt.Fprintln(os.Stderr, "\nWithout files the config and every file it sources are formatted.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	check, dryRun, sortBinds := flagBool(fs, "check"), flagBool(fs, "dry-run"), flagBool(fs, "sort-binds")
+
+	var docs []*config.Document
+	if fs.NArg() == 0 {
+		tree, err := config.LoadConfigTree(configPath)
+		if err != nil {
+			return err
+		}
+		docs = tree.Documents
+	}
+	for _, file := range fs.Args() {
+		doc, err := config.LoadDocument(file)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	formatter := config.Formatter{SortBinds: sortBinds}
+	unformatted := 0
+	for _, doc := range docs {
+		before := doc.String()
+		formatter.Format(doc)
+		after := doc.String()
+		if after == before {
+			continue
+		}
+		unformatted++
+
+		switch {
+		case dryRun:
+			fmt.Print(config.UnifiedDiff(doc.Path, doc.Path+" (formatted)", before, after))
+		case check:
+			fmt.Println(doc.Path)
+		default:
+			if err := config.WriteDocument(doc); err != nil {
+				return err
+			}
+			fmt.Printf("Formatted %s\n", doc.Path)
+		}
+	}
+
+	if check && unformatted > 0 {
+		return fmt.Errorf("%d file(s) need formatting", unformatted)
+	}
+	return nil
+}
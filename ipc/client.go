@@ -0,0 +1,200 @@
+package ipc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a single request/response round trip
+const DefaultTimeout = 2 * time.Second
+
+var (
+	// ErrNoInstance is returned when no running Hyprland instance can be found
+	ErrNoInstance = errors.New("no running Hyprland instance (HYPRLAND_INSTANCE_SIGNATURE not set)")
+
+	// ErrTimeout is returned when Hyprland does not answer in time
+	ErrTimeout = errors.New("hyprland did not respond in time")
+)
+
+// Client talks to Hyprland's request socket (.socket.sock)
+type Client struct {
+	SocketPath string
+	Timeout    time.Duration
+}
+
+// NewClient locates the request socket of the running Hyprland instance
+func NewClient() (*Client, error) {
+	dir, err := SocketDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		SocketPath: filepath.Join(dir, ".socket.sock"),
+		Timeout:    DefaultTimeout,
+	}, nil
+}
+
+// SocketDir returns the runtime directory of the current Hyprland instance
+func SocketDir() (string, error) {
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if signature == "" {
+		return "", ErrNoInstance
+	}
+
+	// Hyprland >= 0.40 uses $XDG_RUNTIME_DIR, older versions used /tmp
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		dir := filepath.Join(runtimeDir, "hypr", signature)
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+	}
+	dir := filepath.Join("/tmp", "hypr", signature)
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("hyprland socket directory not found: %w", err)
+	}
+	return dir, nil
+}
+
+// Request sends a raw command and returns the complete response
+func (c *Client) Request(cmd string) ([]byte, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	conn, err := net.DialTimeout("unix", c.SocketPath, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to hyprland: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return nil, fmt.Errorf("failed to send %q: %w", cmd, wrapTimeout(err))
+	}
+
+	resp, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response to %q: %w", cmd, wrapTimeout(err))
+	}
+	return resp, nil
+}
+
+// Batch sends several commands in one round trip and returns the
+// concatenated responses
+func (c *Client) Batch(cmds ...string) ([]byte, error) {
+	return c.Request("[[BATCH]]" + strings.Join(cmds, ";"))
+}
+
+// Monitors returns the connected monitors
+func (c *Client) Monitors() ([]Monitor, error) {
+	var monitors []Monitor
+	return monitors, c.requestJSON("monitors", &monitors)
+}
+
+// Windows returns all mapped windows (hyprctl clients)
+func (c *Client) Windows() ([]Window, error) {
+	var windows []Window
+	return windows, c.requestJSON("clients", &windows)
+}
+
+// Workspaces returns the existing workspaces
+func (c *Client) Workspaces() ([]Workspace, error) {
+	var workspaces []Workspace
+	return workspaces, c.requestJSON("workspaces", &workspaces)
+}
+
+// Binds returns the keybindings currently registered in the compositor
+func (c *Client) Binds() ([]Bind, error) {
+	var binds []Bind
+	return binds, c.requestJSON("binds", &binds)
+}
+
+// Devices returns the input devices known to the compositor
+func (c *Client) Devices() (*Devices, error) {
+	devices := &Devices{}
+	return devices, c.requestJSON("devices", devices)
+}
+
+// Version returns the version of the running compositor
+func (c *Client) Version() (*Version, error) {
+	version := &Version{}
+	return version, c.requestJSON("version", version)
+}
+
+// GetOption returns the runtime value of an option, e.g. "general:gaps_in"
+func (c *Client) GetOption(name string) (*Option, error) {
+	option := &Option{}
+	if err := c.requestJSON("getoption "+name, option); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
+
+// Keyword sets an option at runtime without touching the config file
+func (c *Client) Keyword(name, value string) error {
+	return c.expectOK("keyword " + name + " " + value)
+}
+
+// Reload asks Hyprland to re-read its configuration
+func (c *Client) Reload() error {
+	return c.expectOK("reload")
+}
+
+// ConfigErrors returns the errors Hyprland reported for the current config
+func (c *Client) ConfigErrors() ([]string, error) {
+	resp, err := c.Request("configerrors")
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	for _, line := range strings.Split(string(resp), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			errs = append(errs, line)
+		}
+	}
+	return errs, nil
+}
+
+// requestJSON sends cmd with the JSON flag and decodes the response into v
+func (c *Client) requestJSON(cmd string, v interface{}) error {
+	resp, err := c.Request("j/" + cmd)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resp, v); err != nil {
+		// Hyprland answers errors in plain text even for JSON requests
+		return fmt.Errorf("%s: %s", cmd, strings.TrimSpace(string(resp)))
+	}
+	return nil
+}
+
+// expectOK sends cmd and fails unless Hyprland answers "ok"
+func (c *Client) expectOK(cmd string) error {
+	resp, err := c.Request(cmd)
+	if err != nil {
+		return err
+	}
+	if reply := strings.TrimSpace(string(resp)); reply != "ok" {
+		return fmt.Errorf("%s: %s", cmd, reply)
+	}
+	return nil
+}
+
+func wrapTimeout(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrTimeout
+	}
+	return err
+}
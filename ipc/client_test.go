@@ -0,0 +1,155 @@
+package ipc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *FakeServer {
+	t.Helper()
+	s, err := NewFakeServer(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFakeServer() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestClientDecodesJSON(t *testing.T) {
+	s := newTestServer(t)
+	s.Respond("j/monitors", `[{"id":0,"name":"eDP-1","width":1920,"height":1080,
+		"refreshRate":60.0,"x":0,"y":0,"activeWorkspace":{"id":1,"name":"1"},"scale":1.25,"focused":true}]`)
+	s.Respond("j/clients", `[{"address":"0x1","class":"kitty","title":"zsh","workspace":{"id":2,"name":"2"},"fullscreen":true},
+		{"address":"0x2","class":"firefox","fullscreen":2}]`)
+	s.Respond("j/version", `{"branch":"main","tag":"v0.41.2-5-gabcdef"}`)
+
+	c := s.Client()
+
+	monitors, err := c.Monitors()
+	if err != nil {
+		t.Fatalf("Monitors() error = %v", err)
+	}
+	if len(monitors) != 1 || monitors[0].Name != "eDP-1" || monitors[0].Scale != 1.25 {
+		t.Errorf("Monitors() = %+v", monitors)
+	}
+
+	windows, err := c.Windows()
+	if err != nil {
+		t.Fatalf("Windows() error = %v", err)
+	}
+	if len(windows) != 2 || windows[0].Fullscreen != 1 || windows[1].Fullscreen != 2 {
+		t.Errorf("Windows() = %+v", windows)
+	}
+
+	version, err := c.Version()
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if got := version.Semver(); got != "0.41.2" {
+		t.Errorf("Semver() = %q, want 0.41.2", got)
+	}
+}
+
+func TestGetOption(t *testing.T) {
+	s := newTestServer(t)
+	s.Handle("j/getoption", func(args string) string {
+		switch args {
+		case "general:gaps_in":
+			return `{"option":"general:gaps_in","custom":"5 5 5 5","set":true}`
+		case "decoration:active_opacity":
+			return `{"option":"decoration:active_opacity","float":0.95,"set":true}`
+		case "general:border_size":
+			return `{"option":"general:border_size","int":2,"set":false}`
+		}
+		return "no such option"
+	})
+
+	c := s.Client()
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"general:gaps_in", "5 5 5 5", false},
+		{"decoration:active_opacity", "0.95", false},
+		{"general:border_size", "2", false},
+		{"general:nope", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := c.GetOption(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetOption() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && opt.Value() != tt.want {
+				t.Errorf("Value() = %q, want %q", opt.Value(), tt.want)
+			}
+		})
+	}
+}
+
+func TestKeywordAndBatch(t *testing.T) {
+	s := newTestServer(t)
+	s.Handle("keyword", func(args string) string {
+		if args == "general:gaps_in nope" {
+			return "invalid value"
+		}
+		return "ok"
+	})
+
+	c := s.Client()
+	if err := c.Keyword("general:gaps_in", "8"); err != nil {
+		t.Errorf("Keyword() error = %v", err)
+	}
+	if err := c.Keyword("general:gaps_in", "nope"); err == nil {
+		t.Error("Keyword() accepted a rejected value")
+	}
+
+	resp, err := c.Batch("keyword general:gaps_in 4", "keyword general:gaps_out 8")
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	if string(resp) != "okok" {
+		t.Errorf("Batch() = %q", resp)
+	}
+	if got := len(s.Requests()); got != 4 {
+		t.Errorf("server saw %d requests, want 4", got)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	s := newTestServer(t)
+	s.Handle("reload", func(string) string {
+		time.Sleep(200 * time.Millisecond)
+		return "ok"
+	})
+
+	c := s.Client()
+	c.Timeout = 20 * time.Millisecond
+	if err := c.Reload(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Reload() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestSocketDir(t *testing.T) {
+	runtime := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(runtime, "hypr", "abc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", runtime)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "abc")
+	dir, err := SocketDir()
+	if err != nil || dir != filepath.Join(runtime, "hypr", "abc") {
+		t.Errorf("SocketDir() = %q, %v", dir, err)
+	}
+
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	if _, err := SocketDir(); !errors.Is(err, ErrNoInstance) {
+		t.Errorf("SocketDir() error = %v, want ErrNoInstance", err)
+	}
+}
@@ -0,0 +1,195 @@
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Event is a message from Hyprland's event socket (.socket2.sock). Events
+// are plain values so they can be passed to a bubbletea program as tea.Msg.
+type Event interface {
+	EventName() string
+}
+
+// MonitorAdded is sent when a monitor is plugged in
+type MonitorAdded struct{ Name string }
+
+// MonitorRemoved is sent when a monitor is unplugged
+type MonitorRemoved struct{ Name string }
+
+// WorkspaceChanged is sent when the active workspace changes
+type WorkspaceChanged struct{ Name string }
+
+// FocusedMonitor is sent when the focus moves to another monitor
+type FocusedMonitor struct{ Monitor, Workspace string }
+
+// ActiveWindow is sent when the focused window changes
+type ActiveWindow struct{ Class, Title string }
+
+// OpenWindow is sent when a window is mapped
+type OpenWindow struct{ Address, Workspace, Class, Title string }
+
+// CloseWindow is sent when a window is unmapped
+type CloseWindow struct{ Address string }
+
+// SubmapChanged is sent when a keybind submap is entered or left
+type SubmapChanged struct{ Name string }
+
+// ConfigReloaded is sent after Hyprland re-read its configuration
+type ConfigReloaded struct{}
+
+// UnknownEvent carries events hyprmax does not model
+type UnknownEvent struct{ Name, Data string }
+
+func (MonitorAdded) EventName() string     { return "monitoradded" }
+func (MonitorRemoved) EventName() string   { return "monitorremoved" }
+func (WorkspaceChanged) EventName() string { return "workspace" }
+func (FocusedMonitor) EventName() string   { return "focusedmon" }
+func (ActiveWindow) EventName() string     { return "activewindow" }
+func (OpenWindow) EventName() string       { return "openwindow" }
+func (CloseWindow) EventName() string      { return "closewindow" }
+func (SubmapChanged) EventName() string    { return "submap" }
+func (ConfigReloaded) EventName() string   { return "configreloaded" }
+func (e UnknownEvent) EventName() string   { return e.Name }
+
+// ParseEvent parses a single "EVENT>>DATA" line
+func ParseEvent(line string) (Event, error) {
+	name, data, ok := strings.Cut(strings.TrimRight(line, "\n"), ">>")
+	if !ok {
+		return nil, fmt.Errorf("malformed event: %q", line)
+	}
+
+	// fields splits data into n comma separated parts, the last one keeping
+	// any further commas since titles may contain them
+	fields := func(n int) []string {
+		parts := strings.SplitN(data, ",", n)
+		for len(parts) < n {
+			parts = append(parts, "")
+		}
+		return parts
+	}
+
+	switch name {
+	case "monitoradded":
+		return MonitorAdded{Name: data}, nil
+	case "monitorremoved":
+		return MonitorRemoved{Name: data}, nil
+	case "workspace":
+		return WorkspaceChanged{Name: data}, nil
+	case "focusedmon":
+		f := fields(2)
+		return FocusedMonitor{Monitor: f[0], Workspace: f[1]}, nil
+	case "activewindow":
+		f := fields(2)
+		return ActiveWindow{Class: f[0], Title: f[1]}, nil
+	case "openwindow":
+		f := fields(4)
+		return OpenWindow{Address: "0x" + f[0], Workspace: f[1], Class: f[2], Title: f[3]}, nil
+	case "closewindow":
+		return CloseWindow{Address: "0x" + data}, nil
+	case "submap":
+		return SubmapChanged{Name: data}, nil
+	case "configreloaded":
+		return ConfigReloaded{}, nil
+	}
+	return UnknownEvent{Name: name, Data: data}, nil
+}
+
+// Default reconnect backoff of the event listener
+const (
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// Listener reads events from the event socket, reconnecting with
+// exponential backoff when the connection drops
+type Listener struct {
+	SocketPath string
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewListener locates the event socket of the running Hyprland instance
+func NewListener() (*Listener, error) {
+	dir, err := SocketDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Listener{
+		SocketPath: filepath.Join(dir, ".socket2.sock"),
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}, nil
+}
+
+// Subscribe starts listening in the background. The returned channel is
+// closed once ctx is cancelled.
+func (l *Listener) Subscribe(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		l.Listen(ctx, events)
+	}()
+	return events
+}
+
+// Listen delivers events until ctx is cancelled and returns ctx.Err()
+func (l *Listener) Listen(ctx context.Context, events chan<- Event) error {
+	minBackoff, maxBackoff := l.MinBackoff, l.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	backoff := minBackoff
+	for {
+		if l.listenOnce(ctx, events) {
+			backoff = minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// listenOnce reads from a single connection until it drops and reports
+// whether the connection could be established at all
+func (l *Listener) listenOnce(ctx context.Context, events chan<- Event) bool {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", l.SocketPath)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	// Unblock the scanner when the listener is stopped
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		event, err := ParseEvent(scanner.Text())
+		if err != nil {
+			continue
+		}
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return true
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package ipc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Event
+		wantErr bool
+	}{
+		{line: "monitoradded>>HDMI-A-1", want: MonitorAdded{Name: "HDMI-A-1"}},
+		{line: "monitorremoved>>DP-2", want: MonitorRemoved{Name: "DP-2"}},
+		{line: "workspace>>3", want: WorkspaceChanged{Name: "3"}},
+		{line: "activewindow>>kitty,~: ls, la", want: ActiveWindow{Class: "kitty", Title: "~: ls, la"}},
+		{line: "openwindow>>80e62df0,2,firefox,Mozilla Firefox",
+			want: OpenWindow{Address: "0x80e62df0", Workspace: "2", Class: "firefox", Title: "Mozilla Firefox"}},
+		{line: "configreloaded>>", want: ConfigReloaded{}},
+		{line: "urgent>>80e62df0", want: UnknownEvent{Name: "urgent", Data: "80e62df0"}},
+		{line: "garbage", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := ParseEvent(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEvent() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListenerReconnects(t *testing.T) {
+	server, err := NewFakeEventServer(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFakeEventServer() error = %v", err)
+	}
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := server.Listener().Subscribe(ctx)
+
+	next := func() Event {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+			return nil
+		}
+	}
+
+	if !server.WaitConnected(time.Second) {
+		t.Fatal("listener did not connect")
+	}
+	server.Emit("monitoradded>>HDMI-A-1")
+	if got := next(); got != (MonitorAdded{Name: "HDMI-A-1"}) {
+		t.Errorf("got %#v", got)
+	}
+
+	// A compositor restart drops the connection, the listener must come back
+	server.Disconnect()
+	if !server.WaitConnected(time.Second) {
+		t.Fatal("listener did not reconnect")
+	}
+	server.Emit("configreloaded>>")
+	if got := next(); got != (ConfigReloaded{}) {
+		t.Errorf("got %#v", got)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("received event after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Error("event channel not closed after cancel")
+	}
+}
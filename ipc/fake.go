@@ -0,0 +1,197 @@
+package ipc
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HandlerFunc answers a fake request; args is everything after the command
+type HandlerFunc func(args string) string
+
+// FakeServer is an in-process stand-in for Hyprland's request socket so
+// code using Client can be tested without a running compositor
+type FakeServer struct {
+	SocketPath string
+
+	ln       net.Listener
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	requests []string
+}
+
+// NewFakeServer listens on a .socket.sock inside dir
+func NewFakeServer(dir string) (*FakeServer, error) {
+	path := filepath.Join(dir, ".socket.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FakeServer{
+		SocketPath: path,
+		ln:         ln,
+		handlers:   make(map[string]HandlerFunc),
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Handle registers fn for a command such as "reload" or "j/getoption"
+func (s *FakeServer) Handle(cmd string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[cmd] = fn
+}
+
+// Respond registers a fixed response for a command
+func (s *FakeServer) Respond(cmd, response string) {
+	s.Handle(cmd, func(string) string { return response })
+}
+
+// Requests returns every command received so far, batches split up
+func (s *FakeServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Client returns a client connected to the fake server
+func (s *FakeServer) Client() *Client {
+	return &Client{SocketPath: s.SocketPath, Timeout: time.Second}
+}
+
+// Close stops accepting connections
+func (s *FakeServer) Close() error {
+	return s.ln.Close()
+}
+
+func (s *FakeServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			buf := make([]byte, 64*1024)
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(s.dispatch(string(buf[:n]))))
+		}()
+	}
+}
+
+func (s *FakeServer) dispatch(request string) string {
+	if batch, ok := strings.CutPrefix(request, "[[BATCH]]"); ok {
+		var sb strings.Builder
+		for _, cmd := range strings.Split(batch, ";") {
+			sb.WriteString(s.answer(strings.TrimSpace(cmd)))
+		}
+		return sb.String()
+	}
+	return s.answer(request)
+}
+
+func (s *FakeServer) answer(request string) string {
+	cmd, args, _ := strings.Cut(request, " ")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	fn, ok := s.handlers[request]
+	if !ok {
+		fn, ok = s.handlers[cmd]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return "unknown request"
+	}
+	return fn(args)
+}
+
+// FakeEventServer is an in-process stand-in for Hyprland's event socket
+type FakeEventServer struct {
+	SocketPath string
+
+	ln        net.Listener
+	mu        sync.Mutex
+	conns     []net.Conn
+	connected chan struct{}
+}
+
+// NewFakeEventServer listens on a .socket2.sock inside dir
+func NewFakeEventServer(dir string) (*FakeEventServer, error) {
+	path := filepath.Join(dir, ".socket2.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FakeEventServer{
+		SocketPath: path,
+		ln:         ln,
+		connected:  make(chan struct{}, 16),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+			s.connected <- struct{}{}
+		}
+	}()
+	return s, nil
+}
+
+// Listener returns a listener for the fake server with short backoffs
+func (s *FakeEventServer) Listener() *Listener {
+	return &Listener{
+		SocketPath: s.SocketPath,
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	}
+}
+
+// WaitConnected blocks until a listener connects or the timeout expires
+func (s *FakeEventServer) WaitConnected(timeout time.Duration) bool {
+	select {
+	case <-s.connected:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Emit sends a raw "EVENT>>DATA" line to every connected listener
+func (s *FakeEventServer) Emit(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Write([]byte(line + "\n"))
+	}
+}
+
+// Disconnect drops all listener connections, as a compositor restart would
+func (s *FakeEventServer) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// Close stops the server and drops all connections
+func (s *FakeEventServer) Close() error {
+	s.Disconnect()
+	return s.ln.Close()
+}
@@ -0,0 +1,191 @@
+package ipc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// WorkspaceRef identifies a workspace inside other objects
+type WorkspaceRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Monitor is an entry of j/monitors
+type Monitor struct {
+	ID               int          `json:"id"`
+	Name             string       `json:"name"`
+	Description      string       `json:"description"`
+	Make             string       `json:"make"`
+	Model            string       `json:"model"`
+	Width            int          `json:"width"`
+	Height           int          `json:"height"`
+	RefreshRate      float64      `json:"refreshRate"`
+	X                int          `json:"x"`
+	Y                int          `json:"y"`
+	ActiveWorkspace  WorkspaceRef `json:"activeWorkspace"`
+	SpecialWorkspace WorkspaceRef `json:"specialWorkspace"`
+	Scale            float64      `json:"scale"`
+	Transform        int          `json:"transform"`
+	Focused          bool         `json:"focused"`
+	DPMSStatus       bool         `json:"dpmsStatus"`
+	VRR              bool         `json:"vrr"`
+	Disabled         bool         `json:"disabled"`
+}
+
+// Window is an entry of j/clients
+type Window struct {
+	Address      string       `json:"address"`
+	Mapped       bool         `json:"mapped"`
+	Hidden       bool         `json:"hidden"`
+	At           [2]int       `json:"at"`
+	Size         [2]int       `json:"size"`
+	Workspace    WorkspaceRef `json:"workspace"`
+	Floating     bool         `json:"floating"`
+	Monitor      int          `json:"monitor"`
+	Class        string       `json:"class"`
+	Title        string       `json:"title"`
+	InitialClass string       `json:"initialClass"`
+	InitialTitle string       `json:"initialTitle"`
+	PID          int          `json:"pid"`
+	XWayland     bool         `json:"xwayland"`
+	Pinned       bool         `json:"pinned"`
+	Fullscreen   Fullscreen   `json:"fullscreen"`
+}
+
+// Fullscreen is the fullscreen mode of a window. Hyprland reported a bool
+// before 0.42 and a mode number since.
+type Fullscreen int
+
+func (f *Fullscreen) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true":
+		*f = 1
+		return nil
+	case "false", "null":
+		*f = 0
+		return nil
+	}
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*f = Fullscreen(n)
+	return nil
+}
+
+// Workspace is an entry of j/workspaces
+type Workspace struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Monitor         string `json:"monitor"`
+	MonitorID       int    `json:"monitorID"`
+	Windows         int    `json:"windows"`
+	HasFullscreen   bool   `json:"hasfullscreen"`
+	LastWindow      string `json:"lastwindow"`
+	LastWindowTitle string `json:"lastwindowtitle"`
+}
+
+// Bind is an entry of j/binds
+type Bind struct {
+	Locked         bool   `json:"locked"`
+	Mouse          bool   `json:"mouse"`
+	Release        bool   `json:"release"`
+	Repeat         bool   `json:"repeat"`
+	NonConsuming   bool   `json:"non_consuming"`
+	HasDescription bool   `json:"has_description"`
+	ModMask        int    `json:"modmask"`
+	Submap         string `json:"submap"`
+	Key            string `json:"key"`
+	KeyCode        int    `json:"keycode"`
+	CatchAll       bool   `json:"catch_all"`
+	Description    string `json:"description"`
+	Dispatcher     string `json:"dispatcher"`
+	Arg            string `json:"arg"`
+}
+
+// Devices is the result of j/devices
+type Devices struct {
+	Mice      []Mouse    `json:"mice"`
+	Keyboards []Keyboard `json:"keyboards"`
+	Tablets   []Device   `json:"tablets"`
+	Touch     []Device   `json:"touch"`
+	Switches  []Device   `json:"switches"`
+}
+
+// Device holds the fields shared by all input devices
+type Device struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+}
+
+// Mouse is a pointer device
+type Mouse struct {
+	Device
+	DefaultSpeed float64 `json:"defaultSpeed"`
+}
+
+// Keyboard is a keyboard device and its xkb settings
+type Keyboard struct {
+	Device
+	Rules        string `json:"rules"`
+	Model        string `json:"model"`
+	Layout       string `json:"layout"`
+	Variant      string `json:"variant"`
+	Options      string `json:"options"`
+	ActiveKeymap string `json:"active_keymap"`
+	Main         bool   `json:"main"`
+}
+
+// Version is the result of j/version
+type Version struct {
+	Branch  string `json:"branch"`
+	Commit  string `json:"commit"`
+	Dirty   bool   `json:"dirty"`
+	Tag     string `json:"tag"`
+	Version string `json:"version"`
+}
+
+// Semver returns the release version, e.g. "0.41.2"
+func (v Version) Semver() string {
+	if v.Version != "" {
+		return v.Version
+	}
+	// Older releases only report the git tag, e.g. "v0.41.2-3-gabcdef"
+	tag := strings.TrimPrefix(v.Tag, "v")
+	if i := strings.Index(tag, "-"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// Option is the result of j/getoption. Exactly one of the value fields is
+// set depending on the option type.
+type Option struct {
+	Option string      `json:"option"`
+	Int    *int64      `json:"int,omitempty"`
+	Float  *float64    `json:"float,omitempty"`
+	Str    *string     `json:"str,omitempty"`
+	Vec2   *[2]float64 `json:"vec2,omitempty"`
+	Custom *string     `json:"custom,omitempty"`
+	Set    bool        `json:"set"`
+}
+
+// Value formats the option value the way it would be written in hyprland.conf
+func (o Option) Value() string {
+	switch {
+	case o.Int != nil:
+		return strconv.FormatInt(*o.Int, 10)
+	case o.Float != nil:
+		return strconv.FormatFloat(*o.Float, 'f', -1, 64)
+	case o.Str != nil:
+		return *o.Str
+	case o.Vec2 != nil:
+		return strconv.FormatFloat(o.Vec2[0], 'f', -1, 64) + " " +
+			strconv.FormatFloat(o.Vec2[1], 'f', -1, 64)
+	case o.Custom != nil:
+		return strings.TrimSpace(*o.Custom)
+	}
+	return ""
+}
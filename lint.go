@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+var lintFlags = []flagSpec{
+	{"format", "format", "text", "output `format`: text, json or sarif", completeChoices},
+	{"check-exec", "", "", "report exec entries whose program is not installed", completeNone},
+}
+
+func runLint(args []string) error {
+	fs := newFlagSet("lint")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax lint [--format text|json|sarif] [--check-exec] [FILE...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, checkExec := flagString(fs, "format"), flagBool(fs, "check-exec")
+
+	// The config is not loaded first: reporting its errors is the point
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{configPath}
+	}
+
+	var linter config.Linter
+	if checkExec {
+		linter.Exec = config.NewExecChecker()
+	}
+
+	var diags []config.Diagnostic
+	for _, file := range files {
+		found, err := linter.LintFile(file)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, found...)
+	}
+
+	var err error
+	switch format {
+	case "text":
+		for _, d := range diags {
+			fmt.Println(d)
+		}
+	case "json":
+		err = writeJSON(os.Stdout, diags)
+	case "sarif":
+		err = writeSARIF(os.Stdout, diags)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	errorCount := 0
+	for _, d := range diags {
+		if d.Severity == config.SeverityError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d error(s) found", errorCount)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, diags []config.Diagnostic) error {
+	if diags == nil {
+		diags = []config.Diagnostic{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(diags)
+}
+
+// SARIF 2.1.0, the format code scanning services read
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+func sarifLevel(s config.Severity) string {
+	switch s {
+	case config.SeverityError:
+		return "error"
+	case config.SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func writeSARIF(w io.Writer, diags []config.Diagnostic) error {
+	driver := sarifDriver{Name: "hyprmax", InformationURI: "https://github.com/max-geller/hyprmax"}
+	for _, r := range config.LintRules() {
+		rule := sarifRule{ID: r.ID, ShortDescription: sarifMessage{r.Description}}
+		rule.DefaultConfig.Level = sarifLevel(r.Severity)
+		driver.Rules = append(driver.Rules, rule)
+	}
+
+	results := []sarifResult{}
+	for _, d := range diags {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = d.File
+		// SARIF regions start at 1, file-wide findings point at the top
+		loc.PhysicalLocation.Region.StartLine = max(d.Line, 1)
+		loc.PhysicalLocation.Region.StartColumn = max(d.Col, 1)
+		results = append(results, sarifResult{
+			RuleID:    d.Rule,
+			Level:     sarifLevel(d.Severity),
+			Message:   sarifMessage{d.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{{Tool: sarifTool{driver}, Results: results}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/max-geller/hyprmax/config"
+	"github.com/max-geller/hyprmax/ipc"
 	"github.com/max-geller/hyprmax/ui"
 )
 
@@ -23,8 +28,21 @@ var (
 				PaddingLeft(2).
 				Foreground(lipgloss.Color("#bb9af7")).
 				SetString("► ")
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff6666")).
+			PaddingLeft(4)
+
+	confirmStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#e0af68")).
+			PaddingLeft(4)
 )
 
+// confirmSeconds is how long the user has to keep an applied change before
+// it is rolled back, like display settings dialogs do
+const confirmSeconds = 15
+
 type model struct {
 	config   *config.HyprlandConfig
 	choices  []string
@@ -33,7 +51,32 @@ type model struct {
 	err      error
 	page     page
 	settings ui.SettingsModel
-	saveChan chan<- config.HyprlandConfig
+
+	configPath  string
+	reloader    config.Reloader
+	tx          *config.Transaction
+	confirmLeft int
+
+	client   *ipc.Client
+	preview  *ui.PreviewSession
+	events   <-chan ipc.Event
+	monitors []ipc.Monitor
+	status   string
+}
+
+// applyResultMsg reports the outcome of writing and reloading the config
+type applyResultMsg struct {
+	tx  *config.Transaction
+	err error
+}
+
+// confirmTickMsg counts down the confirmation of an applied change
+type confirmTickMsg struct{}
+
+// monitorsMsg carries the monitor list fetched from the compositor
+type monitorsMsg struct {
+	monitors []ipc.Monitor
+	err      error
 }
 
 type page int
@@ -45,14 +88,30 @@ const (
 	pageAnimations
 	pageInput
 	pageWindowRules
+	pageKeybindings
+	pageDrift
 )
 
-func initialModel(saveChan chan<- config.HyprlandConfig) model {
-	// Use test mode during development
-	cfg, err := config.LoadConfig("", true)
+func initialModel(client *ipc.Client, events <-chan ipc.Event) model {
+	cfg, err := loadConfigTree(configPath)
+
+	// Apply changes transactionally and preview them live when a
+	// compositor is reachable. A sandbox is never pushed to it.
+	var reloader config.Reloader
+	var preview *ui.PreviewSession
+	if client != nil && !sandboxed {
+		reloader = client
+		preview = ui.NewPreviewSession(client)
+	}
+
 	return model{
-		config: cfg,
-		err:    err,
+		config:     cfg,
+		err:        err,
+		configPath: configPath,
+		reloader:   reloader,
+		client:     client,
+		preview:    preview,
+		events:     events,
 		choices: []string{
 			"General Settings",
 			"Decoration",
@@ -60,21 +119,75 @@ func initialModel(saveChan chan<- config.HyprlandConfig) model {
 			"Input",
 			"Window Rules",
 			"Keybindings",
+			"Runtime Drift",
 			"Save & Quit",
 		},
 		selected: make(map[int]struct{}),
 		page:     pageMain,
-		saveChan: saveChan,
 	}
 }
 
+// loadConfigTree loads the config with the files it sources, which
+// config.SaveConfig expects so that sourced entries are kept
+func loadConfigTree(path string) (*config.HyprlandConfig, error) {
+	tree, err := config.LoadConfigTree(path)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Config, nil
+}
+
 func (m model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.waitForEvent(), m.fetchMonitors())
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case applyResultMsg:
+		// Writing reloaded the compositor, which resets previewed keywords
+		m.preview.Forget()
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		if msg.tx == nil {
+			return m, tea.Quit
+		}
+		m.tx = msg.tx
+		m.confirmLeft = confirmSeconds
+		return m, confirmTick()
+	case monitorsMsg:
+		if msg.err == nil {
+			m.monitors = msg.monitors
+		}
+		return m, nil
+	case ipc.Event:
+		return m.handleEvent(msg)
+	case ui.BackMsg:
+		m.page = pageMain
+		return m, nil
+	case confirmTickMsg:
+		if m.tx == nil {
+			return m, nil
+		}
+		m.confirmLeft--
+		if m.confirmLeft <= 0 {
+			return m.rollback(config.ErrNotConfirmed), nil
+		}
+		return m, confirmTick()
 	case tea.KeyMsg:
+		if m.tx != nil {
+			switch msg.String() {
+			case "y", "enter":
+				m.tx.Commit()
+				m.tx = nil
+				return m, tea.Quit
+			case "n", "esc":
+				return m.rollback(nil), nil
+			}
+			return m, nil
+		}
+
 		if m.page != pageMain {
 			// Handle settings pages
 			var newSettings tea.Model
@@ -87,6 +200,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "ctrl+c", "q":
+			if err := m.preview.RevertAll(); err != nil {
+				m.err = err
+			}
 			return m, tea.Quit
 		case "up", "k":
 			if m.cursor > 0 {
@@ -100,21 +216,34 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch m.cursor {
 			case 0: // General Settings
 				m.page = pageGeneral
-				m.settings = ui.NewGeneralSettingsModel(m.config)
+				m.settings = ui.NewGeneralSettingsModel(m.config, m.preview)
 			case 1: // Decoration
 				m.page = pageDecoration
-				m.settings = ui.NewDecorationSettingsModel(m.config)
+				m.settings = ui.NewDecorationSettingsModel(m.config, m.preview)
 			case 2: // Animations
 				m.page = pageAnimations
-				m.settings = ui.NewAnimationsSettingsModel(m.config)
+				m.settings = ui.NewAnimationsSettingsModel(m.config, m.preview)
 			case 3: // Input
 				m.page = pageInput
-				m.settings = ui.NewInputSettingsModel(m.config)
+				m.settings = ui.NewInputSettingsModel(m.config, m.preview)
 			case 4: // Window Rules
 				m.page = pageWindowRules
-				m.settings = ui.NewWindowRulesSettingsModel(m.config)
+				m.settings = ui.NewWindowRulesSettingsModel(m.config, m.preview)
+			case 5: // Keybindings
+				m.page = pageKeybindings
+				m.settings = ui.NewKeybindingsSettingsModel(m.config, m.preview)
+			case 6: // Runtime Drift
+				if m.client == nil {
+					m.err = ipc.ErrNoInstance
+					return m, nil
+				}
+				m.page = pageDrift
+				m.settings = ui.NewDriftModel(m.config, m.configPath, m.client)
 			case len(m.choices) - 1:
-				return m, tea.Quit
+				if m.config == nil {
+					return m, tea.Quit
+				}
+				return m, m.applyConfig()
 			default:
 				_, ok := m.selected[m.cursor]
 				if ok {
@@ -124,6 +253,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
+	default:
+		// Settings pages get the results of the commands they started
+		if m.page != pageMain {
+			newSettings, cmd := m.settings.Update(msg)
+			if settingsModel, ok := newSettings.(ui.SettingsModel); ok {
+				m.settings = settingsModel
+			}
+			return m, cmd
+		}
 	}
 	return m, nil
 }
@@ -140,28 +278,149 @@ func (m model) View() string {
 		s += fmt.Sprintf("%s%s\n", cursor, itemStyle.Render(choice))
 	}
 	s += "\n"
+
+	if m.tx != nil {
+		s += confirmStyle.Render(fmt.Sprintf(
+			"Keep these changes? Reverting in %ds (y/n)", m.confirmLeft)) + "\n"
+		return s
+	}
+	if len(m.monitors) > 0 {
+		names := make([]string, len(m.monitors))
+		for i, mon := range m.monitors {
+			names[i] = mon.Name
+		}
+		s += itemStyle.Render("Monitors: "+strings.Join(names, ", ")) + "\n"
+	}
+	if m.status != "" {
+		s += itemStyle.Render(m.status) + "\n"
+	}
+	if m.err != nil {
+		s += errorStyle.Render("Error: "+m.err.Error()) + "\n\n"
+	}
 	s += itemStyle.Render("(use arrow keys to navigate, enter to select, q to quit)") + "\n"
 
 	return s
 }
 
-func main() {
-	// Create a channel for config saves
-	saveChan := make(chan config.HyprlandConfig)
-
-	// Start a goroutine to handle config saves
-	go func() {
-		for cfg := range saveChan {
-			if err := config.WriteConfig(&cfg, ""); err != nil {
-				// TODO: Handle error
-				fmt.Printf("Error saving config: %v\n", err)
+// applyConfig saves the changed entries of the config and, when Hyprland is running, reloads it
+// and rolls back automatically if the reload reports new errors
+func (m model) applyConfig() tea.Cmd {
+	cfg, path, reloader := m.config, m.configPath, m.reloader
+	return func() tea.Msg {
+		if reloader == nil {
+			_, err := config.SaveConfig(cfg, path)
+			return applyResultMsg{err: err}
+		}
+		tx, err := config.ApplyConfig(cfg, path, reloader)
+		return applyResultMsg{tx: tx, err: err}
+	}
+}
+
+// handleEvent reacts to compositor events and waits for the next one
+func (m model) handleEvent(event ipc.Event) (tea.Model, tea.Cmd) {
+	cmds := []tea.Cmd{m.waitForEvent()}
+
+	switch event.(type) {
+	case ipc.MonitorAdded, ipc.MonitorRemoved:
+		cmds = append(cmds, m.fetchMonitors())
+	case ipc.ConfigReloaded:
+		// Pick up edits made outside hyprmax, unless a change of ours is
+		// pending confirmation or a settings page holds the old values
+		if m.tx == nil && m.page == pageMain {
+			cfg, err := loadConfigTree(m.configPath)
+			if err == nil {
+				m.config = cfg
+				m.preview.Forget()
+				m.status = "Config reloaded by Hyprland at " + time.Now().Format("15:04:05")
 			}
 		}
-	}()
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// waitForEvent delivers the next compositor event as a tea.Msg
+func (m model) waitForEvent() tea.Cmd {
+	if m.events == nil {
+		return nil
+	}
+	events := m.events
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return event
+	}
+}
+
+func (m model) fetchMonitors() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	client := m.client
+	return func() tea.Msg {
+		monitors, err := client.Monitors()
+		return monitorsMsg{monitors: monitors, err: err}
+	}
+}
+
+// rollback reverts the pending transaction and records why
+func (m model) rollback(reason error) model {
+	if err := m.tx.Rollback(); err != nil {
+		reason = err
+	}
+	m.tx = nil
+	m.err = reason
+	return m
+}
+
+func confirmTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return confirmTickMsg{}
+	})
+}
+
+func main() {
+	args, cleanup, err := selectConfig(os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hyprmax: %v\n", err)
+		os.Exit(2)
+	}
+	if len(args) > 0 {
+		code := runCommand(args)
+		cleanup()
+		os.Exit(code)
+	}
+	defer cleanup()
+
+	// Talk to the compositor when one is running
+	var client *ipc.Client
+	var events <-chan ipc.Event
+	if c, err := ipc.NewClient(); err == nil {
+		client = c
+	}
+	if listener, err := ipc.NewListener(); err == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events = listener.Subscribe(ctx)
+	}
+
+	p := tea.NewProgram(initialModel(client, events))
+	final, err := p.Run()
+
+	// Never leave the compositor in a state that differs from disk
+	if m, ok := final.(model); ok {
+		if err := m.preview.RevertAll(); err != nil {
+			fmt.Printf("Error reverting live preview: %v\n", err)
+		}
+	}
 
-	p := tea.NewProgram(initialModel(saveChan))
-	if _, err := p.Run(); err != nil {
+	if err != nil {
 		fmt.Printf("Error running program: %v", err)
+		cleanup()
 		os.Exit(1)
 	}
 }
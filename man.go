@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var manFlags = []flagSpec{
+	{"dir", "directory", "", "write hyprmax.1 and one page per command to this `directory`", completeFiles},
+}
+
+func runMan(args []string) error {
+	fs := newFlagSet("man")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dir := flagString(fs, "dir")
+
+	if dir == "" {
+		return writeManPage(os.Stdout)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	write := func(name string, page func(w io.Writer) error) error {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if err := page(f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	if err := write("hyprmax.1", writeManPage); err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		cmd := cmd
+		if err := write("hyprmax-"+cmd.name+".1", func(w io.Writer) error { return writeCommandPage(w, cmd) }); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Wrote %d man pages to %s\n", len(commands)+1, dir)
+	return nil
+}
+
+// writeManPage writes hyprmax(1), describing every command and its flags
+func writeManPage(w io.Writer) error {
+	fmt.Fprintln(w, `.TH HYPRMAX 1 "" hyprmax "User Commands"`)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `hyprmax \- settings manager for Hyprland`)
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, `.B hyprmax`)
+	fmt.Fprintln(w, `[\fB\-\-config\fR \fIPATH\fR] [\fB\-\-test\fR | \fB\-\-sandbox\fR] [\fIcommand\fR] [\fIflags\fR]`)
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, "Without a command the interactive settings manager is started.")
+	fmt.Fprintln(w, "The commands read and edit the config file, including the files it sources, keeping comments and layout.")
+
+	fmt.Fprintln(w, ".SH GLOBAL OPTIONS")
+	for _, g := range globalFlags {
+		fmt.Fprintln(w, ".TP")
+		if g.arg != "" {
+			fmt.Fprintf(w, "\\fB\\-\\-%s\\fR \\fI%s\\fR\n", g.name, g.arg)
+		} else {
+			fmt.Fprintf(w, "\\fB\\-\\-%s\\fR\n", g.name)
+		}
+		fmt.Fprintln(w, roff(g.usage))
+	}
+
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, cmd := range commands {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintln(w, commandSynopsis(cmd))
+		fmt.Fprintln(w, roff(capitalize(cmd.summary)))
+		if len(cmd.flags) > 0 {
+			fmt.Fprintln(w, ".RS")
+			writeFlags(w, cmd.flags)
+			fmt.Fprintln(w, ".RE")
+		}
+	}
+
+	fmt.Fprintln(w, ".SH ENVIRONMENT")
+	fmt.Fprintln(w, ".TP")
+	fmt.Fprintln(w, `.B HYPRMAX_CONFIG`)
+	fmt.Fprintln(w, "Config file used when \\fB\\-\\-config\\fR is not given.")
+	fmt.Fprintln(w, ".TP")
+	fmt.Fprintln(w, `.B XDG_CONFIG_HOME`)
+	fmt.Fprintln(w, "The config file is looked up in hypr/hyprland.conf below it.")
+	fmt.Fprintln(w, ".SH FILES")
+	fmt.Fprintln(w, ".TP")
+	fmt.Fprintln(w, `.I ~/.config/hypr/hyprland.conf`)
+	fmt.Fprintln(w, "The default config file. A timestamped backup is written next to each file before it is changed.")
+	fmt.Fprintln(w, ".SH SEE ALSO")
+	_, err := fmt.Fprintln(w, `.BR Hyprland (1)`)
+	return err
+}
+
+// writeCommandPage writes hyprmax-NAME(1) for a single command
+func writeCommandPage(w io.Writer, cmd command) error {
+	fmt.Fprintf(w, ".TH HYPRMAX-%s 1 \"\" hyprmax \"User Commands\"\n", strings.ToUpper(cmd.name))
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintf(w, "hyprmax\\-%s \\- %s\n", cmd.name, roff(cmd.summary))
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, commandSynopsis(cmd))
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, roff(capitalize(cmd.summary))+".")
+	if len(cmd.flags) > 0 {
+		fmt.Fprintln(w, ".SH OPTIONS")
+		writeFlags(w, cmd.flags)
+	}
+	fmt.Fprintln(w, ".SH SEE ALSO")
+	_, err := fmt.Fprintln(w, `.BR hyprmax (1)`)
+	return err
+}
+
+func commandSynopsis(cmd command) string {
+	synopsis := `\fBhyprmax ` + cmd.name + `\fR`
+	if len(cmd.flags) > 0 {
+		synopsis += ` [\fIflags\fR]`
+	}
+	if cmd.args != "" {
+		synopsis += ` \fI` + roff(cmd.args) + `\fR`
+	}
+	return synopsis
+}
+
+// writeFlags writes a tagged paragraph per flag, like flag.PrintDefaults
+func writeFlags(w io.Writer, flags []flagSpec) {
+	for _, f := range flags {
+		fmt.Fprintln(w, ".TP")
+		label := `\fB` + roff(flagLabel(f.name)) + `\fR`
+		if f.arg != "" {
+			label += ` \fI` + strings.ToUpper(f.arg) + `\fR`
+		}
+		fmt.Fprintln(w, label)
+		usage := strings.ReplaceAll(f.usage, "`", "")
+		if f.def != "" {
+			usage += " (default: " + f.def + ")"
+		}
+		fmt.Fprintln(w, roff(capitalize(usage)))
+	}
+}
+
+// roff escapes text for a man page
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
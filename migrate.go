@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/max-geller/hyprmax/config"
+	"github.com/max-geller/hyprmax/ipc"
+)
+
+var migrateFlags = []flagSpec{
+	{"target", "version", "", "Hyprland `version` to migrate to (default: the running version, or the latest)", completeNone},
+	{"apply", "", "", "write the migrated config instead of only showing the changes", completeNone},
+}
+
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	target, apply := flagString(fs, "target"), flagBool(fs, "apply")
+
+	if target == "" {
+		target = runningVersion()
+	}
+
+	// Migration works line by line, so configs that no longer load are
+	// migrated too
+	docs, err := config.LoadDocuments(configPath)
+	if err != nil {
+		return err
+	}
+
+	var changed []*config.Document
+	before := make(map[*config.Document]string)
+	for _, doc := range docs {
+		before[doc] = doc.String()
+		changes := config.Migrate(doc, target)
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+		if len(changes) > 0 {
+			changed = append(changed, doc)
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Println("Nothing to migrate")
+		return nil
+	}
+
+	if !apply {
+		for _, doc := range changed {
+			fmt.Println()
+			fmt.Print(config.UnifiedDiff(doc.Path, doc.Path+" (migrated)", before[doc], doc.String()))
+		}
+		fmt.Println("\nRun with --apply to write these changes")
+		return nil
+	}
+	for _, doc := range changed {
+		if err := config.WriteDocument(doc); err != nil {
+			return err
+		}
+		fmt.Printf("Migrated %s\n", doc.Path)
+	}
+	return nil
+}
+
+// runningVersion returns the version of the running Hyprland, or "" when
+// it cannot be reached
+func runningVersion() string {
+	client, err := ipc.NewClient()
+	if err != nil {
+		return ""
+	}
+	version, err := client.Version()
+	if err != nil {
+		return ""
+	}
+	return version.Semver()
+}
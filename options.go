@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+func runGet(args []string) error {
+	fs := newFlagSet("get")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax get OPTION...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no option given")
+	}
+
+	tree, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	for _, path := range fs.Args() {
+		value, err := tree.Config.GetOption(path)
+		if err != nil {
+			return err
+		}
+		// A single option prints the bare value so scripts can use it as is
+		if fs.NArg() == 1 {
+			fmt.Println(value)
+		} else {
+			fmt.Printf("%s = %s\n", path, value)
+		}
+	}
+	return nil
+}
+
+func runSet(args []string) error {
+	fs := newFlagSet("set")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax set [--dry-run] OPTION VALUE")
+		fmt.Fprintln(os.Stderr, "\nFlags go before OPTION. Quote values with spaces, e.g. gradients.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Flags after the option would be taken as part of the value
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("need exactly an option and a value, got %d argument(s)", fs.NArg())
+	}
+	path, value := fs.Arg(0), fs.Arg(1)
+
+	tree, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := tree.Config.CheckOption(path, value); err != nil {
+		return err
+	}
+
+	return editTree(tree, flagBool(fs, "dry-run"), func() error {
+		return optionDocument(tree, path).Set(path, value)
+	})
+}
+
+func runUnset(args []string) error {
+	fs := newFlagSet("unset")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax unset [--dry-run] OPTION...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no option given")
+	}
+
+	tree, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return editTree(tree, flagBool(fs, "dry-run"), func() error {
+		// Every assignment goes, or an earlier one would take over
+		for _, path := range fs.Args() {
+			found := false
+			for _, doc := range tree.Documents {
+				if doc.Delete(path) {
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s is not set in the config", path)
+			}
+		}
+		return nil
+	})
+}
+
+// optionDocument returns the document holding the assignment Hyprland uses
+// for path, or the main file when the option is not set
+func optionDocument(tree *config.ConfigTree, path string) *config.Document {
+	if doc := tree.Document(tree.Config.OptionFile(path)); doc != nil {
+		return doc
+	}
+	return tree.Documents[0]
+}
+
+// editTree applies edit to the documents of tree, keeping their layout and
+// comments, and writes the ones that changed. With dryRun the diffs are
+// printed and nothing is written.
+func editTree(tree *config.ConfigTree, dryRun bool, edit func() error) error {
+	before := make([]string, len(tree.Documents))
+	for i, doc := range tree.Documents {
+		before[i] = doc.String()
+	}
+	if err := edit(); err != nil {
+		return err
+	}
+
+	var changed []*config.Document
+	for i, doc := range tree.Documents {
+		if after := doc.String(); after != before[i] {
+			changed = append(changed, doc)
+			if dryRun {
+				fmt.Print(config.UnifiedDiff(doc.Path, doc.Path+" (edited)", before[i], after))
+			}
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+	for _, doc := range changed {
+		if err := config.WriteDocument(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var listFlags = []flagSpec{
+	{"section", "section", "", "only list options in this `section`, e.g. input or decoration:blur", completeSections},
+	{"changed", "", "", "only list options that are set in the config file", completeNone},
+}
+
+func runList(args []string) error {
+	fs := newFlagSet("list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	section, changed := flagString(fs, "section"), flagBool(fs, "changed")
+
+	tree, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg := tree.Config
+	category := strings.Trim(section, ":")
+
+	opts := config.Options()
+	if category != "" {
+		opts = config.OptionsIn(category)
+	}
+
+	// Options the schema does not know about are still part of the file
+	extra := cfg.ExtraOptions()
+	var unknown []string
+	for path := range extra {
+		if category == "" || strings.HasPrefix(path, category+":") {
+			unknown = append(unknown, path)
+		}
+	}
+	sort.Strings(unknown)
+
+	if len(opts) == 0 && len(unknown) == 0 {
+		return fmt.Errorf("unknown section %q", section)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OPTION\tVALUE\tDEFAULT")
+	for _, opt := range opts {
+		if changed && !cfg.IsSet(opt.Path) {
+			continue
+		}
+		value, _ := cfg.GetOption(opt.Path)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", opt.Path, value, opt.Default)
+	}
+	for _, path := range unknown {
+		fmt.Fprintf(w, "%s\t%s\t\n", path, extra[path])
+	}
+	return w.Flush()
+}
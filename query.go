@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/max-geller/hyprmax/config"
+)
+
+var queryFlags = []flagSpec{
+	{"format", "format", "table", "output `format`: table or json", completeChoices},
+	{"fields", "fields", "", "comma separated `fields` to print instead of the default columns", completeNone},
+}
+
+func runQuery(args []string) error {
+	fs := newFlagSet("query")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hyprmax query [--format table|json] [--fields a,b,...] QUERY")
+		fmt.Fprintln(os.Stderr, "\nExamples:")
+		fmt.Fprintln(os.Stderr, "  hyprmax query 'binds[dispatcher=exec && mods~SUPER]'")
+		fmt.Fprintln(os.Stderr, "  hyprmax query 'windowrules[match.class=firefox]'")
+		fmt.Fprintln(os.Stderr, "  hyprmax query 'options[section=decoration && set]'")
+		fmt.Fprintf(os.Stderr, "\nCollections: %s\n", strings.Join(config.QueryCollections(), ", "))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, fields := flagString(fs, "format"), flagString(fs, "fields")
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no query given")
+	}
+
+	// Unquoted queries may arrive split at spaces
+	text := strings.Join(fs.Args(), " ")
+	q, err := config.ParseQuery(text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", text, err)
+	}
+
+	var columns []string
+	if fields != "" {
+		for _, f := range strings.Split(fields, ",") {
+			f = strings.TrimSpace(f)
+			if !q.HasField(f) {
+				return fmt.Errorf("unknown field %q of %s", f, q.Collection)
+			}
+			columns = append(columns, f)
+		}
+	}
+
+	tree, err := config.LoadConfigTree(configPath)
+	if err != nil {
+		return err
+	}
+	res := q.Run(tree.Config)
+
+	switch format {
+	case "table":
+		if columns == nil {
+			columns = res.Columns
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, r := range res.Records {
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				row[i] = r[c]
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	case "json":
+		// Without --fields every field is written, including the
+		// match.FIELD ones of window rules
+		records := []config.QueryRecord{}
+		for _, r := range res.Records {
+			if columns != nil {
+				picked := make(config.QueryRecord)
+				for _, c := range columns {
+					picked[c] = r[c]
+				}
+				r = picked
+			}
+			records = append(records, r)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -7,4 +7,4 @@ go test ./...
 go build
 
 # Run with test configuration
-./hyprmax 
\ No newline at end of file
+./hyprmax --test 
\ No newline at end of file
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/max-geller/hyprmax/config"
+)
+
+// driftModel lists options whose runtime value differs from the file
+type driftModel struct {
+	config   *config.HyprlandConfig
+	path     string
+	runtime  config.RuntimeOptions
+	drifts   []config.Drift
+	cursor   int
+	status   string
+	errorMsg string
+}
+
+// NewDriftModel compares the loaded config against the running compositor
+func NewDriftModel(cfg *config.HyprlandConfig, path string, runtime config.RuntimeOptions) SettingsModel {
+	m := driftModel{
+		config:  cfg,
+		path:    path,
+		runtime: runtime,
+	}
+	drifts, err := config.DetectDrift(cfg, runtime)
+	if err != nil {
+		m.errorMsg = err.Error()
+	}
+	m.drifts = drifts
+	return m
+}
+
+func (m driftModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m driftModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return m, back
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.drifts)-1 {
+				m.cursor++
+			}
+		case "p":
+			if len(m.drifts) > 0 {
+				m.persist()
+			}
+		case "r":
+			if len(m.drifts) > 0 {
+				m.reset()
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m driftModel) View() string {
+	var s string
+	s += titleStyle.Render("Runtime Drift") + "\n\n"
+
+	if m.errorMsg != "" {
+		s += errorStyle.Render("Error: "+m.errorMsg) + "\n\n"
+	}
+	if m.status != "" {
+		s += helpStyle.Render(m.status) + "\n\n"
+	}
+	if len(m.drifts) == 0 && m.errorMsg == "" {
+		s += settingStyle.Render("Runtime matches the config file") + "\n"
+	}
+
+	for i, d := range m.drifts {
+		cursor := " "
+		if m.cursor == i {
+			cursor = "► "
+		}
+		file := d.File
+		if d.Default {
+			file += " (default)"
+		}
+		s += fmt.Sprintf("%s%s: %s → %s\n",
+			cursor,
+			settingStyle.Render(d.Option),
+			valueStyle.Render(file),
+			editStyle.Render(d.Runtime))
+	}
+
+	s += "\n" + itemStyle.Render("(↑/↓) navigate • (p) persist runtime value to file • (r) reset runtime to file value • (esc) back")
+	return s
+}
+
+// persist writes the selected runtime value into the config file
+func (m *driftModel) persist() {
+	d := m.drifts[m.cursor]
+	doc, err := config.LoadDocument(m.path)
+	if err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	if err := config.PersistDrift(m.config, doc, d); err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	if err := config.WriteDocument(doc); err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	m.resolve(fmt.Sprintf("Saved %s = %s to %s", d.Option, d.Runtime, m.path))
+}
+
+// reset sets the selected option back to its value from the file
+func (m *driftModel) reset() {
+	d := m.drifts[m.cursor]
+	if err := config.ResetDrift(m.runtime, d); err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	m.resolve(fmt.Sprintf("Reset %s to %s", d.Option, d.File))
+}
+
+func (m *driftModel) resolve(status string) {
+	m.drifts = append(m.drifts[:m.cursor:m.cursor], m.drifts[m.cursor+1:]...)
+	if m.cursor >= len(m.drifts) && m.cursor > 0 {
+		m.cursor--
+	}
+	m.status = status
+	m.errorMsg = ""
+}
@@ -2,7 +2,10 @@ package ui
 
 import (
 	"fmt"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/max-geller/hyprmax/config"
 )
 
 // EditorMode represents different editing modes
@@ -20,9 +23,20 @@ type editorModel struct {
 	mode       EditorMode
 	fields     []editorField
 	cursor     int
+	editing    bool
+	errorMsg   string
+	vars       map[string]string // config $variables, for expanding modifiers
 	parentView SettingsModel
 }
 
+// Field positions in the bind editor
+const (
+	bindFieldMods = iota
+	bindFieldKey
+	bindFieldAction
+	bindFieldParams
+)
+
 type editorField struct {
 	name     string
 	value    string
@@ -44,7 +58,12 @@ func NewRuleEditor(parent SettingsModel) editorModel {
 
 // NewBindEditor creates a new keybinding editor
 func NewBindEditor(parent SettingsModel) editorModel {
+	var vars map[string]string
+	if m, ok := parent.(settingsModel); ok && m.config != nil {
+		vars = m.config.Variables
+	}
 	return editorModel{
+		vars:       vars,
 		mode:       ModeNewBind,
 		parentView: parent,
 		fields: []editorField{
@@ -85,10 +104,99 @@ func (e editorModel) View() string {
 			valueStyle.Render(field.value))
 	}
 
-	s += "\n" + itemStyle.Render("(↑/↓) navigate • (enter) edit • (esc) cancel • (ctrl+s) save")
+	if e.mode == ModeNewBind {
+		s += e.bindHints()
+	}
+	if e.errorMsg != "" {
+		s += "\n" + errorStyle.Render(e.errorMsg) + "\n"
+	}
+
+	s += "\n" + itemStyle.Render("(↑/↓) navigate • (enter) edit • (tab) complete • (esc) cancel • (ctrl+s) save")
+	return s
+}
+
+// bindHints describes the selected dispatcher and lists completions for
+// the field under the cursor
+func (e editorModel) bindHints() string {
+	var suggestions []string
+	switch e.cursor {
+	case bindFieldAction:
+		suggestions = config.CompleteDispatcher(e.fields[bindFieldAction].value)
+	case bindFieldParams:
+		if d, ok := config.LookupDispatcher(e.fields[bindFieldAction].value); ok {
+			suggestions = d.Completions()
+		}
+	}
+
+	var s string
+	if d, ok := config.LookupDispatcher(e.fields[bindFieldAction].value); ok {
+		s += "\n" + helpStyle.Render(d.Name+": "+d.Description) + "\n"
+	}
+	if len(suggestions) > 0 {
+		if len(suggestions) > 8 {
+			suggestions = append(suggestions[:8], "…")
+		}
+		s += helpStyle.Render("Suggestions: "+strings.Join(suggestions, " ")) + "\n"
+	}
 	return s
 }
 
+// complete fills in the field under the cursor from the dispatcher catalog
+func (e *editorModel) complete() {
+	field := &e.fields[e.cursor]
+	switch e.cursor {
+	case bindFieldAction:
+		if names := config.CompleteDispatcher(field.value); len(names) > 0 {
+			field.value = commonPrefix(names)
+		}
+	case bindFieldParams:
+		d, ok := config.LookupDispatcher(e.fields[bindFieldAction].value)
+		if !ok {
+			return
+		}
+		var matches []string
+		for _, c := range d.Completions() {
+			if strings.HasPrefix(c, field.value) {
+				matches = append(matches, c)
+			}
+		}
+		if len(matches) > 0 {
+			field.value = commonPrefix(matches)
+		}
+	}
+}
+
+// validateField checks the field under the cursor
+func (e editorModel) validateField() error {
+	if e.mode != ModeNewBind {
+		return nil
+	}
+	value := e.fields[e.cursor].value
+	switch e.cursor {
+	case bindFieldMods:
+		if _, err := config.NormalizeMods(value, e.vars); err != nil {
+			return err
+		}
+	case bindFieldKey:
+		return config.ValidateKey("key", value)
+	case bindFieldAction:
+		return config.ValidateDispatcher("dispatcher", value)
+	case bindFieldParams:
+		return config.ValidateDispatcherParams(e.fields[bindFieldAction].value, value)
+	}
+	return nil
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
 // Add these methods to implement tea.Model
 func (e editorModel) Init() tea.Cmd {
 	return nil
@@ -97,9 +205,32 @@ func (e editorModel) Init() tea.Cmd {
 func (e editorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if e.editing {
+			field := &e.fields[e.cursor]
+			switch msg.String() {
+			case "esc", "enter":
+				e.editing = false
+				e.errorMsg = ""
+				if err := e.validateField(); err != nil {
+					e.errorMsg = err.Error()
+				}
+			case "tab":
+				e.complete()
+			case "backspace":
+				if len(field.value) > 0 {
+					field.value = field.value[:len(field.value)-1]
+				}
+			default:
+				field.value += msg.String()
+			}
+			return e, nil
+		}
+
 		switch msg.String() {
 		case "esc":
 			return e.parentView, nil
+		case "enter":
+			e.editing = true
 		case "up", "k":
 			if e.cursor > 0 {
 				e.cursor--
@@ -8,3 +8,11 @@ type SettingsModel interface {
 	Update(msg tea.Msg) (tea.Model, tea.Cmd)
 	View() string
 }
+
+// BackMsg asks the main menu to close the current settings view
+type BackMsg struct{}
+
+// back is the command a settings view returns to close itself
+func back() tea.Msg {
+	return BackMsg{}
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Previewer pushes option values to the running compositor without
+// touching the config file
+type Previewer interface {
+	Keyword(name, value string) error
+}
+
+// PreviewSession tracks the options pushed to the compositor ahead of saving
+// so they can be reverted to their on-disk values. Pushes made by Push run
+// as commands, so the session is safe for concurrent use.
+type PreviewSession struct {
+	previewer Previewer
+
+	mu       sync.Mutex
+	original map[string]string
+	pushes   map[string]int // number of the newest Push per key
+}
+
+// previewMsg reports the outcome of a Push
+type previewMsg struct {
+	key string
+	err error
+}
+
+// NewPreviewSession returns a session using p, or nil if p is nil so
+// callers can treat a missing compositor as "no preview"
+func NewPreviewSession(p Previewer) *PreviewSession {
+	if p == nil {
+		return nil
+	}
+	return &PreviewSession{
+		previewer: p,
+		original:  make(map[string]string),
+		pushes:    make(map[string]int),
+	}
+}
+
+// Push returns a command doing what Set does, so the IPC round trip does
+// not block the UI. The command reports the outcome as a previewMsg. Pushes
+// run one at a time, and one overtaken by a newer Push of the same key is
+// dropped.
+func (s *PreviewSession) Push(key, value, original string) tea.Cmd {
+	if s == nil || key == "" {
+		return nil
+	}
+	s.mu.Lock()
+	s.pushes[key]++
+	n := s.pushes[key]
+	s.mu.Unlock()
+
+	return func() tea.Msg {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.pushes[key] != n {
+			return nil
+		}
+		return previewMsg{key: key, err: s.set(key, value, original)}
+	}
+}
+
+// Set pushes value for key. original is the on-disk value, remembered the
+// first time key is previewed.
+func (s *PreviewSession) Set(key, value, original string) error {
+	if s == nil || key == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.set(key, value, original)
+}
+
+func (s *PreviewSession) set(key, value, original string) error {
+	if err := s.previewer.Keyword(key, value); err != nil {
+		return err
+	}
+
+	if _, ok := s.original[key]; !ok {
+		s.original[key] = original
+	}
+	if s.original[key] == value {
+		delete(s.original, key)
+	}
+	return nil
+}
+
+// Revert restores the on-disk value of a single key
+func (s *PreviewSession) Revert(key string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	original, ok := s.original[key]
+	if !ok {
+		return nil
+	}
+	if err := s.previewer.Keyword(key, original); err != nil {
+		return err
+	}
+	delete(s.original, key)
+	return nil
+}
+
+// RevertAll restores every previewed option to its on-disk value
+func (s *PreviewSession) RevertAll() error {
+	var failed []string
+	for _, key := range s.Keys() {
+		if err := s.Revert(key); err != nil {
+			failed = append(failed, key)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to revert previewed options: %v", failed)
+	}
+	return nil
+}
+
+// Forget drops all tracked values, e.g. after a reload made the compositor
+// match the file again
+func (s *PreviewSession) Forget() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.original = make(map[string]string)
+}
+
+// Previewing reports whether key currently differs from disk
+func (s *PreviewSession) Previewing(key string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.original[key]
+	return ok
+}
+
+// Keys returns the previewed option names in stable order
+func (s *PreviewSession) Keys() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.original))
+	for key := range s.original {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
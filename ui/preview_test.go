@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+// recordingPreviewer remembers the last value pushed for each option
+type recordingPreviewer struct {
+	values map[string]string
+	fail   bool
+}
+
+func (r *recordingPreviewer) Keyword(name, value string) error {
+	if r.fail {
+		return errors.New("rejected")
+	}
+	r.values[name] = value
+	return nil
+}
+
+func TestPreviewSession(t *testing.T) {
+	p := &recordingPreviewer{values: make(map[string]string)}
+	s := NewPreviewSession(p)
+
+	// Typing "12" previews "1" then "12", the original stays the disk value
+	s.Set("general:gaps_in", "1", "5")
+	s.Set("general:gaps_in", "12", "1")
+	s.Set("decoration:rounding", "4", "10")
+
+	if !s.Previewing("general:gaps_in") || len(s.Keys()) != 2 {
+		t.Fatalf("Keys() = %v, want both options previewed", s.Keys())
+	}
+
+	// Typing back the disk value ends the preview of that option
+	s.Set("decoration:rounding", "10", "4")
+	if s.Previewing("decoration:rounding") {
+		t.Error("option equal to disk value still marked as previewing")
+	}
+
+	if err := s.RevertAll(); err != nil {
+		t.Fatalf("RevertAll() error = %v", err)
+	}
+	if p.values["general:gaps_in"] != "5" {
+		t.Errorf("gaps_in reverted to %q, want 5", p.values["general:gaps_in"])
+	}
+	if len(s.Keys()) != 0 {
+		t.Errorf("Keys() = %v after RevertAll", s.Keys())
+	}
+}
+
+func TestPreviewSessionRejectedValue(t *testing.T) {
+	p := &recordingPreviewer{values: make(map[string]string), fail: true}
+	s := NewPreviewSession(p)
+
+	if err := s.Set("general:layout", "bogus", "dwindle"); err == nil {
+		t.Error("Set() accepted a value the compositor rejected")
+	}
+	if s.Previewing("general:layout") {
+		t.Error("rejected value tracked as previewing")
+	}
+}
+
+func TestNilPreviewSession(t *testing.T) {
+	var s *PreviewSession
+	if err := s.Set("general:gaps_in", "1", "5"); err != nil {
+		t.Errorf("Set() on nil session error = %v", err)
+	}
+	if err := s.RevertAll(); err != nil {
+		t.Errorf("RevertAll() on nil session error = %v", err)
+	}
+}
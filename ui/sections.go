@@ -6,97 +6,74 @@ import (
 	"github.com/max-geller/hyprmax/config"
 )
 
-func NewGeneralSettingsModel(cfg *config.HyprlandConfig) SettingsModel {
-	return settingsModel{
-		config:  cfg,
-		section: "General",
-		settings: []setting{
-			{"Border Size", cfg.General.BorderSize, true},
-			{"Gaps In", cfg.General.GapIn, true},
-			{"Gaps Out", cfg.General.GapOut, true},
-			{"Sensitivity", cfg.General.SensitivityMultiplier, true},
-			{"Cursor Zoom Factor", cfg.General.CursorZoomFactor, true},
-			{"Layout", cfg.General.Layout, true},
-			{"Allow Tearing", cfg.General.AllowTearing, true},
-			// Add other general settings...
-		},
+// newOptionsModel lists the schema options of a category and its
+// subcategories. Deprecated options only show up when the config uses them.
+func newOptionsModel(cfg *config.HyprlandConfig, preview *PreviewSession, section, category string) settingsModel {
+	var settings []setting
+	for _, opt := range config.OptionsIn(category) {
+		if opt.DeprecatedIn != "" && !cfg.IsSet(opt.Path) {
+			continue
+		}
+		value, _ := cfg.GetOption(opt.Path)
+		settings = append(settings, setting{opt.Label, opt.Path, value, true})
 	}
-}
 
-func NewDecorationSettingsModel(cfg *config.HyprlandConfig) SettingsModel {
 	return settingsModel{
-		config:  cfg,
-		section: "Decoration",
-		settings: []setting{
-			{"Rounding", cfg.Decoration.Rounding, true},
-			{"Blur Enabled", cfg.Decoration.BlurEnabled, true},
-			{"Blur Size", cfg.Decoration.BlurSize, true},
-			{"Blur Passes", cfg.Decoration.BlurPasses, true},
-			{"Active Opacity", cfg.Decoration.Opacity, true},
-			{"Inactive Opacity", cfg.Decoration.InactiveOpacity, true},
-			{"Drop Shadow", cfg.Decoration.DropShadow, true},
-			{"Shadow Range", cfg.Decoration.ShadowRange, true},
-			{"Shadow Color", cfg.Decoration.ShadowColor, true},
-		},
+		config:   cfg,
+		preview:  preview,
+		section:  section,
+		settings: settings,
 	}
 }
 
-func NewAnimationsSettingsModel(cfg *config.HyprlandConfig) SettingsModel {
-	return settingsModel{
-		config:  cfg,
-		section: "Animations",
-		settings: []setting{
-			{"Enabled", cfg.Animations.Enabled, true},
-			// Add bezier curves as sub-settings
-			{"Add Bezier Curve", "New", true},
-			// Add animations as sub-settings
-			{"Add Animation", "New", true},
-		},
-	}
+func NewGeneralSettingsModel(cfg *config.HyprlandConfig, preview *PreviewSession) SettingsModel {
+	return newOptionsModel(cfg, preview, "General", "general")
 }
 
-func NewInputSettingsModel(cfg *config.HyprlandConfig) SettingsModel {
-	return settingsModel{
-		config:  cfg,
-		section: "Input",
-		settings: []setting{
-			{"Keyboard Model", cfg.Input.KBModel, true},
-			{"Keyboard Layout", cfg.Input.KBLayout, true},
-			{"Keyboard Variant", cfg.Input.KBVariant, true},
-			{"Keyboard Options", cfg.Input.KBOptions, true},
-			{"NumLock by Default", cfg.Input.NumLockByDefault, true},
-			{"Scroll Method", cfg.Input.ScrollMethod, true},
-			{"Scroll Button", cfg.Input.ScrollButton, true},
-			{"Scroll Factor", cfg.Input.ScrollFactor, true},
-			{"Follow Mouse", cfg.Input.FollowMouse, true},
-			{"Mouse Refocus", cfg.Input.MouseRefocus, true},
-		},
-	}
+func NewDecorationSettingsModel(cfg *config.HyprlandConfig, preview *PreviewSession) SettingsModel {
+	return newOptionsModel(cfg, preview, "Decoration", "decoration")
+}
+
+func NewAnimationsSettingsModel(cfg *config.HyprlandConfig, preview *PreviewSession) SettingsModel {
+	m := newOptionsModel(cfg, preview, "Animations", "animations")
+	m.settings = append(m.settings,
+		// Add bezier curves as sub-settings
+		setting{"Add Bezier Curve", "", "New", true},
+		// Add animations as sub-settings
+		setting{"Add Animation", "", "New", true},
+	)
+	return m
 }
 
-func NewWindowRulesSettingsModel(cfg *config.HyprlandConfig) SettingsModel {
+func NewInputSettingsModel(cfg *config.HyprlandConfig, preview *PreviewSession) SettingsModel {
+	return newOptionsModel(cfg, preview, "Input", "input")
+}
+
+func NewWindowRulesSettingsModel(cfg *config.HyprlandConfig, preview *PreviewSession) SettingsModel {
 	// Convert existing rules to settings
 	settings := []setting{
-		{"Add New Rule", "New", true},
+		{"Add New Rule", "", "New", true},
 	}
 
 	for i, rule := range cfg.WindowRules {
 		name := fmt.Sprintf("Rule %d", i+1)
 		value := fmt.Sprintf("%s,%s,%s", rule.Rule, rule.Value, rule.Target)
-		settings = append(settings, setting{name, value, true})
+		settings = append(settings, setting{name, "", value, true})
 	}
 
 	return settingsModel{
 		config:   cfg,
+		preview:  preview,
 		section:  "Window Rules",
 		settings: settings,
+		errors:   ruleMessages(cfg),
 	}
 }
 
-func NewKeybindingsSettingsModel(cfg *config.HyprlandConfig) SettingsModel {
+func NewKeybindingsSettingsModel(cfg *config.HyprlandConfig, preview *PreviewSession) SettingsModel {
 	// Convert existing binds to settings
 	settings := []setting{
-		{"Add New Binding", "New", true},
+		{"Add New Binding", "", "New", true},
 	}
 
 	for i, bind := range cfg.Binds {
@@ -105,14 +82,44 @@ func NewKeybindingsSettingsModel(cfg *config.HyprlandConfig) SettingsModel {
 			name = bind.Description
 		}
 		value := fmt.Sprintf("%s + %s → %s %s", bind.Mods, bind.Key, bind.Dispatcher, bind.Params)
-		settings = append(settings, setting{name, value, true})
+		settings = append(settings, setting{name, "", value, true})
 	}
 
 	return settingsModel{
 		config:   cfg,
+		preview:  preview,
 		section:  "Keybindings",
 		settings: settings,
+		errors:   conflictMessages(cfg),
+	}
+}
+
+// ruleMessages lists window rules that fail validation
+func ruleMessages(cfg *config.HyprlandConfig) []string {
+	var messages []string
+	for i, rule := range cfg.WindowRules {
+		if err := rule.Validate(); err != nil {
+			where := fmt.Sprintf("Rule %d", i+1)
+			if rule.Line > 0 {
+				where = fmt.Sprintf("line %d", rule.Line)
+			}
+			messages = append(messages, where+": "+err.Error())
+		}
+	}
+	return messages
+}
+
+// conflictMessages lists keybinding conflicts for display above the binds
+func conflictMessages(cfg *config.HyprlandConfig) []string {
+	var messages []string
+	for _, c := range config.FindBindConflicts(cfg) {
+		msg := c.Kind.String() + ": " + c.Message
+		if c.Bind.Line > 0 {
+			msg = c.Bind.Source() + ": " + msg
+		}
+		messages = append(messages, msg)
 	}
+	return messages
 }
 
 // Add other section models...
@@ -2,8 +2,8 @@ package ui
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -32,6 +32,10 @@ var (
 	errorListStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#ff6666")).
 			PaddingLeft(4)
+
+	previewStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#e0af68")).
+			PaddingLeft(4)
 )
 
 type settingsModel struct {
@@ -45,10 +49,24 @@ type settingsModel struct {
 	errorMsg  string
 	errors    []string
 	showHelp  bool
+	preview   *PreviewSession
+
+	previewTicks int // keystrokes seen while editing, for the debounce
+}
+
+// previewDelay is how long typing has to pause before the value is pushed
+// to the compositor
+var previewDelay = 150 * time.Millisecond
+
+// previewTickMsg fires previewDelay after a keystroke. Only the tick of the
+// last keystroke pushes.
+type previewTickMsg struct {
+	tick int
 }
 
 type setting struct {
 	name     string
+	key      string // option path for IPC, e.g. "general:gaps_in"
 	value    interface{}
 	editable bool
 }
@@ -59,6 +77,18 @@ func (m settingsModel) Init() tea.Cmd {
 
 func (m settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case previewTickMsg:
+		if !m.editing || msg.tick != m.previewTicks {
+			return m, nil
+		}
+		return m, m.pushPreview()
+	case previewMsg:
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+		} else {
+			m.errorMsg = ""
+		}
+		return m, nil
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "?":
@@ -73,9 +103,9 @@ func (m settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc":
 			if m.editing {
 				m.editing = false
-				return m, nil
+				return m, m.restorePreview()
 			}
-			return m, tea.Quit
+			return m, back
 		case "enter":
 			if m.editing {
 				// Validate and save the edited value
@@ -113,6 +143,9 @@ func (m settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				default:
 					m.editValue += msg.String()
 				}
+				m.previewTicks++
+				tick := m.previewTicks
+				return m, tea.Tick(previewDelay, func(time.Time) tea.Msg { return previewTickMsg{tick} })
 			}
 		}
 	}
@@ -142,10 +175,26 @@ func (m settingsModel) View() string {
 		case "Keybindings":
 			s += helpStyle.Render("Format: MODS + KEY → ACTION PARAMS") + "\n"
 			s += helpStyle.Render("Example: SUPER + Return → exec kitty") + "\n"
+		default:
+			if opt, ok := config.Lookup(m.settings[m.cursor].key); ok {
+				s += helpStyle.Render(opt.Path+" ("+opt.Type.String()+"): "+opt.Description) + "\n"
+				if constraint := opt.Constraint(); constraint != "" {
+					s += helpStyle.Render("Allowed: "+constraint) + "\n"
+				}
+				if opt.Restart {
+					s += helpStyle.Render("Takes effect after restarting Hyprland") + "\n"
+				}
+			}
 		}
 		s += "\n"
 	}
 
+	// Show live preview state
+	if keys := m.preview.Keys(); len(keys) > 0 {
+		s += previewStyle.Render(fmt.Sprintf(
+			"● Previewing %d unsaved change(s) live, reverted on quit", len(keys))) + "\n\n"
+	}
+
 	// Show error message if present
 	if m.errorMsg != "" {
 		s += errorStyle.Render("Error: "+m.errorMsg) + "\n\n"
@@ -163,6 +212,9 @@ func (m settingsModel) View() string {
 		} else {
 			value = valueStyle.Render(value)
 		}
+		if m.preview.Previewing(setting.key) {
+			value += previewStyle.Render("(live)")
+		}
 
 		s += fmt.Sprintf("%s%s: %s\n",
 			cursor,
@@ -174,64 +226,59 @@ func (m settingsModel) View() string {
 	return s
 }
 
+// pushPreview returns the command sending the value being typed to the
+// compositor when it is valid for the option. While it is not, the
+// compositor shows the value held before editing rather than the last
+// valid prefix.
+func (m *settingsModel) pushPreview() tea.Cmd {
+	setting := m.settings[m.cursor]
+	opt, ok := config.Lookup(setting.key)
+	if m.preview == nil || !ok {
+		return nil
+	}
+	value := strings.TrimSpace(m.editValue)
+	if opt.Validate(value) != nil {
+		return m.restorePreview()
+	}
+	return m.preview.Push(setting.key, value, fmt.Sprintf("%v", setting.value))
+}
+
+// restorePreview returns the command putting the compositor back to the
+// value held before editing. A push may still be on its way after a
+// keystroke, so the value is restored then too.
+func (m *settingsModel) restorePreview() tea.Cmd {
+	setting := m.settings[m.cursor]
+	if m.previewTicks == 0 && !m.preview.Previewing(setting.key) {
+		return nil
+	}
+	value := fmt.Sprintf("%v", setting.value)
+	return m.preview.Push(setting.key, value, value)
+}
+
+// validateAndSave checks the edited value against the option schema and
+// stores it in the config
 func (m *settingsModel) validateAndSave() error {
 	setting := m.settings[m.cursor]
 
-	var err error
-	switch setting.value.(type) {
-	case int:
-		err = config.ValidateInt(setting.name, m.editValue, 0, 1000)
-		if err == nil {
-			val, _ := strconv.Atoi(m.editValue)
-			m.updateConfigValue(setting.name, val)
-		}
-	case float64:
-		err = config.ValidateFloat(setting.name, m.editValue, 0, 10)
-		if err == nil {
-			val, _ := strconv.ParseFloat(m.editValue, 64)
-			m.updateConfigValue(setting.name, val)
-		}
-	case bool:
-		err = config.ValidateBool(setting.name, m.editValue)
-		if err == nil {
-			val := strings.ToLower(m.editValue) == "true"
-			m.updateConfigValue(setting.name, val)
-		}
-	case string:
-		// Add specific validation based on the field
-		m.updateConfigValue(setting.name, m.editValue)
+	opt, ok := config.Lookup(setting.key)
+	if !ok {
+		// Entries such as rules and binds are only listed here
+		m.settings[m.cursor].value = m.editValue
+		return nil
 	}
 
+	err := opt.Validate(m.editValue)
+	if err == nil {
+		err = m.config.SetOption(opt.Path, m.editValue)
+	}
 	if err != nil {
 		m.errorMsg = err.Error()
 		return err
 	}
 
+	// Show the value the way it is stored
+	value, _ := m.config.GetOption(opt.Path)
+	m.settings[m.cursor].value = value
 	m.errorMsg = "" // Clear error on success
 	return nil
 }
-
-func (m *settingsModel) updateConfigValue(name string, value interface{}) {
-	// Update the config based on the section and field name
-	switch m.section {
-	case "General":
-		switch name {
-		case "Border Size":
-			m.config.General.BorderSize = value.(int)
-		case "Gaps In":
-			m.config.General.GapIn = value.(int)
-			// Add other cases...
-		}
-	case "Decoration":
-		// Handle decoration settings
-		// Add other sections...
-	}
-
-	// Update the displayed value
-	for i, s := range m.settings {
-		if s.name == name {
-			m.settings[i].value = value
-			break
-		}
-	}
-}
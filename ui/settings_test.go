@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/max-geller/hyprmax/config"
+)
+
+// gapsModel returns a settings page editing general:gaps_in, which holds 5
+func gapsModel(p Previewer) settingsModel {
+	m := newOptionsModel(&config.HyprlandConfig{}, NewPreviewSession(p), "General", "general")
+	for m.settings[m.cursor].key != "general:gaps_in" {
+		m.cursor++
+	}
+	m.editing = true
+	m.editValue = "5"
+	return m
+}
+
+// update runs msg through m and then the commands it returns, the way the
+// program loop would
+func update(m settingsModel, msg tea.Msg) settingsModel {
+	for msg != nil {
+		model, cmd := m.Update(msg)
+		m = model.(settingsModel)
+		if cmd == nil {
+			break
+		}
+		msg = cmd()
+	}
+	return m
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	if key == "backspace" {
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestSettingsPreviewValidates(t *testing.T) {
+	defer func(d time.Duration) { previewDelay = d }(previewDelay)
+	previewDelay = 0
+
+	p := &recordingPreviewer{values: make(map[string]string)}
+	m := gapsModel(p)
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"1", "51"},
+		// "51x" is not a number, the compositor goes back to the saved value
+		{"x", "5"},
+		{"backspace", "51"},
+	}
+	for _, tt := range tests {
+		m = update(m, keyMsg(tt.key))
+		if got := p.values["general:gaps_in"]; got != tt.want {
+			t.Errorf("after %q pushed %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	// Leaving the field restores the saved value
+	m = update(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if got := p.values["general:gaps_in"]; got != "5" || m.preview.Previewing("general:gaps_in") {
+		t.Errorf("after esc pushed %q, want 5", got)
+	}
+}
+
+func TestSettingsPreviewDebounce(t *testing.T) {
+	p := &recordingPreviewer{values: make(map[string]string)}
+	m := gapsModel(p)
+	m.editValue = ""
+
+	// Each keystroke starts a tick; only the one of the last keystroke pushes
+	var ticks []tea.Msg
+	for _, key := range []string{"1", "2"} {
+		model, cmd := m.Update(keyMsg(key))
+		m = model.(settingsModel)
+		if cmd == nil {
+			t.Fatalf("keystroke %q returned no command", key)
+		}
+		ticks = append(ticks, previewTickMsg{m.previewTicks})
+	}
+	if _, cmd := m.Update(ticks[0]); cmd != nil {
+		t.Error("tick of an earlier keystroke pushed")
+	}
+	_, cmd := m.Update(ticks[1])
+	if cmd == nil {
+		t.Fatal("tick of the last keystroke did not push")
+	}
+	if len(p.values) != 0 {
+		t.Error("value pushed before the command ran")
+	}
+	msg := cmd()
+	if got := p.values["general:gaps_in"]; got != "12" {
+		t.Errorf("pushed %q, want 12", got)
+	}
+
+	// A failing push shows up as an error on the page
+	p.fail = true
+	m = update(m, previewTickMsg{m.previewTicks})
+	if m.errorMsg == "" {
+		t.Error("failed push not reported")
+	}
+	m = update(m, msg)
+	if m.errorMsg != "" {
+		t.Errorf("errorMsg = %q after a successful push", m.errorMsg)
+	}
+}